@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/logging"
+)
+
+// withClient はフラグと設定ファイルの値でS3クライアントを作成して fn を実行します。
+// UIを使わないサブコマンドで使います。
+func withClient(fn func(client *aws.S3Client) error) error {
+	clientOptions, err := newClientOptions()
+	if err != nil {
+		return err
+	}
+	logger, err := logging.New(logging.Options{Debug: debugMode, File: logFile})
+	if err != nil {
+		return err
+	}
+	defer logger.Close()
+	clientOptions.Logger = logger.Logger
+
+	client, err := aws.NewS3Client(clientOptions)
+	if err != nil {
+		return err
+	}
+	return fn(client)
+}
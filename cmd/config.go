@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/tsuna-can/s3-cli/internal/config"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/ui"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View, edit or validate the config file",
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the path of the config file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := resolveConfigPath()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), path)
+		return nil
+	},
+}
+
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Print the settings loaded from the config file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := resolveConfigPath()
+		if err != nil {
+			return err
+		}
+		c, err := config.Load(path)
+		if err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(c)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "# %s\n%s", path, out)
+		return nil
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config file for errors",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := resolveConfigPath()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return i18n.Errorf("cmd.config_missing", path)
+		}
+		if err := validateConfigFile(path); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: OK\n", path)
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config file in $EDITOR and validate it afterwards",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := resolveConfigPath()
+		if err != nil {
+			return err
+		}
+
+		// 設定ファイルがなければひな形を作成する
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte(config.Template), 0644); err != nil {
+				return err
+			}
+		}
+
+		editor := ui.EditorProcess(path)
+		editor.Stdin, editor.Stdout, editor.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := editor.Run(); err != nil {
+			return i18n.Errorf("cmd.editor_failed", err)
+		}
+
+		if err := validateConfigFile(path); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: OK\n", path)
+		return nil
+	},
+}
+
+// validateConfigFile は設定ファイルを読み込み、UIで使う値も含めて検証します
+func validateConfigFile(path string) error {
+	c, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	if c.Theme != "" {
+		if err := ui.ValidateTheme(c.Theme); err != nil {
+			return i18n.Errorf("config.file_error", path, fmt.Errorf("theme: %w", err))
+		}
+	}
+	if err := ui.ValidateKeybindings(c.Keybindings); err != nil {
+		return i18n.Errorf("config.file_error", path, err)
+	}
+	return nil
+}
+
+func init() {
+	configCmd.AddCommand(configPathCmd, configViewCmd, configValidateCmd, configEditCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/usage"
+)
+
+// du のフラグ
+var (
+	duFormat string
+	duDepth  int
+)
+
+var duCmd = &cobra.Command{
+	Use:   "du s3://<bucket>[/<prefix>]",
+	Short: "Show the size and object count of a bucket or prefix, by sub-prefix, storage class and age",
+	Long: `Show the size and object count of a bucket or prefix.
+All objects under the prefix are listed with ListObjectsV2 (using --concurrency
+workers, one per sub-prefix) and summed up by sub-prefix, by storage class and by
+age since last modification. Only current object versions are counted.
+
+--format json and --format csv write the whole tree, like the export in the UI's
+usage view (z).`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		switch usage.Format(duFormat) {
+		case "text", usage.FormatJSON, usage.FormatCSV:
+		default:
+			return i18n.Errorf("usage.unknown_format", duFormat)
+		}
+		return prepareClient(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bucket, prefix, err := parseS3URI(args[0])
+		if err != nil {
+			return err
+		}
+		return withClient(func(client *aws.S3Client) error {
+			tree, err := scanUsage(cmd, client, bucket, prefix)
+			if err != nil {
+				return err
+			}
+			if duFormat == "text" {
+				return reportUsage(cmd.OutOrStdout(), tree, duDepth)
+			}
+			return tree.Write(cmd.OutOrStdout(), tree.Root, usage.Format(duFormat))
+		})
+	},
+}
+
+// scanUsage はプレフィックス配下のすべてのオブジェクトを --concurrency 個のワーカーで走査して集計します
+func scanUsage(cmd *cobra.Command, client *aws.S3Client, bucket, prefix string) (*usage.Tree, error) {
+	tree := usage.New(bucket, prefix, time.Now())
+	progress := make(chan aws.SearchProgress, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- client.SearchObjects(cmd.Context(), aws.SearchOptions{Bucket: bucket, Prefix: prefix, Concurrency: concurrency}, progress)
+		close(progress)
+	}()
+	for p := range progress {
+		for _, obj := range p.Matched {
+			tree.Add(obj)
+		}
+	}
+	if err := <-done; err != nil {
+		return nil, err
+	}
+	tree.Complete = true
+	return tree, nil
+}
+
+// reportUsage は集計した場所と depth 階層下までのサブプレフィックス、ストレージクラスと経過時間の内訳を表で書き出します
+func reportUsage(out io.Writer, tree *usage.Tree, depth int) error {
+	n := tree.Root
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, i18n.T("usage.header"))
+	var walk func(c *usage.Node, level int)
+	walk = func(c *usage.Node, level int) {
+		name := "s3://" + tree.Bucket + "/" + c.Prefix
+		if level > 0 {
+			name = strings.Repeat("  ", level) + c.Name
+		}
+		fmt.Fprintf(w, "%d\t%d\t%.1f\t%s\n", c.Total.Size, c.Total.Objects, usage.Percent(c.Total.Size, n.Total.Size), name)
+		if level < depth {
+			for _, child := range c.Sorted() {
+				walk(child, level+1)
+			}
+		}
+	}
+	walk(n, 0)
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, i18n.T("usage.class_header"))
+	for _, name := range n.ClassNames() {
+		s := n.Classes[name]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\n", name, s.Size, s.Objects, usage.Percent(s.Size, n.Total.Size))
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, i18n.T("usage.age_header"))
+	for i, b := range usage.AgeBuckets {
+		s := n.Ages[i]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\n", b.Label, s.Size, s.Objects, usage.Percent(s.Size, n.Total.Size))
+	}
+	return w.Flush()
+}
+
+func init() {
+	duCmd.Flags().StringVar(&duFormat, "format", "text", "Output format: text, json or csv")
+	duCmd.Flags().IntVar(&duDepth, "depth", 1, "Number of sub-prefix levels to show with --format text")
+	rootCmd.AddCommand(duCmd)
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/query"
+)
+
+// mpu abort のフラグ
+var (
+	olderThan string
+	uploadIDs []string
+	dryRun    bool
+)
+
+var mpuCmd = &cobra.Command{
+	Use:   "mpu",
+	Short: "List or abort incomplete multipart uploads",
+	Long: `List or abort incomplete multipart uploads.
+The same uploads can be browsed in the UI with the "uploads" key (U).`,
+}
+
+var mpuLsCmd = &cobra.Command{
+	Use:     "ls <bucket>",
+	Short:   "List incomplete multipart uploads in a bucket, oldest first, with their part count and size",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: prepareClient,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bucket := args[0]
+		return withClient(func(client *aws.S3Client) error {
+			uploads, err := listUploads(cmd, client, bucket)
+			if err != nil {
+				return err
+			}
+			if len(uploads) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), i18n.T("mpu.none"))
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, i18n.T("mpu.header"))
+			for _, u := range uploads {
+				fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n",
+					u.Initiated.Local().Format("2006-01-02 15:04"), u.Parts, u.Size, u.UploadID, u.Key)
+			}
+			return w.Flush()
+		})
+	},
+}
+
+var mpuAbortCmd = &cobra.Command{
+	Use:   "abort <bucket>",
+	Short: "Abort incomplete multipart uploads older than --older-than, or those given with --upload-id",
+	Args:  cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := parseOlderThan(olderThan); err != nil {
+			return err
+		}
+		return prepareClient(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bucket := args[0]
+		age, _ := parseOlderThan(olderThan)
+		return withClient(func(client *aws.S3Client) error {
+			uploads, err := client.ListMultipartUploads(cmd.Context(), bucket)
+			if err != nil {
+				return err
+			}
+			targets := selectUploads(uploads, uploadIDs, time.Now().Add(-age))
+			for _, u := range targets {
+				if !dryRun {
+					if err := client.AbortMultipartUpload(cmd.Context(), bucket, u.Key, u.UploadID); err != nil {
+						return err
+					}
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), i18n.T("mpu.aborted", u.Key, u.UploadID))
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), i18n.T("mpu.aborted_count", len(targets), len(uploads)-len(targets)))
+			if dryRun {
+				fmt.Fprintln(cmd.OutOrStdout(), i18n.T("mpu.dry_run"))
+			}
+			return nil
+		})
+	},
+}
+
+// listUploads は完了していないアップロードを、パート数と合計サイズを付けて返します
+func listUploads(cmd *cobra.Command, client *aws.S3Client, bucket string) ([]aws.MultipartUpload, error) {
+	uploads, err := client.ListMultipartUploads(cmd.Context(), bucket)
+	if err != nil {
+		return nil, err
+	}
+	for i := range uploads {
+		if err := client.CountParts(cmd.Context(), bucket, &uploads[i]); err != nil {
+			return nil, err
+		}
+	}
+	return uploads, nil
+}
+
+// selectUploads は中止するアップロードを返します。
+// アップロードIDが指定された場合はそれだけを、そうでなければ cutoff 以前に開始されたものを返します。
+func selectUploads(uploads []aws.MultipartUpload, ids []string, cutoff time.Time) []aws.MultipartUpload {
+	var targets []aws.MultipartUpload
+	for _, u := range uploads {
+		if len(ids) > 0 {
+			for _, id := range ids {
+				if u.UploadID == id {
+					targets = append(targets, u)
+				}
+			}
+		} else if !u.Initiated.After(cutoff) {
+			targets = append(targets, u)
+		}
+	}
+	return targets
+}
+
+// parseOlderThan は --older-than の値を変換します。"0" はすべてのアップロードを表します
+func parseOlderThan(value string) (time.Duration, error) {
+	if value == "0" {
+		return 0, nil
+	}
+	return query.ParseAge(value)
+}
+
+func init() {
+	mpuAbortCmd.Flags().StringVar(&olderThan, "older-than", "7d", "Only abort uploads started at least this long ago, e.g. 12h, 7d (0: all)")
+	mpuAbortCmd.Flags().StringSliceVar(&uploadIDs, "upload-id", nil, "Abort only these upload IDs (ignores --older-than)")
+	mpuAbortCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the uploads that would be aborted without aborting them")
+	mpuCmd.AddCommand(mpuLsCmd, mpuAbortCmd)
+	rootCmd.AddCommand(mpuCmd)
+}
@@ -1,9 +1,15 @@
 package cmd
 
 import (
-	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/cache"
+	"github.com/tsuna-can/s3-cli/internal/config"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/logging"
+	"github.com/tsuna-can/s3-cli/internal/state"
 	"github.com/tsuna-can/s3-cli/internal/ui"
 )
 
@@ -11,20 +17,88 @@ var outputDir string
 var profile string
 var debugMode bool
 var endpointURL string
+var concurrency int
+var configPath string
+var conflictPolicy string
+var themeName string
+var langName string
+var logFile string
+var maxRetries int
+var retryMode string
+var timeout time.Duration
+var transferTimeout time.Duration
+var operationTimeouts map[string]time.Duration
+var maxRPS float64
+var bandwidthLimit string
+var sseCKeyFile string
+var openURI string
+var cacheTTL time.Duration
+var diskCache bool
+
+// sseCKeyEnv は SSE-C の鍵（base64）を渡す環境変数です
+const sseCKeyEnv = "S3_CLI_SSE_C_KEY"
+
+// cfg は読み込んだ設定ファイルの内容です
+var cfg *config.Config
 
 var rootCmd = &cobra.Command{
-	Use:   "s3-cli",
+	Use:   "s3-cli [s3://bucket/prefix/ | s3://bucket/key]",
 	Short: "Interactive AWS S3 CLI tool",
-	Long:  `An interactive CLI tool for browsing and downloading files from AWS S3 buckets.`,
-	PreRunE: func(cmd *cobra.Command, args []string) error {
-		// --endpoint-urlフラグが指定されているか確認
-		if endpointURL == "" {
-			return fmt.Errorf("--endpoint-url フラグは必須です")
-		}
-		return nil
+	Long: `An interactive CLI tool for browsing and downloading files from AWS S3 buckets.
+
+Give an s3:// URI to start in that bucket or prefix instead of the bucket list.
+If the URI names an object, its details are shown with the cursor on it.`,
+	Args: cobra.MaximumNArgs(1),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// 設定ファイルを読む前に、フラグと環境変数から表示言語を決める
+		return setLanguage(langName, "")
 	},
-	Run: func(cmd *cobra.Command, args []string) {
-		ui.StartUI(outputDir, profile, endpointURL, debugMode) // 引数にendpointURLを追加
+	PreRunE: prepareClient,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policy, err := aws.ParseConflictPolicy(conflictPolicy)
+		if err != nil {
+			return err
+		}
+		clientOptions, err := newClientOptions()
+		if err != nil {
+			return err
+		}
+		start, err := startLocation(args)
+		if err != nil {
+			return err
+		}
+		logger, err := logging.New(logging.Options{Debug: debugMode, File: logFile})
+		if err != nil {
+			return err
+		}
+		defer logger.Close()
+		store, err := openStateStore(profile)
+		if err != nil {
+			// ブックマークが使えないだけなので、起動は続ける
+			logger.Warn("bookmarks and recent locations are disabled", "error", err)
+		}
+		listings, err := newListingCache(profile)
+		if err != nil {
+			// ディスクに保存できないだけなので、メモリのキャッシュで続ける
+			logger.Warn("disk cache for object lists is disabled", "error", err)
+		}
+
+		return ui.StartUI(ui.Options{
+			OutputDir:      outputDir,
+			Profile:        profile,
+			EndpointURL:    endpointURL,
+			Concurrency:    concurrency,
+			ConflictPolicy: policy,
+			Keybindings:    cfg.Keybindings,
+			OpenWith:       cfg.OpenWith,
+			State:          store,
+			Open:           start,
+			Listings:       listings,
+			OpenProfile:    profileOpener(logger),
+			Theme:          ui.ResolveThemeName(themeName, cfg.Theme),
+			Logger:         logger,
+			Client:         clientOptions,
+		})
 	},
 }
 
@@ -34,11 +108,205 @@ func Execute() error {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to the config file (default: $XDG_CONFIG_HOME/s3-cli/config.yaml)")
 	rootCmd.PersistentFlags().StringVar(&outputDir, "output-dir", "", "Directory to save downloaded files (default is current directory)")
 	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "AWS profile to use (default: default)")
-	rootCmd.PersistentFlags().BoolVar(&debugMode, "debug", false, "Enable debug mode")
+	rootCmd.PersistentFlags().BoolVar(&debugMode, "debug", false, "Record debug logs, including a trace of every S3 request (default file: $XDG_CACHE_HOME/s3-cli/debug.log)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append logs to this file (info level unless --debug is also given)")
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", 4, "Number of parallel workers for background operations such as search")
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "max-retries", 2, "Maximum number of retries for each S3 call")
+	rootCmd.PersistentFlags().StringVar(&retryMode, "retry-mode", "standard", "Retry mode: standard, or adaptive to also slow down when throttled")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 30*time.Second, "Time limit for each S3 call except downloads, including retries, unless operation_timeouts sets one (0: no limit)")
+	rootCmd.PersistentFlags().DurationVar(&transferTimeout, "transfer-timeout", 0, "Time limit for each download (0: no limit)")
+	rootCmd.PersistentFlags().Float64Var(&maxRPS, "max-rps", 0, "Maximum number of S3 requests per second, including retries (0: no limit)")
+	rootCmd.PersistentFlags().StringVar(&bandwidthLimit, "bandwidth-limit", "", "Maximum transfer rate shared by all downloads and uploads, e.g. 20MB/s (0: no limit)")
+	rootCmd.PersistentFlags().StringVar(&sseCKeyFile, "sse-c-key-file", "", "File with the 32-byte key (raw or base64) for objects encrypted with SSE-C (default: $"+sseCKeyEnv+")")
+	rootCmd.PersistentFlags().StringVar(&conflictPolicy, "conflict-policy", "error", "What to do when a downloaded file already exists: error, skip, overwrite or rename")
+	rootCmd.PersistentFlags().StringVar(&langName, "lang", "", "Display language: en or ja (default: from LC_ALL, LC_MESSAGES or LANG)")
+	rootCmd.Flags().StringVar(&openURI, "open", "", "Start at this location instead of the bucket list, e.g. s3://bucket/prefix/")
+	rootCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 5*time.Minute, "Show cached object lists younger than this without listing again; older ones are shown while being refreshed")
+	rootCmd.Flags().BoolVar(&diskCache, "disk-cache", false, "Also keep object lists on disk for the next run (in $XDG_CACHE_HOME/s3-cli/listings)")
+	rootCmd.PersistentFlags().StringVar(&themeName, "theme", "", "Color theme: dark, light, high-contrast or none (default: dark, or none when NO_COLOR is set)")
+
+	// エンドポイントURLフラグ（設定ファイルの endpoint でも指定可能）
+	rootCmd.PersistentFlags().StringVar(&endpointURL, "endpoint-url", "", "AWS S3 endpoint URL or an alias from the config file (required unless set in the config file)")
+}
+
+// resolveConfigPath は --config フラグまたは既定の設定ファイルのパスを返します
+func resolveConfigPath() (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+	return config.DefaultPath()
+}
+
+// startLocation は位置引数または --open で指定された、起動時に開く場所を返します。指定がなければ nil です
+func startLocation(args []string) (*state.Location, error) {
+	uri := openURI
+	if len(args) > 0 {
+		if openURI != "" {
+			return nil, i18n.Errorf("cmd.open_twice")
+		}
+		uri = args[0]
+	}
+	if uri == "" {
+		return nil, nil
+	}
+	loc, err := state.ParseLocation(uri)
+	if err != nil {
+		return nil, err
+	}
+	return &loc, nil
+}
+
+// newListingCache はオブジェクト一覧のキャッシュを作成します。
+// --disk-cache の保存先を決められない場合は、エラーとメモリだけのキャッシュを返します。
+func newListingCache(profile string) (*cache.Cache, error) {
+	if !diskCache {
+		return cache.New("", cacheTTL, profile, endpointURL), nil
+	}
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return cache.New("", cacheTTL, profile, endpointURL), err
+	}
+	return cache.New(dir, cacheTTL, profile, endpointURL), nil
+}
+
+// openStateStore はプロファイルとエンドポイントの組のブックマークと最近開いた場所を読み込みます
+func openStateStore(profile string) (*state.Store, error) {
+	path, err := state.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return state.Open(path, profile, endpointURL)
+}
+
+// profileOpener はUIで別のプロファイルのタブを開くときに、そのプロファイルのブックマークと一覧のキャッシュを開く関数を返します
+func profileOpener(logger *logging.Logger) ui.ProfileOpener {
+	return func(profile string) (*state.Store, *cache.Cache) {
+		store, err := openStateStore(profile)
+		if err != nil {
+			logger.Warn("bookmarks and recent locations are disabled", "profile", profile, "error", err)
+		}
+		listings, err := newListingCache(profile)
+		if err != nil {
+			logger.Warn("disk cache for object lists is disabled", "profile", profile, "error", err)
+		}
+		return store, listings
+	}
+}
+
+// loadConfig は設定ファイルを読み込み、フラグで指定されなかった値を設定ファイルの値で補います
+func loadConfig(cmd *cobra.Command) error {
+	path, err := resolveConfigPath()
+	if err != nil {
+		return err
+	}
+	cfg, err = config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("profile") && cfg.Profile != "" {
+		profile = cfg.Profile
+	}
+	if !flags.Changed("endpoint-url") && cfg.Endpoint != "" {
+		endpointURL = cfg.Endpoint
+	}
+	endpointURL = cfg.ResolveEndpoint(endpointURL)
+	if !flags.Changed("output-dir") && cfg.OutputDir != "" {
+		outputDir = config.ExpandHome(cfg.OutputDir)
+	}
+	if !flags.Changed("concurrency") && cfg.Concurrency > 0 {
+		concurrency = cfg.Concurrency
+	}
+	if !flags.Changed("conflict-policy") && cfg.ConflictPolicy != "" {
+		conflictPolicy = cfg.ConflictPolicy
+	}
+	if !flags.Changed("max-retries") && cfg.MaxRetries != nil {
+		maxRetries = *cfg.MaxRetries
+	}
+	if !flags.Changed("retry-mode") && cfg.RetryMode != "" {
+		retryMode = cfg.RetryMode
+	}
+	if !flags.Changed("timeout") && cfg.Timeout > 0 {
+		timeout = cfg.Timeout
+	}
+	operationTimeouts = cfg.OperationTimeouts
+	if !flags.Changed("transfer-timeout") && cfg.TransferTimeout > 0 {
+		transferTimeout = cfg.TransferTimeout
+	}
+	if !flags.Changed("max-rps") && cfg.MaxRPS > 0 {
+		maxRPS = cfg.MaxRPS
+	}
+	if !flags.Changed("bandwidth-limit") && cfg.BandwidthLimit != "" {
+		bandwidthLimit = cfg.BandwidthLimit
+	}
+	if !flags.Changed("cache-ttl") && cfg.CacheTTL > 0 {
+		cacheTTL = cfg.CacheTTL
+	}
+	if !flags.Changed("disk-cache") && cfg.DiskCache {
+		diskCache = true
+	}
+	if !flags.Changed("sse-c-key-file") && cfg.SSECKeyFile != "" {
+		sseCKeyFile = config.ExpandHome(cfg.SSECKeyFile)
+	}
+	if err := setLanguage(langName, cfg.Lang); err != nil {
+		return err
+	}
+	if err := ui.ValidateTheme(ui.ResolveThemeName(themeName, cfg.Theme)); err != nil {
+		return err
+	}
+	return ui.ValidateKeybindings(cfg.Keybindings)
+}
+
+// prepareClient は設定ファイルを読み込み、S3に接続するための値が揃っているか確認します
+func prepareClient(cmd *cobra.Command, args []string) error {
+	if err := loadConfig(cmd); err != nil {
+		return err
+	}
+	// --endpoint-urlフラグか設定ファイルでエンドポイントが指定されているか確認
+	if endpointURL == "" {
+		return i18n.Errorf("cmd.endpoint_required")
+	}
+	return nil
+}
+
+// newClientOptions はフラグと設定ファイルの値からS3クライアントのオプションを作成します
+func newClientOptions() (aws.ClientOptions, error) {
+	mode, err := aws.ParseRetryMode(retryMode)
+	if err != nil {
+		return aws.ClientOptions{}, err
+	}
+	limit, err := aws.ParseBandwidth(bandwidthLimit)
+	if err != nil {
+		return aws.ClientOptions{}, err
+	}
+	customerKey, err := aws.LoadCustomerKey(sseCKeyFile, sseCKeyEnv)
+	if err != nil {
+		return aws.ClientOptions{}, err
+	}
+	return aws.ClientOptions{
+		Profile:           profile,
+		EndpointURL:       endpointURL,
+		MaxRetries:        maxRetries,
+		RetryMode:         mode,
+		Timeout:           timeout,
+		OperationTimeouts: operationTimeouts,
+		TransferTimeout:   transferTimeout,
+		MaxRPS:            maxRPS,
+		BandwidthLimit:    limit,
+		CustomerKey:       customerKey,
+	}, nil
+}
 
-	// エンドポイントURLフラグを追加（必須）
-	rootCmd.PersistentFlags().StringVar(&endpointURL, "endpoint-url", "", "AWS S3 endpoint URL (required)")
-	rootCmd.MarkPersistentFlagRequired("endpoint-url")
+// setLanguage はフラグ・設定ファイル・環境変数から表示言語を決めて設定します
+func setLanguage(flagValue, configValue string) error {
+	lang, err := i18n.Resolve(flagValue, configValue)
+	if err != nil {
+		return err
+	}
+	i18n.Set(lang)
+	return nil
 }
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// upload のフラグ
+var (
+	sseMode     string
+	sseKMSKeyID string
+	bucketKey   bool
+)
+
+var uploadCmd = &cobra.Command{
+	Use:   "upload <file> s3://<bucket>[/<key>]",
+	Short: "Upload a local file, honouring --bandwidth-limit",
+	Long: `Upload a local file to S3.
+When the key is omitted or ends with "/", the file name is appended to it.
+Large files are uploaded in parts; if an upload is interrupted, running the
+same command again resumes it from the last completed part.
+--sse selects server-side encryption (sse-s3, sse-kms or sse-c); without it the
+bucket's default encryption applies. SSE-C uses the key from --sse-c-key-file
+or the S3_CLI_SSE_C_KEY environment variable.`,
+	Args:    cobra.ExactArgs(2),
+	PreRunE: prepareClient,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		bucket, key, err := parseS3URI(args[1])
+		if err != nil {
+			return err
+		}
+		if key == "" || strings.HasSuffix(key, "/") {
+			key += filepath.Base(path)
+		}
+
+		encryption, err := uploadEncryption(cmd)
+		if err != nil {
+			return err
+		}
+
+		err = withClient(func(client *aws.S3Client) error {
+			return client.UploadObject(cmd.Context(), path, bucket, key, aws.UploadOptions{Encryption: encryption})
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), i18n.T("cmd.upload_done", path, bucket, key))
+		return nil
+	},
+}
+
+// uploadEncryption はフラグと設定ファイルの値からアップロードの暗号化の設定を作ります
+func uploadEncryption(cmd *cobra.Command) (aws.Encryption, error) {
+	flags := cmd.Flags()
+	if !flags.Changed("sse") && cfg.SSE != "" {
+		sseMode = cfg.SSE
+	}
+	if !flags.Changed("sse-kms-key-id") && cfg.SSEKMSKeyID != "" {
+		sseKMSKeyID = cfg.SSEKMSKeyID
+	}
+	if !flags.Changed("bucket-key") && cfg.BucketKey {
+		bucketKey = true
+	}
+	mode, err := aws.ParseEncryptionMode(sseMode)
+	if err != nil {
+		return aws.Encryption{}, err
+	}
+	encryption := aws.Encryption{Mode: mode}
+	if mode == aws.EncryptionKMS {
+		encryption.KMSKeyID, encryption.BucketKey = sseKMSKeyID, bucketKey
+	}
+	return encryption, nil
+}
+
+// parseS3URI は "s3://bucket/key" をバケット名とキーに分けます。キーは空の場合があります
+func parseS3URI(uri string) (bucket, key string, err error) {
+	rest, ok := strings.CutPrefix(uri, "s3://")
+	if !ok || rest == "" || strings.HasPrefix(rest, "/") {
+		return "", "", i18n.Errorf("cmd.invalid_s3_uri", uri)
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	return bucket, key, nil
+}
+
+func init() {
+	uploadCmd.Flags().StringVar(&sseMode, "sse", "", "Server-side encryption: sse-s3, sse-kms or sse-c (default: the bucket's setting)")
+	uploadCmd.Flags().StringVar(&sseKMSKeyID, "sse-kms-key-id", "", "KMS key ID, ARN or alias for sse-kms (default: the AWS managed key)")
+	uploadCmd.Flags().BoolVar(&bucketKey, "bucket-key", false, "Use an S3 Bucket Key with sse-kms")
+	rootCmd.AddCommand(uploadCmd)
+}
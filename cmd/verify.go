@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// verify のフラグ
+var verbose bool
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <local-dir> s3://<bucket>[/<prefix>]",
+	Short: "Check that a local directory matches the objects under an S3 prefix",
+	Long: `Check that a local directory matches the objects under an S3 prefix.
+Each object is compared by size and by checksum: an additional checksum
+(SHA256, SHA1, CRC32C, CRC32) when the object has one, otherwise the MD5 in its
+ETag, including multipart ETags. Files missing on either side are reported too.`,
+	Args:    cobra.ExactArgs(2),
+	PreRunE: prepareClient,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := args[0]
+		bucket, prefix, err := parseS3URI(args[1])
+		if err != nil {
+			return err
+		}
+		return withClient(func(client *aws.S3Client) error {
+			var keys []string
+			err := client.WalkObjects(cmd.Context(), bucket, prefix, func(page []aws.ObjectInfo) error {
+				for _, object := range page {
+					if !strings.HasSuffix(object.Key, "/") {
+						keys = append(keys, object.Key)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			results, err := verifyKeys(cmd, client, bucket, prefix, dir, keys)
+			if err != nil {
+				return err
+			}
+			extra, err := extraFiles(dir, prefix, keys)
+			if err != nil {
+				return err
+			}
+			return reportVerify(cmd.OutOrStdout(), results, extra)
+		})
+	},
+}
+
+// verifyEntry は1つのオブジェクトの検証結果です
+type verifyEntry struct {
+	key  string
+	path string
+	aws.VerifyResult
+}
+
+// localPath はキーに対応するローカルのパスを返します。
+// prefix が "/" で終わらない場合（"logs"）は、"logs-old/x" なども一致するため、最後の "/" までを取り除きます。
+func localPath(dir, prefix, key string) string {
+	base := prefix[:strings.LastIndex(prefix, "/")+1]
+	rel := strings.TrimPrefix(key, base)
+	return filepath.Join(dir, filepath.FromSlash(rel))
+}
+
+// verifyKeys はオブジェクトを --concurrency 個のワーカーで並行して検証し、キーの順に返します
+func verifyKeys(cmd *cobra.Command, client *aws.S3Client, bucket, prefix, dir string, keys []string) ([]verifyEntry, error) {
+	results := make([]verifyEntry, len(keys))
+	errs := make([]error, len(keys))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(concurrency, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				key := keys[i]
+				p := localPath(dir, prefix, key)
+				result, err := client.VerifyFile(cmd.Context(), bucket, key, p)
+				results[i], errs[i] = verifyEntry{key: key, path: p, VerifyResult: result}, err
+			}
+		}()
+	}
+	for i := range keys {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", keys[i], err)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].key < results[j].key })
+	return results, nil
+}
+
+// extraFiles はS3にないローカルのファイルを返します。中断したダウンロードが残したファイルは含めません
+func extraFiles(dir, prefix string, keys []string) ([]string, error) {
+	known := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		known[localPath(dir, prefix, key)] = true
+	}
+	var extra []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() && !known[p] && !aws.IsPartialDownload(p) {
+			extra = append(extra, p)
+		}
+		return nil
+	})
+	return extra, err
+}
+
+// reportVerify は問題のあったファイル（-v の場合はすべて）と集計を表示し、不一致があればエラーを返します
+func reportVerify(w io.Writer, results []verifyEntry, extra []string) error {
+	counts := map[aws.VerifyStatus]int{}
+	for _, r := range results {
+		counts[r.Status]++
+		switch r.Status {
+		case aws.VerifyOK:
+			if verbose {
+				fmt.Fprintln(w, i18n.T("verify.ok", r.key, r.Algorithm))
+			}
+		case aws.VerifyMismatch:
+			fmt.Fprintln(w, i18n.T("verify.mismatch", r.Err))
+		case aws.VerifySizeDiffers:
+			fmt.Fprintln(w, i18n.T("verify.size", r.key, r.LocalSize, r.Size))
+		case aws.VerifyMissing:
+			fmt.Fprintln(w, i18n.T("verify.missing", r.key))
+		case aws.VerifyUnverifiable:
+			if verbose {
+				fmt.Fprintln(w, i18n.T("verify.unverifiable", r.key))
+			}
+		}
+	}
+	for _, p := range extra {
+		fmt.Fprintln(w, i18n.T("verify.extra", p))
+	}
+
+	fmt.Fprintln(w, i18n.T("verify.summary", len(results), counts[aws.VerifyOK], counts[aws.VerifyMismatch],
+		counts[aws.VerifySizeDiffers], counts[aws.VerifyMissing], len(extra), counts[aws.VerifyUnverifiable]))
+	if failed := counts[aws.VerifyMismatch] + counts[aws.VerifySizeDiffers] + counts[aws.VerifyMissing] + len(extra); failed > 0 {
+		return i18n.Errorf("verify.failed", failed)
+	}
+	return nil
+}
+
+func init() {
+	verifyCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Also list files that match")
+	rootCmd.AddCommand(verifyCmd)
+}
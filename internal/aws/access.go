@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// AccessDeniedError は権限がなく拒否されたS3の呼び出しのエラーです。
+// どの操作がどのリソースに対して拒否されたかを、IAMのアクション名とARNで示します。
+type AccessDeniedError struct {
+	Action   string // 例: s3:ListBucket
+	Resource string // 例: arn:aws:s3:::bucket
+	Err      error
+}
+
+func (e *AccessDeniedError) Error() string {
+	return i18n.T("aws.access_denied", e.Action, e.Resource)
+}
+
+func (e *AccessDeniedError) Unwrap() error {
+	return e.Err
+}
+
+// IsAccessDenied は err が権限がなく拒否された呼び出しのエラーかを返します
+func IsAccessDenied(err error) bool {
+	var denied *AccessDeniedError
+	return errors.As(err, &denied)
+}
+
+// operationActions はS3の操作名から、その操作に必要なIAMのアクションへの対応です。
+// 載っていない操作は "s3:" + 操作名 とします。
+var operationActions = map[string]string{
+	"ListBuckets":                     "s3:ListAllMyBuckets",
+	"ListObjectsV2":                   "s3:ListBucket",
+	"HeadBucket":                      "s3:ListBucket",
+	"ListObjectVersions":              "s3:ListBucketVersions",
+	"HeadObject":                      "s3:GetObject",
+	"GetObject":                       "s3:GetObject",
+	"CopyObject":                      "s3:PutObject",
+	"CreateMultipartUpload":           "s3:PutObject",
+	"UploadPart":                      "s3:PutObject",
+	"CompleteMultipartUpload":         "s3:PutObject",
+	"DeleteObjects":                   "s3:DeleteObject",
+	"ListMultipartUploads":            "s3:ListBucketMultipartUploads",
+	"ListParts":                       "s3:ListMultipartUploadParts",
+	"GetBucketLifecycleConfiguration": "s3:GetLifecycleConfiguration",
+	"PutBucketLifecycleConfiguration": "s3:PutLifecycleConfiguration",
+	"DeleteBucketLifecycle":           "s3:PutLifecycleConfiguration",
+	"GetBucketCors":                   "s3:GetBucketCORS",
+	"PutBucketCors":                   "s3:PutBucketCORS",
+	"DeleteBucketCors":                "s3:PutBucketCORS",
+	"DeleteBucketTagging":             "s3:PutBucketTagging",
+}
+
+// deniedMessage は拒否の理由に含まれるアクションとリソースを取り出す正規表現です。
+// AWS は "User: arn:... is not authorized to perform: s3:ListBucket on resource: \"arn:aws:s3:::bucket\"" の形式で返します。
+var deniedMessage = regexp.MustCompile(`not authorized to perform: (\S+) on resource: "?(arn:[^"\s]+)`)
+
+// accessDeniedMiddleware は AccessDenied のエラーを、拒否されたアクションとリソースを示す AccessDeniedError に包むミドルウェアです
+func accessDeniedMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("S3CLIAccessDenied",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			// HEAD の応答には本文がないため、エラーコードは Forbidden になる
+			if err == nil || !isAPIError(err, "AccessDenied", "Forbidden") {
+				return out, metadata, err
+			}
+			bucket, key := inputTarget(in.Parameters)
+			return out, metadata, describeAccessDenied(awsmiddleware.GetOperationName(ctx), bucket, key, err)
+		}), middleware.After)
+}
+
+// describeAccessDenied は拒否されたアクションとリソースを求めます。
+// 拒否の理由に書かれていればそれを使い、なければ操作名と呼び出しの引数から推定します。
+func describeAccessDenied(operation, bucket, key string, err error) *AccessDeniedError {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if match := deniedMessage.FindStringSubmatch(apiErr.ErrorMessage()); match != nil {
+			return &AccessDeniedError{Action: match[1], Resource: match[2], Err: err}
+		}
+	}
+
+	action, ok := operationActions[operation]
+	if !ok {
+		action = "s3:" + operation
+	}
+	resource := "arn:aws:s3:::*"
+	switch {
+	case bucket != "" && key != "":
+		resource = "arn:aws:s3:::" + bucket + "/" + key
+	case bucket != "":
+		resource = "arn:aws:s3:::" + bucket
+	}
+	return &AccessDeniedError{Action: action, Resource: resource, Err: err}
+}
+
+// inputTarget は呼び出しの引数からバケット名とキーを取り出します。ない場合は空文字列です
+func inputTarget(params interface{}) (bucket, key string) {
+	v := reflect.Indirect(reflect.ValueOf(params))
+	if v.Kind() != reflect.Struct {
+		return "", ""
+	}
+	return stringField(v, "Bucket"), stringField(v, "Key")
+}
+
+func stringField(v reflect.Value, name string) string {
+	field := v.FieldByName(name)
+	if !field.IsValid() || !field.CanInterface() {
+		return ""
+	}
+	if s, ok := field.Interface().(*string); ok && s != nil {
+		return *s
+	}
+	return ""
+}
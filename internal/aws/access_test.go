@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestAccessDenied は拒否された呼び出しのエラーが、拒否されたアクションとリソースを示すことをテストします
+func TestAccessDenied(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		call     func(c *S3Client) error
+		action   string
+		resource string
+	}{
+		{
+			name: "理由からアクションとリソースを取り出す",
+			body: `<Error><Code>AccessDenied</Code><Message>User: arn:aws:iam::123456789012:user/dev is not authorized to perform: s3:ListBucket on resource: "arn:aws:s3:::logs" because no identity-based policy allows the s3:ListBucket action</Message></Error>`,
+			call: func(c *S3Client) error {
+				_, err := c.ListObjects(context.Background(), "logs", "")
+				return err
+			},
+			action:   "s3:ListBucket",
+			resource: "arn:aws:s3:::logs",
+		},
+		{
+			name: "理由がない場合は操作名から推定する",
+			body: `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`,
+			call: func(c *S3Client) error {
+				_, err := c.ListBuckets(context.Background())
+				return err
+			},
+			action:   "s3:ListAllMyBuckets",
+			resource: "arn:aws:s3:::*",
+		},
+		{
+			name: "本文のないHEADの応答",
+			call: func(c *S3Client) error {
+				_, err := c.DescribeObject(context.Background(), "logs", "2026/app.log")
+				return err
+			},
+			action:   "s3:GetObject",
+			resource: "arn:aws:s3:::logs/2026/app.log",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				if r.Method != http.MethodHead {
+					w.Write([]byte(tc.body))
+				}
+			}
+			client := newTestClient(t, handler, ClientOptions{MaxRetries: 0})
+
+			err := tc.call(client)
+			var denied *AccessDeniedError
+			if !errors.As(err, &denied) {
+				t.Fatalf("期待結果 AccessDeniedError, 実際の結果 %v", err)
+			}
+			if denied.Action != tc.action || denied.Resource != tc.resource {
+				t.Errorf("期待結果 %v on %v, 実際の結果 %v on %v", tc.action, tc.resource, denied.Action, denied.Resource)
+			}
+			if !IsAccessDenied(err) || !strings.Contains(err.Error(), tc.action) {
+				t.Errorf("エラーメッセージに拒否されたアクションがありません: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"golang.org/x/time/rate"
+)
+
+// bandwidthUnits は帯域の単位ごとのバイト数です（1KB = 1024バイト）
+var bandwidthUnits = map[string]float64{
+	"":   1,
+	"b":  1,
+	"kb": 1 << 10,
+	"mb": 1 << 20,
+	"gb": 1 << 30,
+}
+
+// ParseBandwidth は "20MB/s" や "512KB" のような帯域を1秒あたりのバイト数に変換します。
+// 空文字と "0" は無制限（0）を表します。
+func ParseBandwidth(s string) (int64, error) {
+	value := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "/s")
+	if value == "" {
+		return 0, nil
+	}
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if i < 0 {
+		i = len(value)
+	}
+	num, err := strconv.ParseFloat(value[:i], 64)
+	unit, ok := bandwidthUnits[strings.TrimSpace(value[i:])]
+	if err != nil || !ok || num < 0 {
+		return 0, i18n.Errorf("aws.invalid_bandwidth", s)
+	}
+	return int64(math.Round(num * unit)), nil
+}
+
+// BandwidthLimiter はすべての転送で共有するトークンバケット方式の帯域制限です。
+// 転送中でも SetLimit で制限を変更できます。
+type BandwidthLimiter struct {
+	mu      sync.Mutex
+	limit   int64 // 1秒あたりのバイト数（0は無制限）
+	limiter *rate.Limiter
+}
+
+// NewBandwidthLimiter は1秒あたりbytesPerSecondバイトに制限するリミッターを作成します（0は無制限）
+func NewBandwidthLimiter(bytesPerSecond int64) *BandwidthLimiter {
+	b := &BandwidthLimiter{limiter: rate.NewLimiter(rate.Inf, 0)}
+	b.SetLimit(bytesPerSecond)
+	return b
+}
+
+// Limit は現在の制限（1秒あたりのバイト数、0は無制限）を返します
+func (b *BandwidthLimiter) Limit() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.limit
+}
+
+// SetLimit は制限を変更します。実行中の転送にもすぐに反映されます
+func (b *BandwidthLimiter) SetLimit(bytesPerSecond int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if bytesPerSecond < 0 {
+		bytesPerSecond = 0
+	}
+	b.limit = bytesPerSecond
+	if bytesPerSecond == 0 {
+		b.limiter.SetLimit(rate.Inf)
+		return
+	}
+	b.limiter.SetLimit(rate.Limit(bytesPerSecond))
+	b.limiter.SetBurst(burstSize(bytesPerSecond))
+}
+
+// burstSize は1回の読み書きで使うトークン数の上限です。
+// 小さいほど制限に忠実になり、大きいほど待機の回数が減ります。
+func burstSize(bytesPerSecond int64) int {
+	const minBurst, maxBurst = 1 << 10, 256 << 10
+	burst := bytesPerSecond / 20 // 50ms分
+	if burst < minBurst {
+		burst = minBurst
+	}
+	if burst > maxBurst {
+		burst = maxBurst
+	}
+	return int(burst)
+}
+
+// wait は n バイト分のトークンを待ちます。
+// 待っている間に制限が変わっても、その時点のバースト以下に分けて待ちます。
+func (b *BandwidthLimiter) wait(ctx context.Context, n int) error {
+	for n > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		r, chunk := b.reserve(n)
+		if r == nil {
+			return nil
+		}
+		if delay := r.Delay(); delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				r.Cancel()
+				return ctx.Err()
+			}
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// reserve は最大 n バイト分のトークンを予約し、予約した数とともに返します（無制限の場合は nil）。
+// 予約の直前に SetLimit でバーストが小さくなると予約できないため、ロックを取ったままバーストを確かめて予約します。
+func (b *BandwidthLimiter) reserve(n int) (*rate.Reservation, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit == 0 {
+		return nil, 0
+	}
+	chunk := min(n, b.limiter.Burst())
+	return b.limiter.ReserveN(time.Now(), chunk), chunk
+}
+
+// chunk は1回に読み書きしてよいバイト数を返します（無制限の場合は0）
+func (b *BandwidthLimiter) chunk() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit == 0 {
+		return 0
+	}
+	return b.limiter.Burst()
+}
+
+// limitedReader は読み出しを帯域制限に合わせて待たせる io.Reader です
+type limitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *BandwidthLimiter
+}
+
+// Reader は r からの読み出しを帯域制限します。r が io.Seeker の場合は返り値も io.Seeker になります
+func (b *BandwidthLimiter) Reader(ctx context.Context, r io.Reader) io.Reader {
+	lr := &limitedReader{ctx: ctx, r: r, limiter: b}
+	if seeker, ok := r.(io.Seeker); ok {
+		// アップロードの再試行で先頭に戻せるよう、Seekを引き継ぐ
+		return &limitedReadSeeker{limitedReader: lr, seeker: seeker}
+	}
+	return lr
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if chunk := r.limiter.chunk(); chunk > 0 && len(p) > chunk {
+		p = p[:chunk]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.wait(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// limitedReadSeeker は io.Seeker を引き継いだ limitedReader です
+type limitedReadSeeker struct {
+	*limitedReader
+	seeker io.Seeker
+}
+
+func (r *limitedReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return r.seeker.Seek(offset, whence)
+}
@@ -0,0 +1,183 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseBandwidth(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{input: "", expected: 0},
+		{input: "0", expected: 0},
+		{input: "20MB/s", expected: 20 << 20},
+		{input: "512KB", expected: 512 << 10},
+		{input: "1.5mb/s", expected: 3 << 19},
+		{input: "1000", expected: 1000},
+		{input: "fast", wantErr: true},
+		{input: "10TB/s", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := ParseBandwidth(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("エラーの有無が期待と異なります: %v", err)
+			}
+			if actual != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// expectThroughput は転送時間が帯域制限どおりの範囲に収まっているかを確認します。
+// 最初のバースト分は待たずに転送できるため、下限には少し余裕を持たせます。
+// 負荷の高い環境では転送が遅れることがあるため、上限には大きな余裕を持たせます。
+func expectThroughput(t *testing.T, elapsed, expected time.Duration) {
+	t.Helper()
+	low := time.Duration(float64(expected) * 0.75)
+	high := 3*expected + 500*time.Millisecond
+	if elapsed < low || elapsed > high {
+		t.Errorf("転送時間: 期待結果 %v 〜 %v, 実際の結果 %v", low, high, elapsed)
+	}
+}
+
+// TestDownloadBandwidth は複数の同時ダウンロードの合計が帯域制限に収まることをテストします
+func TestDownloadBandwidth(t *testing.T) {
+	const size = 64 << 10
+	const limit = 256 << 10 // 256KB/s
+
+	testCases := []struct {
+		name    string
+		streams int
+	}{
+		{name: "1本", streams: 1},
+		{name: "4本同時", streams: 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := newFakeS3("bucket")
+			for i := 0; i < tc.streams; i++ {
+				fake.put("bucket", fmt.Sprintf("file-%d.bin", i), bytes.Repeat([]byte{byte(i)}, size))
+			}
+			client := newFakeClient(fake)
+			client.Bandwidth().SetLimit(limit)
+			dir := t.TempDir()
+
+			start := time.Now()
+			var wg sync.WaitGroup
+			errs := make(chan error, tc.streams)
+			for i := 0; i < tc.streams; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, err := client.DownloadObject(context.Background(), "bucket", fmt.Sprintf("file-%d.bin", i), DownloadOptions{OutputDir: dir})
+					errs <- err
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Fatalf("予期しないエラー: %v", err)
+				}
+			}
+
+			expected := time.Duration(float64(tc.streams*size) / limit * float64(time.Second))
+			expectThroughput(t, time.Since(start), expected)
+
+			data, err := os.ReadFile(filepath.Join(dir, "file-0.bin"))
+			if err != nil || len(data) != size {
+				t.Errorf("ダウンロードした内容が期待と異なります: %d バイト, %v", len(data), err)
+			}
+		})
+	}
+}
+
+// TestUploadBandwidth はアップロードにも帯域制限がかかることをテストします
+func TestUploadBandwidth(t *testing.T) {
+	const size = 64 << 10
+	const limit = 256 << 10
+
+	path := filepath.Join(t.TempDir(), "upload.bin")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("x"), size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeS3("bucket")
+	client := newFakeClient(fake)
+	client.Bandwidth().SetLimit(limit)
+
+	var done int64
+	start := time.Now()
+	err := client.UploadObject(context.Background(), path, "bucket", "upload.bin", UploadOptions{
+		Progress: func(n, total int64) { done = n },
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expectThroughput(t, time.Since(start), time.Duration(float64(size)/limit*float64(time.Second)))
+
+	if data, ok := fake.get("bucket", "upload.bin"); !ok || len(data) != size {
+		t.Errorf("アップロードした内容が期待と異なります: %d バイト", len(data))
+	}
+	if done != size {
+		t.Errorf("進捗: 期待結果 %v, 実際の結果 %v", size, done)
+	}
+}
+
+// TestSetLimitWhileTransferring は転送中の制限の変更がすぐに反映されることをテストします
+func TestSetLimitWhileTransferring(t *testing.T) {
+	const size = 128 << 10
+
+	fake := newFakeS3("bucket")
+	fake.put("bucket", "file.bin", bytes.Repeat([]byte("x"), size))
+	client := newFakeClient(fake)
+	// この制限のままだと2分以上かかる
+	client.Bandwidth().SetLimit(1 << 10)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		client.Bandwidth().SetLimit(0)
+	}()
+
+	start := time.Now()
+	if _, err := client.DownloadObject(context.Background(), "bucket", "file.bin", DownloadOptions{OutputDir: t.TempDir()}); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	// 制限のままの場合との差が大きいため、負荷の高い環境でも十分な余裕がある
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("制限を解除した後も転送が遅いままです: %v", elapsed)
+	}
+}
+
+// TestWaitCancel は制限で待っている間にキャンセルされると、すぐに戻ることをテストします
+func TestWaitCancel(t *testing.T) {
+	b := NewBandwidthLimiter(1 << 10)
+	// 最初のバースト分を使い切る
+	if err := b.wait(context.Background(), 1<<10); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := b.wait(ctx, 1<<10)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期待結果 %v, 実際の結果 %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("キャンセルの後も待ち続けました: %v", elapsed)
+	}
+}
@@ -0,0 +1,768 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// BucketSetting はバケットの設定の種類です
+type BucketSetting string
+
+const (
+	SettingVersioning BucketSetting = "versioning"
+	SettingLifecycle  BucketSetting = "lifecycle"
+	SettingCORS       BucketSetting = "cors"
+	SettingPolicy     BucketSetting = "policy"
+	SettingTags       BucketSetting = "tags"
+)
+
+// DocumentSettings は JSON で編集する設定の一覧です（表示順）。バージョニングは有効・停止の切り替えだけです
+var DocumentSettings = []BucketSetting{SettingLifecycle, SettingCORS, SettingPolicy, SettingTags}
+
+// 設定の件数の上限です
+const (
+	maxLifecycleRules = 1000
+	maxCORSRules      = 100
+	maxBucketTags     = 50
+)
+
+// BucketConfig はバケットの設定です
+type BucketConfig struct {
+	// Versioning はバージョニングの状態です（"Enabled"、"Suspended"、一度も有効にしていなければ空）
+	Versioning string
+	// Documents は設定ごとの JSON です。未設定の場合は空の規則の一覧（ポリシーは空文字列）です
+	Documents map[BucketSetting]string
+	// Errors は取得できなかった設定のエラーです。権限がない設定があっても残りは表示できます
+	Errors map[BucketSetting]error
+}
+
+// lifecycleDocument などは aws s3api の get-bucket-* と同じ形の JSON です。
+// SDK の型は Filter がインターフェースなどで JSON との相互変換ができないため、変換用に定義しています。
+type lifecycleDocument struct {
+	Rules []lifecycleRule `json:"Rules"`
+}
+
+type lifecycleRule struct {
+	ID                             string                 `json:"ID,omitempty"`
+	Status                         string                 `json:"Status"`
+	Filter                         *lifecycleFilter       `json:"Filter,omitempty"`
+	Prefix                         *string                `json:"Prefix,omitempty"` // 古い形式の対象の指定
+	Expiration                     *lifecycleExpiration   `json:"Expiration,omitempty"`
+	Transitions                    []lifecycleTransition  `json:"Transitions,omitempty"`
+	NoncurrentVersionExpiration    *noncurrentExpiration  `json:"NoncurrentVersionExpiration,omitempty"`
+	NoncurrentVersionTransitions   []noncurrentTransition `json:"NoncurrentVersionTransitions,omitempty"`
+	AbortIncompleteMultipartUpload *abortIncompleteUpload `json:"AbortIncompleteMultipartUpload,omitempty"`
+}
+
+type lifecycleFilter struct {
+	Prefix                *string       `json:"Prefix,omitempty"`
+	Tag                   *bucketTag    `json:"Tag,omitempty"`
+	ObjectSizeGreaterThan int64         `json:"ObjectSizeGreaterThan,omitempty"`
+	ObjectSizeLessThan    int64         `json:"ObjectSizeLessThan,omitempty"`
+	And                   *lifecycleAnd `json:"And,omitempty"`
+}
+
+type lifecycleAnd struct {
+	Prefix                *string     `json:"Prefix,omitempty"`
+	Tags                  []bucketTag `json:"Tags,omitempty"`
+	ObjectSizeGreaterThan int64       `json:"ObjectSizeGreaterThan,omitempty"`
+	ObjectSizeLessThan    int64       `json:"ObjectSizeLessThan,omitempty"`
+}
+
+type lifecycleExpiration struct {
+	Date                      *time.Time `json:"Date,omitempty"`
+	Days                      int32      `json:"Days,omitempty"`
+	ExpiredObjectDeleteMarker bool       `json:"ExpiredObjectDeleteMarker,omitempty"`
+}
+
+type lifecycleTransition struct {
+	Date         *time.Time `json:"Date,omitempty"`
+	Days         int32      `json:"Days,omitempty"`
+	StorageClass string     `json:"StorageClass"`
+}
+
+type noncurrentExpiration struct {
+	NoncurrentDays          int32 `json:"NoncurrentDays"`
+	NewerNoncurrentVersions int32 `json:"NewerNoncurrentVersions,omitempty"`
+}
+
+type noncurrentTransition struct {
+	NoncurrentDays          int32  `json:"NoncurrentDays"`
+	NewerNoncurrentVersions int32  `json:"NewerNoncurrentVersions,omitempty"`
+	StorageClass            string `json:"StorageClass"`
+}
+
+type abortIncompleteUpload struct {
+	DaysAfterInitiation int32 `json:"DaysAfterInitiation"`
+}
+
+type bucketTag struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+type corsDocument struct {
+	CORSRules []corsRule `json:"CORSRules"`
+}
+
+type corsRule struct {
+	ID             string   `json:"ID,omitempty"`
+	AllowedHeaders []string `json:"AllowedHeaders,omitempty"`
+	AllowedMethods []string `json:"AllowedMethods"`
+	AllowedOrigins []string `json:"AllowedOrigins"`
+	ExposeHeaders  []string `json:"ExposeHeaders,omitempty"`
+	MaxAgeSeconds  int32    `json:"MaxAgeSeconds,omitempty"`
+}
+
+type taggingDocument struct {
+	TagSet []bucketTag `json:"TagSet"`
+}
+
+// corsMethods は CORS の規則で許可できるメソッドです
+var corsMethods = []string{"GET", "PUT", "POST", "DELETE", "HEAD"}
+
+// transitionStorageClasses はライフサイクルの移行先に指定できるストレージクラスです
+func transitionStorageClasses() []string {
+	classes := types.TransitionStorageClass("").Values()
+	names := make([]string, len(classes))
+	for i, c := range classes {
+		names[i] = string(c)
+	}
+	return names
+}
+
+// GetBucketConfig はバケットのバージョニングと、JSON で編集する設定を取得します
+func (c *S3Client) GetBucketConfig(ctx context.Context, bucketName string) BucketConfig {
+	config := BucketConfig{
+		Documents: map[BucketSetting]string{},
+		Errors:    map[BucketSetting]error{},
+	}
+	out, err := c.client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{Bucket: &bucketName})
+	if err != nil {
+		config.Errors[SettingVersioning] = err
+	} else {
+		config.Versioning = string(out.Status)
+	}
+	for _, setting := range DocumentSettings {
+		doc, err := c.GetBucketSetting(ctx, bucketName, setting)
+		if err != nil {
+			config.Errors[setting] = err
+			continue
+		}
+		config.Documents[setting] = doc
+	}
+	return config
+}
+
+// GetBucketSetting は設定を編集用に整形した JSON で返します。未設定の場合は空の規則の一覧（ポリシーは空文字列）です
+func (c *S3Client) GetBucketSetting(ctx context.Context, bucketName string, setting BucketSetting) (string, error) {
+	switch setting {
+	case SettingLifecycle:
+		out, err := c.client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: &bucketName})
+		if isAPIError(err, "NoSuchLifecycleConfiguration") {
+			return formatDocument(lifecycleDocument{Rules: []lifecycleRule{}})
+		}
+		if err != nil {
+			return "", err
+		}
+		return formatDocument(lifecycleFromSDK(out.Rules))
+
+	case SettingCORS:
+		out, err := c.client.GetBucketCors(ctx, &s3.GetBucketCorsInput{Bucket: &bucketName})
+		if isAPIError(err, "NoSuchCORSConfiguration") {
+			return formatDocument(corsDocument{CORSRules: []corsRule{}})
+		}
+		if err != nil {
+			return "", err
+		}
+		return formatDocument(corsFromSDK(out.CORSRules))
+
+	case SettingPolicy:
+		out, err := c.client.GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{Bucket: &bucketName})
+		if isAPIError(err, "NoSuchBucketPolicy") {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		// S3 は空白を除いた形で返すため、編集しやすいように字下げする
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, []byte(aws.ToString(out.Policy)), "", "  "); err != nil {
+			return aws.ToString(out.Policy), nil
+		}
+		return indented.String() + "\n", nil
+
+	case SettingTags:
+		out, err := c.client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{Bucket: &bucketName})
+		if isAPIError(err, "NoSuchTagSet") {
+			return formatDocument(taggingDocument{TagSet: []bucketTag{}})
+		}
+		if err != nil {
+			return "", err
+		}
+		return formatDocument(taggingDocument{TagSet: tagsFromSDK(out.TagSet)})
+	}
+	return "", i18n.Errorf("aws.unknown_setting", setting)
+}
+
+// PutBucketSetting は JSON を検証してから設定に反映します。
+// 内容が空か規則の一覧が空の場合は、S3 が空の設定を受け付けないため設定を削除します。
+func (c *S3Client) PutBucketSetting(ctx context.Context, bucketName string, setting BucketSetting, doc string) error {
+	if err := ValidateBucketSetting(setting, doc); err != nil {
+		return err
+	}
+	empty := SettingEntries(setting, doc) == 0
+
+	var err error
+	switch setting {
+	case SettingLifecycle:
+		if empty {
+			_, err = c.client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{Bucket: &bucketName})
+			break
+		}
+		rules, _ := parseLifecycle(doc)
+		_, err = c.client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+			Bucket:                 &bucketName,
+			LifecycleConfiguration: &types.BucketLifecycleConfiguration{Rules: rules},
+		})
+
+	case SettingCORS:
+		if empty {
+			_, err = c.client.DeleteBucketCors(ctx, &s3.DeleteBucketCorsInput{Bucket: &bucketName})
+			break
+		}
+		rules, _ := parseCORS(doc)
+		_, err = c.client.PutBucketCors(ctx, &s3.PutBucketCorsInput{
+			Bucket:            &bucketName,
+			CORSConfiguration: &types.CORSConfiguration{CORSRules: rules},
+		})
+
+	case SettingPolicy:
+		if empty {
+			_, err = c.client.DeleteBucketPolicy(ctx, &s3.DeleteBucketPolicyInput{Bucket: &bucketName})
+			break
+		}
+		_, err = c.client.PutBucketPolicy(ctx, &s3.PutBucketPolicyInput{Bucket: &bucketName, Policy: aws.String(doc)})
+
+	case SettingTags:
+		if empty {
+			_, err = c.client.DeleteBucketTagging(ctx, &s3.DeleteBucketTaggingInput{Bucket: &bucketName})
+			break
+		}
+		tags, _ := parseTags(doc)
+		_, err = c.client.PutBucketTagging(ctx, &s3.PutBucketTaggingInput{
+			Bucket:  &bucketName,
+			Tagging: &types.Tagging{TagSet: tags},
+		})
+	}
+	if err != nil {
+		return err
+	}
+	c.logger.Info("bucket setting updated", "bucket", bucketName, "setting", setting, "deleted", empty)
+	return nil
+}
+
+// SetBucketVersioning はバージョニングを有効にするか、停止します
+func (c *S3Client) SetBucketVersioning(ctx context.Context, bucketName string, enabled bool) error {
+	status := types.BucketVersioningStatusSuspended
+	if enabled {
+		status = types.BucketVersioningStatusEnabled
+	}
+	if _, err := c.client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+		Bucket:                  &bucketName,
+		VersioningConfiguration: &types.VersioningConfiguration{Status: status},
+	}); err != nil {
+		return err
+	}
+	c.logger.Info("bucket versioning updated", "bucket", bucketName, "status", status)
+	return nil
+}
+
+// ValidateBucketSetting は設定の JSON の構文と、必須の項目・値の範囲を確認します
+func ValidateBucketSetting(setting BucketSetting, doc string) error {
+	var err error
+	switch setting {
+	case SettingLifecycle:
+		_, err = parseLifecycle(doc)
+	case SettingCORS:
+		_, err = parseCORS(doc)
+	case SettingPolicy:
+		err = validatePolicy(doc)
+	case SettingTags:
+		_, err = parseTags(doc)
+	default:
+		err = i18n.Errorf("aws.unknown_setting", setting)
+	}
+	return err
+}
+
+// SettingEntries は設定の規則・タグ・ステートメントの数を返します。JSON が不正な場合は 0 です
+func SettingEntries(setting BucketSetting, doc string) int {
+	switch setting {
+	case SettingLifecycle:
+		rules, _ := parseLifecycle(doc)
+		return len(rules)
+	case SettingCORS:
+		rules, _ := parseCORS(doc)
+		return len(rules)
+	case SettingPolicy:
+		var policy struct {
+			Statement json.RawMessage
+		}
+		if strings.TrimSpace(doc) == "" || json.Unmarshal([]byte(doc), &policy) != nil {
+			return 0
+		}
+		var statements []json.RawMessage
+		if json.Unmarshal(policy.Statement, &statements) == nil {
+			return len(statements)
+		}
+		return 1
+	case SettingTags:
+		tags, _ := parseTags(doc)
+		return len(tags)
+	}
+	return 0
+}
+
+// formatDocument は設定を編集用に字下げした JSON にします
+func formatDocument(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// decodeDocument は JSON を v に読み込みます。綴りの誤りに気付けるよう、知らない項目はエラーにします
+func decodeDocument(doc string, v any) error {
+	if strings.TrimSpace(doc) == "" {
+		return nil
+	}
+	dec := json.NewDecoder(strings.NewReader(doc))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := position(doc, syntaxErr.Offset)
+			return i18n.Errorf("aws.setting_syntax", line, column, err)
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			line, column := position(doc, typeErr.Offset)
+			return i18n.Errorf("aws.setting_syntax", line, column, err)
+		}
+		return i18n.Errorf("aws.setting_invalid", err)
+	}
+	if dec.More() {
+		return i18n.Errorf("aws.setting_trailing")
+	}
+	return nil
+}
+
+// position は JSON のエラーのオフセットを行と列（どちらも1から）に変換します。
+// encoding/json のオフセットは問題の文字を読んだ後を指すため、1つ戻します。
+func position(doc string, offset int64) (line, column int) {
+	offset = min(max(offset-1, 0), int64(len(doc)))
+	before := doc[:offset]
+	line = strings.Count(before, "\n") + 1
+	column = int(offset) - strings.LastIndex(before, "\n")
+	return line, column
+}
+
+// parseLifecycle はライフサイクルの JSON を検証して SDK の規則に変換します
+func parseLifecycle(doc string) ([]types.LifecycleRule, error) {
+	var d lifecycleDocument
+	if err := decodeDocument(doc, &d); err != nil {
+		return nil, err
+	}
+	if len(d.Rules) > maxLifecycleRules {
+		return nil, i18n.Errorf("aws.setting_too_many", "Rules", maxLifecycleRules)
+	}
+	ids := map[string]bool{}
+	rules := make([]types.LifecycleRule, len(d.Rules))
+	for i, r := range d.Rules {
+		path := fmt.Sprintf("Rules[%d]", i)
+		if r.ID != "" {
+			if ids[r.ID] {
+				return nil, i18n.Errorf("aws.setting_duplicate", path, "ID", r.ID)
+			}
+			ids[r.ID] = true
+		}
+		if r.Status != string(types.ExpirationStatusEnabled) && r.Status != string(types.ExpirationStatusDisabled) {
+			return nil, i18n.Errorf("aws.setting_one_of", path, "Status", "Enabled, Disabled")
+		}
+		if r.Filter != nil && r.Prefix != nil {
+			return nil, i18n.Errorf("aws.setting_exclusive", path, "Filter", "Prefix")
+		}
+		if r.Expiration == nil && len(r.Transitions) == 0 && r.NoncurrentVersionExpiration == nil &&
+			len(r.NoncurrentVersionTransitions) == 0 && r.AbortIncompleteMultipartUpload == nil {
+			return nil, i18n.Errorf("aws.setting_no_action", path)
+		}
+
+		rule := types.LifecycleRule{
+			Status: types.ExpirationStatus(r.Status),
+			Prefix: r.Prefix,
+		}
+		if r.ID != "" {
+			rule.ID = aws.String(r.ID)
+		}
+		filter, err := lifecycleFilterToSDK(path+".Filter", r.Filter)
+		if err != nil {
+			return nil, err
+		}
+		rule.Filter = filter
+		if r.Filter == nil && r.Prefix == nil {
+			// 対象を指定しない場合はバケット全体が対象
+			rule.Filter = &types.LifecycleRuleFilterMemberPrefix{Value: ""}
+		}
+
+		if e := r.Expiration; e != nil {
+			set := 0
+			for _, ok := range []bool{e.Date != nil, e.Days != 0, e.ExpiredObjectDeleteMarker} {
+				if ok {
+					set++
+				}
+			}
+			if set != 1 {
+				return nil, i18n.Errorf("aws.setting_one_field", path+".Expiration", "Date, Days, ExpiredObjectDeleteMarker")
+			}
+			if e.Days < 0 {
+				return nil, i18n.Errorf("aws.setting_positive", path+".Expiration", "Days")
+			}
+			rule.Expiration = &types.LifecycleExpiration{Date: e.Date, Days: e.Days, ExpiredObjectDeleteMarker: e.ExpiredObjectDeleteMarker}
+		}
+		for j, t := range r.Transitions {
+			tpath := fmt.Sprintf("%s.Transitions[%d]", path, j)
+			if (t.Date != nil) == (t.Days != 0) {
+				return nil, i18n.Errorf("aws.setting_one_field", tpath, "Date, Days")
+			}
+			if t.Days < 0 {
+				return nil, i18n.Errorf("aws.setting_positive", tpath, "Days")
+			}
+			if err := checkOneOf(tpath, "StorageClass", t.StorageClass, transitionStorageClasses()); err != nil {
+				return nil, err
+			}
+			rule.Transitions = append(rule.Transitions, types.Transition{
+				Date: t.Date, Days: t.Days, StorageClass: types.TransitionStorageClass(t.StorageClass),
+			})
+		}
+		if e := r.NoncurrentVersionExpiration; e != nil {
+			if e.NoncurrentDays <= 0 {
+				return nil, i18n.Errorf("aws.setting_positive", path+".NoncurrentVersionExpiration", "NoncurrentDays")
+			}
+			rule.NoncurrentVersionExpiration = &types.NoncurrentVersionExpiration{
+				NoncurrentDays: e.NoncurrentDays, NewerNoncurrentVersions: e.NewerNoncurrentVersions,
+			}
+		}
+		for j, t := range r.NoncurrentVersionTransitions {
+			tpath := fmt.Sprintf("%s.NoncurrentVersionTransitions[%d]", path, j)
+			if t.NoncurrentDays <= 0 {
+				return nil, i18n.Errorf("aws.setting_positive", tpath, "NoncurrentDays")
+			}
+			if err := checkOneOf(tpath, "StorageClass", t.StorageClass, transitionStorageClasses()); err != nil {
+				return nil, err
+			}
+			rule.NoncurrentVersionTransitions = append(rule.NoncurrentVersionTransitions, types.NoncurrentVersionTransition{
+				NoncurrentDays:          t.NoncurrentDays,
+				NewerNoncurrentVersions: t.NewerNoncurrentVersions,
+				StorageClass:            types.TransitionStorageClass(t.StorageClass),
+			})
+		}
+		if a := r.AbortIncompleteMultipartUpload; a != nil {
+			if a.DaysAfterInitiation <= 0 {
+				return nil, i18n.Errorf("aws.setting_positive", path+".AbortIncompleteMultipartUpload", "DaysAfterInitiation")
+			}
+			rule.AbortIncompleteMultipartUpload = &types.AbortIncompleteMultipartUpload{DaysAfterInitiation: a.DaysAfterInitiation}
+		}
+		rules[i] = rule
+	}
+	return rules, nil
+}
+
+// lifecycleFilterToSDK はライフサイクルの対象の指定を SDK の型に変換します。条件は1つだけ指定でき、複数の場合は And を使います
+func lifecycleFilterToSDK(path string, f *lifecycleFilter) (types.LifecycleRuleFilter, error) {
+	if f == nil {
+		return nil, nil
+	}
+	var filters []types.LifecycleRuleFilter
+	if f.Prefix != nil {
+		filters = append(filters, &types.LifecycleRuleFilterMemberPrefix{Value: *f.Prefix})
+	}
+	if f.Tag != nil {
+		if err := checkTag(path+".Tag", *f.Tag); err != nil {
+			return nil, err
+		}
+		filters = append(filters, &types.LifecycleRuleFilterMemberTag{Value: f.Tag.toSDK()})
+	}
+	if f.ObjectSizeGreaterThan != 0 {
+		filters = append(filters, &types.LifecycleRuleFilterMemberObjectSizeGreaterThan{Value: f.ObjectSizeGreaterThan})
+	}
+	if f.ObjectSizeLessThan != 0 {
+		filters = append(filters, &types.LifecycleRuleFilterMemberObjectSizeLessThan{Value: f.ObjectSizeLessThan})
+	}
+	if a := f.And; a != nil {
+		and := types.LifecycleRuleAndOperator{
+			Prefix:                a.Prefix,
+			ObjectSizeGreaterThan: a.ObjectSizeGreaterThan,
+			ObjectSizeLessThan:    a.ObjectSizeLessThan,
+		}
+		for j, tag := range a.Tags {
+			if err := checkTag(fmt.Sprintf("%s.And.Tags[%d]", path, j), tag); err != nil {
+				return nil, err
+			}
+			and.Tags = append(and.Tags, tag.toSDK())
+		}
+		filters = append(filters, &types.LifecycleRuleFilterMemberAnd{Value: and})
+	}
+	switch len(filters) {
+	case 0:
+		// "Filter": {} はバケット全体が対象
+		return &types.LifecycleRuleFilterMemberPrefix{Value: ""}, nil
+	case 1:
+		return filters[0], nil
+	}
+	return nil, i18n.Errorf("aws.setting_one_field", path, "Prefix, Tag, ObjectSizeGreaterThan, ObjectSizeLessThan, And")
+}
+
+// lifecycleFromSDK は SDK のライフサイクルの規則を JSON の形に変換します
+func lifecycleFromSDK(rules []types.LifecycleRule) lifecycleDocument {
+	d := lifecycleDocument{Rules: make([]lifecycleRule, len(rules))}
+	for i, r := range rules {
+		rule := lifecycleRule{ID: aws.ToString(r.ID), Status: string(r.Status), Prefix: r.Prefix}
+		switch f := r.Filter.(type) {
+		case *types.LifecycleRuleFilterMemberPrefix:
+			rule.Filter = &lifecycleFilter{Prefix: aws.String(f.Value)}
+		case *types.LifecycleRuleFilterMemberTag:
+			rule.Filter = &lifecycleFilter{Tag: &bucketTag{Key: aws.ToString(f.Value.Key), Value: aws.ToString(f.Value.Value)}}
+		case *types.LifecycleRuleFilterMemberObjectSizeGreaterThan:
+			rule.Filter = &lifecycleFilter{ObjectSizeGreaterThan: f.Value}
+		case *types.LifecycleRuleFilterMemberObjectSizeLessThan:
+			rule.Filter = &lifecycleFilter{ObjectSizeLessThan: f.Value}
+		case *types.LifecycleRuleFilterMemberAnd:
+			rule.Filter = &lifecycleFilter{And: &lifecycleAnd{
+				Prefix:                f.Value.Prefix,
+				Tags:                  tagsFromSDK(f.Value.Tags),
+				ObjectSizeGreaterThan: f.Value.ObjectSizeGreaterThan,
+				ObjectSizeLessThan:    f.Value.ObjectSizeLessThan,
+			}}
+		}
+		if e := r.Expiration; e != nil {
+			rule.Expiration = &lifecycleExpiration{Date: e.Date, Days: e.Days, ExpiredObjectDeleteMarker: e.ExpiredObjectDeleteMarker}
+		}
+		for _, t := range r.Transitions {
+			rule.Transitions = append(rule.Transitions, lifecycleTransition{Date: t.Date, Days: t.Days, StorageClass: string(t.StorageClass)})
+		}
+		if e := r.NoncurrentVersionExpiration; e != nil {
+			rule.NoncurrentVersionExpiration = &noncurrentExpiration{NoncurrentDays: e.NoncurrentDays, NewerNoncurrentVersions: e.NewerNoncurrentVersions}
+		}
+		for _, t := range r.NoncurrentVersionTransitions {
+			rule.NoncurrentVersionTransitions = append(rule.NoncurrentVersionTransitions, noncurrentTransition{
+				NoncurrentDays: t.NoncurrentDays, NewerNoncurrentVersions: t.NewerNoncurrentVersions, StorageClass: string(t.StorageClass),
+			})
+		}
+		if a := r.AbortIncompleteMultipartUpload; a != nil {
+			rule.AbortIncompleteMultipartUpload = &abortIncompleteUpload{DaysAfterInitiation: a.DaysAfterInitiation}
+		}
+		d.Rules[i] = rule
+	}
+	return d
+}
+
+// parseCORS は CORS の JSON を検証して SDK の規則に変換します
+func parseCORS(doc string) ([]types.CORSRule, error) {
+	var d corsDocument
+	if err := decodeDocument(doc, &d); err != nil {
+		return nil, err
+	}
+	if len(d.CORSRules) > maxCORSRules {
+		return nil, i18n.Errorf("aws.setting_too_many", "CORSRules", maxCORSRules)
+	}
+	rules := make([]types.CORSRule, len(d.CORSRules))
+	for i, r := range d.CORSRules {
+		path := fmt.Sprintf("CORSRules[%d]", i)
+		if len(r.AllowedMethods) == 0 {
+			return nil, i18n.Errorf("aws.setting_required", path, "AllowedMethods")
+		}
+		if len(r.AllowedOrigins) == 0 {
+			return nil, i18n.Errorf("aws.setting_required", path, "AllowedOrigins")
+		}
+		for _, method := range r.AllowedMethods {
+			if err := checkOneOf(path, "AllowedMethods", method, corsMethods); err != nil {
+				return nil, err
+			}
+		}
+		if r.MaxAgeSeconds < 0 {
+			return nil, i18n.Errorf("aws.setting_positive", path, "MaxAgeSeconds")
+		}
+		rule := types.CORSRule{
+			AllowedHeaders: r.AllowedHeaders,
+			AllowedMethods: r.AllowedMethods,
+			AllowedOrigins: r.AllowedOrigins,
+			ExposeHeaders:  r.ExposeHeaders,
+			MaxAgeSeconds:  r.MaxAgeSeconds,
+		}
+		if r.ID != "" {
+			rule.ID = aws.String(r.ID)
+		}
+		rules[i] = rule
+	}
+	return rules, nil
+}
+
+// corsFromSDK は SDK の CORS の規則を JSON の形に変換します
+func corsFromSDK(rules []types.CORSRule) corsDocument {
+	d := corsDocument{CORSRules: make([]corsRule, len(rules))}
+	for i, r := range rules {
+		d.CORSRules[i] = corsRule{
+			ID:             aws.ToString(r.ID),
+			AllowedHeaders: r.AllowedHeaders,
+			AllowedMethods: r.AllowedMethods,
+			AllowedOrigins: r.AllowedOrigins,
+			ExposeHeaders:  r.ExposeHeaders,
+			MaxAgeSeconds:  r.MaxAgeSeconds,
+		}
+	}
+	return d
+}
+
+// parseTags はタグの JSON を検証して SDK のタグに変換します
+func parseTags(doc string) ([]types.Tag, error) {
+	var d taggingDocument
+	if err := decodeDocument(doc, &d); err != nil {
+		return nil, err
+	}
+	if len(d.TagSet) > maxBucketTags {
+		return nil, i18n.Errorf("aws.setting_too_many", "TagSet", maxBucketTags)
+	}
+	keys := map[string]bool{}
+	tags := make([]types.Tag, len(d.TagSet))
+	for i, tag := range d.TagSet {
+		path := fmt.Sprintf("TagSet[%d]", i)
+		if err := checkTag(path, tag); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(tag.Key, "aws:") {
+			return nil, i18n.Errorf("aws.tag_reserved", path, tag.Key)
+		}
+		if keys[tag.Key] {
+			return nil, i18n.Errorf("aws.setting_duplicate", path, "Key", tag.Key)
+		}
+		keys[tag.Key] = true
+		tags[i] = tag.toSDK()
+	}
+	return tags, nil
+}
+
+// checkTag はタグのキーと値の長さを確認します
+func checkTag(path string, tag bucketTag) error {
+	if n := len([]rune(tag.Key)); n == 0 || n > 128 {
+		return i18n.Errorf("aws.tag_key_length", path)
+	}
+	if len([]rune(tag.Value)) > 256 {
+		return i18n.Errorf("aws.tag_value_length", path)
+	}
+	return nil
+}
+
+func (t bucketTag) toSDK() types.Tag {
+	return types.Tag{Key: aws.String(t.Key), Value: aws.String(t.Value)}
+}
+
+// tagsFromSDK は SDK のタグを JSON の形に変換します
+func tagsFromSDK(tags []types.Tag) []bucketTag {
+	result := make([]bucketTag, len(tags))
+	for i, tag := range tags {
+		result[i] = bucketTag{Key: aws.ToString(tag.Key), Value: aws.ToString(tag.Value)}
+	}
+	return result
+}
+
+// validatePolicy はバケットポリシーの構造を確認します。
+// 条件キーや ARN の正しさまでは確認せず、S3 が受け付けない場合はそのエラーを表示します。
+func validatePolicy(doc string) error {
+	if strings.TrimSpace(doc) == "" {
+		return nil
+	}
+	var policy map[string]json.RawMessage
+	if err := decodeDocument(doc, &policy); err != nil {
+		return err
+	}
+	for name := range policy {
+		if name != "Version" && name != "Id" && name != "Statement" {
+			return i18n.Errorf("aws.policy_unknown_field", name)
+		}
+	}
+	if raw, ok := policy["Version"]; ok {
+		var version string
+		if json.Unmarshal(raw, &version) != nil || (version != "2012-10-17" && version != "2008-10-17") {
+			return i18n.Errorf("aws.setting_one_of", "Policy", "Version", "2012-10-17, 2008-10-17")
+		}
+	}
+	raw, ok := policy["Statement"]
+	if !ok {
+		return i18n.Errorf("aws.setting_required", "Policy", "Statement")
+	}
+	// Statement は1つのオブジェクトか、オブジェクトの配列
+	var statements []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &statements); err != nil {
+		var statement map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &statement); err != nil {
+			return i18n.Errorf("aws.policy_statement_type")
+		}
+		statements = append(statements, statement)
+	}
+	if len(statements) == 0 {
+		return i18n.Errorf("aws.setting_required", "Policy", "Statement")
+	}
+	for i, s := range statements {
+		path := fmt.Sprintf("Statement[%d]", i)
+		var effect string
+		if json.Unmarshal(s["Effect"], &effect) != nil || (effect != "Allow" && effect != "Deny") {
+			return i18n.Errorf("aws.setting_one_of", path, "Effect", "Allow, Deny")
+		}
+		for _, pair := range [][2]string{{"Principal", "NotPrincipal"}, {"Action", "NotAction"}, {"Resource", "NotResource"}} {
+			value, ok := s[pair[0]]
+			notValue, notOK := s[pair[1]]
+			switch {
+			case ok == notOK:
+				return i18n.Errorf("aws.setting_exclusive", path, pair[0], pair[1])
+			case notOK:
+				value = notValue
+			}
+			if pair[0] != "Principal" && !isStringOrList(value) {
+				return i18n.Errorf("aws.policy_string_list", path, pair[0])
+			}
+		}
+	}
+	return nil
+}
+
+// isStringOrList は JSON の値が空でない文字列か文字列の配列かを返します
+func isStringOrList(raw json.RawMessage) bool {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s != ""
+	}
+	var list []string
+	return json.Unmarshal(raw, &list) == nil && len(list) > 0
+}
+
+// checkOneOf は値が選択肢のいずれかかを確認します
+func checkOneOf(path, field, value string, choices []string) error {
+	for _, c := range choices {
+		if value == c {
+			return nil
+		}
+	}
+	return i18n.Errorf("aws.setting_one_of", path, field, strings.Join(choices, ", "))
+}
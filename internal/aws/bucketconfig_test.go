@@ -0,0 +1,207 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const testLifecycle = `{
+  "Rules": [
+    {
+      "ID": "expire-logs",
+      "Status": "Enabled",
+      "Filter": {
+        "Prefix": "logs/"
+      },
+      "Expiration": {
+        "Days": 30
+      },
+      "Transitions": [
+        {
+          "Days": 7,
+          "StorageClass": "STANDARD_IA"
+        }
+      ]
+    },
+    {
+      "ID": "abort-uploads",
+      "Status": "Disabled",
+      "Filter": {
+        "And": {
+          "Prefix": "tmp/",
+          "Tags": [
+            {
+              "Key": "temporary",
+              "Value": "true"
+            }
+          ]
+        }
+      },
+      "AbortIncompleteMultipartUpload": {
+        "DaysAfterInitiation": 7
+      }
+    }
+  ]
+}
+`
+
+const testPolicy = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "PublicRead",
+      "Effect": "Allow",
+      "Principal": "*",
+      "Action": "s3:GetObject",
+      "Resource": "arn:aws:s3:::bucket/*"
+    }
+  ]
+}
+`
+
+// TestValidateBucketSetting は設定の JSON の検証をテストします
+func TestValidateBucketSetting(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting BucketSetting
+		doc     string
+		wantErr string // エラーに含まれる文字列。空の場合はエラーにならない
+	}{
+		{name: "ライフサイクル", setting: SettingLifecycle, doc: testLifecycle},
+		{name: "空のライフサイクル", setting: SettingLifecycle, doc: `{"Rules": []}`},
+		{name: "空の内容", setting: SettingLifecycle, doc: "  \n"},
+		{name: "構文エラーの位置", setting: SettingLifecycle, doc: "{\n  \"Rules\": [\n    {,\n", wantErr: "line 3, column 6"},
+		{name: "知らない項目", setting: SettingLifecycle, doc: `{"Rule": []}`, wantErr: `"Rule"`},
+		{name: "型の誤り", setting: SettingLifecycle, doc: `{"Rules": {}}`, wantErr: "line 1, column 11"},
+		{name: "Status がない", setting: SettingLifecycle, doc: `{"Rules": [{"Expiration": {"Days": 1}}]}`, wantErr: "Rules[0]: Status"},
+		{name: "動作がない", setting: SettingLifecycle, doc: `{"Rules": [{"Status": "Enabled"}]}`, wantErr: "at least one action"},
+		{name: "Filter と Prefix", setting: SettingLifecycle, doc: `{"Rules": [{"Status": "Enabled", "Prefix": "", "Filter": {}, "Expiration": {"Days": 1}}]}`, wantErr: "Filter and Prefix"},
+		{name: "Filter の条件が複数", setting: SettingLifecycle, doc: `{"Rules": [{"Status": "Enabled", "Filter": {"Prefix": "a/", "ObjectSizeLessThan": 10}, "Expiration": {"Days": 1}}]}`, wantErr: "Rules[0].Filter"},
+		{name: "不明なストレージクラス", setting: SettingLifecycle, doc: `{"Rules": [{"Status": "Enabled", "Transitions": [{"Days": 1, "StorageClass": "COLD"}]}]}`, wantErr: "Transitions[0]: StorageClass"},
+		{name: "ID の重複", setting: SettingLifecycle, doc: `{"Rules": [{"ID": "a", "Status": "Enabled", "Expiration": {"Days": 1}}, {"ID": "a", "Status": "Enabled", "Expiration": {"Days": 2}}]}`, wantErr: "Rules[1]: duplicate ID"},
+		{name: "CORS", setting: SettingCORS, doc: `{"CORSRules": [{"AllowedMethods": ["GET", "HEAD"], "AllowedOrigins": ["*"], "MaxAgeSeconds": 3000}]}`},
+		{name: "CORS のオリジンがない", setting: SettingCORS, doc: `{"CORSRules": [{"AllowedMethods": ["GET"]}]}`, wantErr: "AllowedOrigins"},
+		{name: "CORS の不明なメソッド", setting: SettingCORS, doc: `{"CORSRules": [{"AllowedMethods": ["PATCH"], "AllowedOrigins": ["*"]}]}`, wantErr: "AllowedMethods"},
+		{name: "タグ", setting: SettingTags, doc: `{"TagSet": [{"Key": "team", "Value": "data"}, {"Key": "env", "Value": ""}]}`},
+		{name: "タグの重複", setting: SettingTags, doc: `{"TagSet": [{"Key": "team", "Value": "a"}, {"Key": "team", "Value": "b"}]}`, wantErr: "TagSet[1]"},
+		{name: "予約されたタグ", setting: SettingTags, doc: `{"TagSet": [{"Key": "aws:owner", "Value": "a"}]}`, wantErr: "aws:"},
+		{name: "空のタグのキー", setting: SettingTags, doc: `{"TagSet": [{"Key": "", "Value": "a"}]}`, wantErr: "TagSet[0]"},
+		{name: "ポリシー", setting: SettingPolicy, doc: testPolicy},
+		{name: "空のポリシー", setting: SettingPolicy, doc: ""},
+		{name: "Statement が1つのオブジェクト", setting: SettingPolicy, doc: `{"Statement": {"Effect": "Deny", "Principal": "*", "NotAction": ["s3:GetObject"], "Resource": "*"}}`},
+		{name: "Statement がない", setting: SettingPolicy, doc: `{"Version": "2012-10-17"}`, wantErr: "Statement"},
+		{name: "不明なバージョン", setting: SettingPolicy, doc: `{"Version": "2020-01-01", "Statement": []}`, wantErr: "Version"},
+		{name: "Effect の誤り", setting: SettingPolicy, doc: `{"Statement": [{"Effect": "allow", "Principal": "*", "Action": "s3:*", "Resource": "*"}]}`, wantErr: "Effect"},
+		{name: "Principal がない", setting: SettingPolicy, doc: `{"Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "*"}]}`, wantErr: "Principal"},
+		{name: "Action と NotAction", setting: SettingPolicy, doc: `{"Statement": [{"Effect": "Allow", "Principal": "*", "Action": "s3:*", "NotAction": "s3:*", "Resource": "*"}]}`, wantErr: "Action and NotAction"},
+		{name: "Resource が空", setting: SettingPolicy, doc: `{"Statement": [{"Effect": "Allow", "Principal": "*", "Action": "s3:*", "Resource": []}]}`, wantErr: "Resource"},
+		{name: "ポリシーの知らない項目", setting: SettingPolicy, doc: `{"Statements": []}`, wantErr: "Statements"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBucketSetting(tt.setting, tt.doc)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("予期しないエラー: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("期待結果 %q を含むエラー, 実際の結果 nil", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("期待結果 %q を含むエラー, 実際の結果 %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestBucketSettings は設定の反映・取得・削除をテストします
+func TestBucketSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting BucketSetting
+		doc     string
+		entries int
+		empty   string // 未設定の場合の内容
+	}{
+		{name: "ライフサイクル", setting: SettingLifecycle, doc: testLifecycle, entries: 2, empty: "{\n  \"Rules\": []\n}\n"},
+		{
+			name:    "CORS",
+			setting: SettingCORS,
+			doc:     "{\n  \"CORSRules\": [\n    {\n      \"AllowedMethods\": [\n        \"GET\"\n      ],\n      \"AllowedOrigins\": [\n        \"*\"\n      ],\n      \"MaxAgeSeconds\": 3000\n    }\n  ]\n}\n",
+			entries: 1,
+			empty:   "{\n  \"CORSRules\": []\n}\n",
+		},
+		{name: "ポリシー", setting: SettingPolicy, doc: testPolicy, entries: 1, empty: ""},
+		{
+			name:    "タグ",
+			setting: SettingTags,
+			doc:     "{\n  \"TagSet\": [\n    {\n      \"Key\": \"team\",\n      \"Value\": \"data\"\n    }\n  ]\n}\n",
+			entries: 1,
+			empty:   "{\n  \"TagSet\": []\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := newFakeClient(newFakeS3("bucket"))
+
+			before := client.GetBucketConfig(ctx, "bucket")
+			if got := before.Documents[tt.setting]; got != tt.empty {
+				t.Errorf("期待結果 %q, 実際の結果 %q", tt.empty, got)
+			}
+
+			if err := client.PutBucketSetting(ctx, "bucket", tt.setting, tt.doc); err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			got, err := client.GetBucketSetting(ctx, "bucket", tt.setting)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			// 取得した内容をそのまま編集し直せるよう、反映した JSON と同じ形で返る
+			if got != tt.doc {
+				t.Errorf("期待結果 %q, 実際の結果 %q", tt.doc, got)
+			}
+			if n := SettingEntries(tt.setting, got); n != tt.entries {
+				t.Errorf("期待結果 %d, 実際の結果 %d", tt.entries, n)
+			}
+
+			// 内容を空にすると設定が削除される
+			if err := client.PutBucketSetting(ctx, "bucket", tt.setting, tt.empty); err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if got, _ := client.GetBucketSetting(ctx, "bucket", tt.setting); got != tt.empty {
+				t.Errorf("期待結果 %q, 実際の結果 %q", tt.empty, got)
+			}
+		})
+	}
+
+	client := newFakeClient(newFakeS3("bucket"))
+	if err := client.PutBucketSetting(context.Background(), "bucket", SettingTags, `{"TagSet": [{"Key": ""}]}`); err == nil {
+		t.Error("不正な設定でエラーが返されませんでした")
+	}
+	if config := client.GetBucketConfig(context.Background(), "missing"); config.Errors[SettingLifecycle] == nil || config.Errors[SettingVersioning] == nil {
+		t.Errorf("存在しないバケットの設定でエラーが返されませんでした: %+v", config.Errors)
+	}
+}
+
+// TestSetBucketVersioning はバージョニングの有効化と停止をテストします
+func TestSetBucketVersioning(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient(newFakeS3("bucket"))
+
+	for _, tt := range []struct {
+		enabled  bool
+		expected string
+	}{
+		{enabled: true, expected: "Enabled"},
+		{enabled: false, expected: "Suspended"},
+	} {
+		if err := client.SetBucketVersioning(ctx, "bucket", tt.enabled); err != nil {
+			t.Fatalf("予期しないエラー: %v", err)
+		}
+		if got := client.GetBucketConfig(ctx, "bucket").Versioning; got != tt.expected {
+			t.Errorf("期待結果 %v, 実際の結果 %v", tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package aws
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// deleteBatchSize は DeleteObjects 1回で削除できるオブジェクトの上限です
+const deleteBatchSize = 1000
+
+// reservedBucketPrefixes と reservedBucketSuffixes はバケット名に使えない接頭辞・接尾辞です
+var (
+	reservedBucketPrefixes = []string{"xn--", "sthree-"}
+	reservedBucketSuffixes = []string{"-s3alias", "--ol-s3", ".mrap", "--x-s3"}
+)
+
+// ValidateBucketName は汎用バケットの命名規則に従っているかを確認します
+func ValidateBucketName(name string) error {
+	if len(name) < 3 || len(name) > 63 {
+		return i18n.Errorf("aws.bucket_name_length", name)
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '-') {
+			return i18n.Errorf("aws.bucket_name_chars", name)
+		}
+	}
+	if !isAlnum(name[0]) || !isAlnum(name[len(name)-1]) {
+		return i18n.Errorf("aws.bucket_name_edge", name)
+	}
+	if strings.Contains(name, "..") {
+		return i18n.Errorf("aws.bucket_name_dots", name)
+	}
+	if net.ParseIP(name) != nil {
+		return i18n.Errorf("aws.bucket_name_ip", name)
+	}
+	for _, prefix := range reservedBucketPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return i18n.Errorf("aws.bucket_name_reserved", name, prefix)
+		}
+	}
+	for _, suffix := range reservedBucketSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return i18n.Errorf("aws.bucket_name_reserved", name, suffix)
+		}
+	}
+	return nil
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+// CreateBucketOptions はバケット作成のオプションです
+type CreateBucketOptions struct {
+	// Region はバケットを作成するリージョンです（空の場合はクライアントのリージョン）
+	Region string
+	// ObjectLock はオブジェクトロックを有効にするかです。有効にするとバージョニングも有効になります
+	ObjectLock bool
+	// Versioning は作成後にバージョニングを有効にするかです
+	Versioning bool
+}
+
+// CreateBucket はバケットを作成します
+func (c *S3Client) CreateBucket(ctx context.Context, bucketName string, opts CreateBucketOptions) error {
+	if err := ValidateBucketName(bucketName); err != nil {
+		return err
+	}
+	region := opts.Region
+	if region == "" {
+		region = c.region
+	}
+
+	input := &s3.CreateBucketInput{
+		Bucket:                     &bucketName,
+		ObjectLockEnabledForBucket: opts.ObjectLock,
+	}
+	// us-east-1 には LocationConstraint を指定できない
+	if region != "us-east-1" {
+		input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+			LocationConstraint: types.BucketLocationConstraint(region),
+		}
+	}
+	if _, err := c.client.CreateBucket(ctx, input, func(o *s3.Options) { o.Region = region }); err != nil {
+		return err
+	}
+	c.logger.Info("bucket created", "bucket", bucketName, "region", region, "object_lock", opts.ObjectLock)
+
+	// オブジェクトロックを有効にした場合はS3がバージョニングを有効にする
+	if opts.Versioning && !opts.ObjectLock {
+		if _, err := c.client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+			Bucket:                  &bucketName,
+			VersioningConfiguration: &types.VersioningConfiguration{Status: types.BucketVersioningStatusEnabled},
+		}, func(o *s3.Options) { o.Region = region }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EmptyBucket はバケット内のすべてのオブジェクトを、過去のバージョンと削除マーカーも含めて削除し、削除した数を返します。
+// progress が nil でない場合は、削除するたびにそれまでに削除した数で呼ばれます。
+func (c *S3Client) EmptyBucket(ctx context.Context, bucketName string, progress func(deleted int)) (int, error) {
+	deleted := 0
+	input := &s3.ListObjectVersionsInput{Bucket: &bucketName}
+	for {
+		out, err := c.client.ListObjectVersions(ctx, input)
+		if err != nil {
+			return deleted, err
+		}
+
+		ids := make([]types.ObjectIdentifier, 0, len(out.Versions)+len(out.DeleteMarkers))
+		for _, v := range out.Versions {
+			ids = append(ids, types.ObjectIdentifier{Key: v.Key, VersionId: v.VersionId})
+		}
+		for _, m := range out.DeleteMarkers {
+			ids = append(ids, types.ObjectIdentifier{Key: m.Key, VersionId: m.VersionId})
+		}
+		for start := 0; start < len(ids); start += deleteBatchSize {
+			batch := ids[start:min(start+deleteBatchSize, len(ids))]
+			result, err := c.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+				Bucket: &bucketName,
+				Delete: &types.Delete{Objects: batch, Quiet: true},
+			})
+			if err != nil {
+				return deleted, err
+			}
+			if len(result.Errors) > 0 {
+				e := result.Errors[0]
+				return deleted + len(batch) - len(result.Errors), i18n.Errorf("aws.delete_failed",
+					aws.ToString(e.Key), aws.ToString(e.VersionId), aws.ToString(e.Code), aws.ToString(e.Message))
+			}
+			deleted += len(batch)
+			if progress != nil {
+				progress(deleted)
+			}
+		}
+
+		if !out.IsTruncated {
+			break
+		}
+		input.KeyMarker, input.VersionIdMarker = out.NextKeyMarker, out.NextVersionIdMarker
+	}
+	c.logger.Info("bucket emptied", "bucket", bucketName, "deleted", deleted)
+	return deleted, nil
+}
+
+// DeleteBucket は空のバケットを削除します
+func (c *S3Client) DeleteBucket(ctx context.Context, bucketName string) error {
+	if _, err := c.client.DeleteBucket(ctx, &s3.DeleteBucketInput{Bucket: &bucketName}); err != nil {
+		return err
+	}
+	c.logger.Info("bucket deleted", "bucket", bucketName)
+	return nil
+}
@@ -0,0 +1,117 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// TestValidateBucketName はバケット名の命名規則の確認をテストします
+func TestValidateBucketName(t *testing.T) {
+	tests := []struct {
+		name    string
+		bucket  string
+		wantErr bool
+	}{
+		{name: "正しい名前", bucket: "my-bucket.logs-2026"},
+		{name: "3文字", bucket: "abc"},
+		{name: "短すぎる", bucket: "ab", wantErr: true},
+		{name: "長すぎる", bucket: "a123456789012345678901234567890123456789012345678901234567890123", wantErr: true},
+		{name: "大文字", bucket: "My-Bucket", wantErr: true},
+		{name: "アンダースコア", bucket: "my_bucket", wantErr: true},
+		{name: "ハイフンで始まる", bucket: "-bucket", wantErr: true},
+		{name: "ドットで終わる", bucket: "bucket.", wantErr: true},
+		{name: "連続したドット", bucket: "my..bucket", wantErr: true},
+		{name: "IPアドレスの形式", bucket: "192.168.5.4", wantErr: true},
+		{name: "予約された接頭辞", bucket: "xn--bucket", wantErr: true},
+		{name: "予約された接尾辞", bucket: "bucket-s3alias", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateBucketName(tt.bucket)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestCreateBucket はリージョン・オブジェクトロック・バージョニングの指定をテストします
+func TestCreateBucket(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           CreateBucketOptions
+		wantRegion     string
+		wantLock       bool
+		wantVersioning types.BucketVersioningStatus
+	}{
+		{name: "既定のリージョン", opts: CreateBucketOptions{}, wantRegion: "us-east-1"},
+		{name: "別のリージョン", opts: CreateBucketOptions{Region: "ap-northeast-1"}, wantRegion: "ap-northeast-1"},
+		{name: "バージョニング", opts: CreateBucketOptions{Versioning: true}, wantRegion: "us-east-1", wantVersioning: types.BucketVersioningStatusEnabled},
+		{
+			name:           "オブジェクトロック",
+			opts:           CreateBucketOptions{Region: "eu-west-1", ObjectLock: true},
+			wantRegion:     "eu-west-1",
+			wantLock:       true,
+			wantVersioning: types.BucketVersioningStatusEnabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeS3()
+			client := newFakeClient(fake)
+			if err := client.CreateBucket(context.Background(), "new-bucket", tt.opts); err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			got := fake.bucketInfo["new-bucket"]
+			want := fakeBucket{region: tt.wantRegion, objectLock: tt.wantLock, versioning: tt.wantVersioning}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("期待結果 %+v, 実際の結果 %+v", want, got)
+			}
+		})
+	}
+
+	client := newFakeClient(newFakeS3())
+	if err := client.CreateBucket(context.Background(), "Bad_Name", CreateBucketOptions{}); err == nil {
+		t.Error("不正なバケット名でエラーが返されませんでした")
+	}
+}
+
+// TestEmptyAndDeleteBucket は過去のバージョンと削除マーカーを含めてバケットを空にし、削除できることをテストします
+func TestEmptyAndDeleteBucket(t *testing.T) {
+	fake := newFakeS3("bucket")
+	client := newFakeClient(fake)
+	for i := 0; i < 1200; i++ {
+		fake.put("bucket", fmt.Sprintf("logs/%04d.txt", i), []byte("x"))
+	}
+	fake.versions["bucket"] = []fakeVersion{
+		{key: "logs/0001.txt", versionID: "v1"},
+		{key: "logs/0001.txt", versionID: "v2"},
+		{key: "removed.txt", versionID: "v3", deleteMarker: true},
+		{key: "removed.txt", versionID: "v4"},
+	}
+
+	if err := client.DeleteBucket(context.Background(), "bucket"); err == nil {
+		t.Fatal("空でないバケットの削除でエラーが返されませんでした")
+	}
+
+	var last int
+	deleted, err := client.EmptyBucket(context.Background(), "bucket", func(n int) { last = n })
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if deleted != 1204 || last != 1204 {
+		t.Errorf("削除した数: 期待結果 %d, 実際の結果 %d（進捗 %d）", 1204, deleted, last)
+	}
+	if err := client.DeleteBucket(context.Background(), "bucket"); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if _, ok := fake.buckets["bucket"]; ok {
+		t.Error("バケットが削除されていません")
+	}
+}
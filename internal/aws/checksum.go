@@ -0,0 +1,207 @@
+package aws
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// 検証に使うアルゴリズムの名前です。S3の追加のチェックサムと、ETagのMD5です
+const (
+	AlgorithmSHA256 = "SHA256"
+	AlgorithmSHA1   = "SHA1"
+	AlgorithmCRC32C = "CRC32C"
+	AlgorithmCRC32  = "CRC32"
+	AlgorithmMD5    = "MD5"
+)
+
+// checksumAlgorithms は追加のチェックサムを優先する順に並べたものです
+var checksumAlgorithms = []string{AlgorithmSHA256, AlgorithmSHA1, AlgorithmCRC32C, AlgorithmCRC32}
+
+// ChecksumError はダウンロードした内容やローカルのファイルが、S3上のオブジェクトと一致しないことを表します
+type ChecksumError struct {
+	Key       string
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+func (e *ChecksumError) Error() string {
+	return i18n.T("aws.checksum_mismatch", e.Key, e.Algorithm, e.Expected, e.Actual)
+}
+
+// objectChecksums はオブジェクトの検証に使える値です
+type objectChecksums struct {
+	ETag      string            `json:"etag"`
+	Checksums map[string]string `json:"checksums,omitempty"` // アルゴリズム名からbase64の値
+	// Encrypted は SSE-KMS（DSSE-KMS を含む）や SSE-C で暗号化されているかです。この場合ETagはMD5ではありません
+	Encrypted bool `json:"encrypted,omitempty"`
+}
+
+// newObjectChecksums は GetObject や HeadObject の応答から検証に使える値を取り出します
+func newObjectChecksums(etag string, crc32, crc32c, sha1, sha256 *string, sse types.ServerSideEncryption, sseCustomer *string) objectChecksums {
+	sums := map[string]string{}
+	for algorithm, value := range map[string]*string{
+		AlgorithmCRC32: crc32, AlgorithmCRC32C: crc32c, AlgorithmSHA1: sha1, AlgorithmSHA256: sha256,
+	} {
+		if v := aws.ToString(value); v != "" {
+			sums[algorithm] = v
+		}
+	}
+	return objectChecksums{
+		ETag:      etag,
+		Checksums: sums,
+		Encrypted: sse == types.ServerSideEncryptionAwsKms || sse == types.ServerSideEncryptionAwsKmsDsse || aws.ToString(sseCustomer) != "",
+	}
+}
+
+// checksumSpec は内容の検証方法です
+type checksumSpec struct {
+	algorithm string
+	expected  string
+	// parts はマルチパートでアップロードされたオブジェクトのETagを検証する場合のパート数です
+	parts int
+}
+
+// spec は検証方法を決めます。全体の追加のチェックサムを優先し、なければETagのMD5を使います。
+// マルチパートのETagの場合は parts が設定され、検証にはパートサイズが必要です。
+// 検証できない場合は ok が false になります。
+func (c objectChecksums) spec() (spec checksumSpec, ok bool) {
+	for _, algorithm := range checksumAlgorithms {
+		// "-N" の付いた値はパートごとのチェックサムから計算した値のため、全体の検証には使えない
+		if v := c.Checksums[algorithm]; v != "" && !strings.Contains(v, "-") {
+			return checksumSpec{algorithm: algorithm, expected: v}, true
+		}
+	}
+	if c.Encrypted {
+		return checksumSpec{}, false
+	}
+	etag := strings.Trim(c.ETag, `"`)
+	digest, suffix, multipart := strings.Cut(etag, "-")
+	if len(digest) != md5.Size*2 {
+		return checksumSpec{}, false
+	}
+	if !multipart {
+		return checksumSpec{algorithm: AlgorithmMD5, expected: digest}, true
+	}
+	parts, err := strconv.Atoi(suffix)
+	if err != nil || parts < 1 {
+		return checksumSpec{}, false
+	}
+	return checksumSpec{algorithm: AlgorithmMD5, expected: etag, parts: parts}, true
+}
+
+// newChecksumHash はアルゴリズムに対応するハッシュを作成します
+func newChecksumHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case AlgorithmSHA256:
+		return sha256.New()
+	case AlgorithmSHA1:
+		return sha1.New()
+	case AlgorithmCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case AlgorithmCRC32:
+		return crc32.NewIEEE()
+	}
+	return md5.New()
+}
+
+// verifier は書き込まれた内容のチェックサムを計算し、期待する値と比べます
+type verifier struct {
+	spec     checksumSpec
+	hash     hash.Hash
+	partSize int64  // マルチパートのETagの場合のパートサイズ
+	written  int64  // 現在のパートに書き込んだバイト数
+	digests  []byte // マルチパートのETagの場合の、完了したパートのMD5
+}
+
+// newVerifier は検証を始めます。マルチパートのETagの場合は partSize が必要です
+func newVerifier(spec checksumSpec, partSize int64) *verifier {
+	return &verifier{spec: spec, hash: newChecksumHash(spec.algorithm), partSize: partSize}
+}
+
+func (v *verifier) Write(p []byte) (int, error) {
+	n := len(p)
+	if v.spec.parts == 0 {
+		v.hash.Write(p)
+		return n, nil
+	}
+	for len(p) > 0 {
+		chunk := min(int64(len(p)), v.partSize-v.written)
+		v.hash.Write(p[:chunk])
+		v.written += chunk
+		p = p[chunk:]
+		if v.written == v.partSize {
+			v.digests = v.hash.Sum(v.digests)
+			v.hash.Reset()
+			v.written = 0
+		}
+	}
+	return n, nil
+}
+
+// sum は計算したチェックサムを、期待する値と同じ表記で返します
+func (v *verifier) sum() string {
+	switch {
+	case v.spec.parts > 0:
+		digests, parts := v.digests, len(v.digests)/md5.Size
+		if v.written > 0 {
+			digests = v.hash.Sum(digests)
+			parts++
+		}
+		total := md5.Sum(digests)
+		return hex.EncodeToString(total[:]) + "-" + strconv.Itoa(parts)
+	case v.spec.algorithm == AlgorithmMD5:
+		return hex.EncodeToString(v.hash.Sum(nil))
+	}
+	return base64.StdEncoding.EncodeToString(v.hash.Sum(nil))
+}
+
+// check は計算したチェックサムが期待する値と一致するかを確認します
+func (v *verifier) check(key string) error {
+	if actual := v.sum(); actual != v.spec.expected {
+		return &ChecksumError{Key: key, Algorithm: v.spec.algorithm, Expected: v.spec.expected, Actual: actual}
+	}
+	return nil
+}
+
+// hashFile はファイルの内容を w に書き込みます（途中までのダウンロードの検証を再開するため）
+func hashFile(path string, w io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// sha256Base64 は r の内容のSHA256をS3のチェックサムの表記（base64）で返します
+func sha256Base64(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// skipSDKChecksumValidation はSDKによる応答のチェックサムの検証を外します。
+// 再開したダウンロードも含めてファイル全体を自前で検証し、不一致を ChecksumError として報告するためです。
+func skipSDKChecksumValidation(stack *middleware.Stack) error {
+	// 検証のミドルウェアがない場合も問題ないため、エラーは無視する
+	stack.Deserialize.Remove("AWSChecksum:ValidateOutputPayloadChecksum")
+	return nil
+}
@@ -0,0 +1,190 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// TestDownloadChecksum はダウンロードした内容がETagや追加のチェックサムで検証されることをテストします
+func TestDownloadChecksum(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	tests := []struct {
+		name      string
+		prepare   func(t *testing.T, fake *fakeS3, client *S3Client)
+		algorithm string
+	}{
+		{
+			name:      "単一パートのETag（MD5）",
+			prepare:   func(t *testing.T, fake *fakeS3, client *S3Client) { fake.put("bucket", "file.bin", data) },
+			algorithm: AlgorithmMD5,
+		},
+		{
+			name:      "アップロード時のSHA256",
+			prepare:   func(t *testing.T, fake *fakeS3, client *S3Client) { upload(t, client, data, UploadOptions{}) },
+			algorithm: AlgorithmSHA256,
+		},
+		{
+			name: "マルチパートのETag",
+			prepare: func(t *testing.T, fake *fakeS3, client *S3Client) {
+				upload(t, client, data, UploadOptions{PartSize: 3000, StateDir: t.TempDir()})
+			},
+			algorithm: AlgorithmMD5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeS3("bucket")
+			client := newFakeClient(fake)
+			tt.prepare(t, fake, client)
+			dir := t.TempDir()
+
+			path, err := client.DownloadObject(context.Background(), "bucket", "file.bin", DownloadOptions{OutputDir: dir})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+				t.Error("ダウンロードした内容が一致しません")
+			}
+
+			// 壊れた内容は ChecksumError になり、保存されない
+			fake.corruptNext = true
+			if err := os.Remove(path); err != nil {
+				t.Fatal(err)
+			}
+			_, err = client.DownloadObject(context.Background(), "bucket", "file.bin", DownloadOptions{OutputDir: dir})
+			var checksumErr *ChecksumError
+			if !errors.As(err, &checksumErr) {
+				t.Fatalf("期待結果 %T, 実際の結果 %v", checksumErr, err)
+			}
+			if checksumErr.Algorithm != tt.algorithm {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tt.algorithm, checksumErr.Algorithm)
+			}
+			for _, p := range []string{path, path + partialSuffix, path + partialMetaSuffix} {
+				if _, err := os.Stat(p); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("%s が残っています: %v", p, err)
+				}
+			}
+		})
+	}
+}
+
+// TestDownloadDSSEKMS は DSSE-KMS で暗号化されたオブジェクトのETagを、MD5として検証しないことをテストします
+func TestDownloadDSSEKMS(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	fake := newFakeS3("bucket")
+	fake.put("bucket", "file.bin", data)
+	fake.meta["bucket/file.bin"] = fakeObjectMeta{encryption: fakeEncryption{sse: types.ServerSideEncryptionAwsKmsDsse}}
+	client := newFakeClient(fake)
+
+	details, err := client.DescribeObject(context.Background(), "bucket", "file.bin")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if details.Encryption.Mode != EncryptionKMS {
+		t.Errorf("期待結果 %v, 実際の結果 %v", EncryptionKMS, details.Encryption.Mode)
+	}
+
+	path, err := client.DownloadObject(context.Background(), "bucket", "file.bin", DownloadOptions{OutputDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+		t.Error("ダウンロードした内容が一致しません")
+	}
+}
+
+// TestResumeDownloadChecksum は再開したダウンロードが、途中までのファイルも含めて検証されることをテストします
+func TestResumeDownloadChecksum(t *testing.T) {
+	data := bytes.Repeat([]byte("abcdefghij"), 1000)
+	fake := newFakeS3("bucket")
+	fake.put("bucket", "file.bin", data)
+	client := newFakeClient(fake)
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "file.bin")
+
+	fake.failBodyAfter = 4000
+	if _, err := client.DownloadObject(context.Background(), "bucket", "file.bin", DownloadOptions{OutputDir: dir}); !errors.Is(err, errFakeInterrupted) {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", errFakeInterrupted, err)
+	}
+	// 途中までのファイルを書き換えておく
+	partial, err := os.ReadFile(outputPath + partialSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial[0] ^= 0xff
+	if err := os.WriteFile(outputPath+partialSuffix, partial, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.DownloadObject(context.Background(), "bucket", "file.bin", DownloadOptions{OutputDir: dir})
+	var checksumErr *ChecksumError
+	if !errors.As(err, &checksumErr) {
+		t.Fatalf("期待結果 %T, 実際の結果 %v", checksumErr, err)
+	}
+	if _, err := os.Stat(outputPath + partialSuffix); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("壊れた途中までのファイルが残っています: %v", err)
+	}
+}
+
+// TestVerifyFile はローカルのファイルとオブジェクトの比較結果をテストします
+func TestVerifyFile(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	tests := []struct {
+		name  string
+		local []byte
+		opts  *UploadOptions // nil の場合はチェックサムなしで直接置く
+		want  VerifyStatus
+	}{
+		{name: "ETagで一致", local: data, want: VerifyOK},
+		{name: "SHA256で一致", local: data, opts: &UploadOptions{}, want: VerifyOK},
+		{name: "マルチパートのETagで一致", local: data, opts: &UploadOptions{PartSize: 3000}, want: VerifyOK},
+		{name: "内容が異なる（ETag）", local: bytes.Repeat([]byte("9876543210"), 1000), want: VerifyMismatch},
+		{name: "内容が異なる（SHA256）", local: bytes.Repeat([]byte("9876543210"), 1000), opts: &UploadOptions{}, want: VerifyMismatch},
+		{name: "内容が異なる（マルチパート）", local: bytes.Repeat([]byte("9876543210"), 1000), opts: &UploadOptions{PartSize: 3000}, want: VerifyMismatch},
+		{name: "サイズが異なる", local: data[:10], want: VerifySizeDiffers},
+		{name: "ローカルにない", want: VerifyMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeS3("bucket")
+			client := newFakeClient(fake)
+			if tt.opts != nil {
+				opts := *tt.opts
+				opts.StateDir = t.TempDir()
+				upload(t, client, data, opts)
+			} else {
+				fake.put("bucket", "file.bin", data)
+			}
+			path := filepath.Join(t.TempDir(), "file.bin")
+			if tt.local != nil {
+				if err := os.WriteFile(path, tt.local, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := client.VerifyFile(context.Background(), "bucket", "file.bin", path)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result.Status != tt.want {
+				t.Errorf("期待結果 %v, 実際の結果 %v (%v)", tt.want, result.Status, result.Err)
+			}
+		})
+	}
+}
+
+// upload はテスト用の内容を "bucket/file.bin" にアップロードします
+func upload(t *testing.T, client *S3Client, data []byte, opts UploadOptions) {
+	t.Helper()
+	if err := client.UploadObject(context.Background(), writeTemp(t, data), "bucket", "file.bin", opts); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+}
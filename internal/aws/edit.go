@@ -0,0 +1,208 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// MaxEditSize はエディタで編集できるオブジェクトの大きさの上限です。
+// 書き戻しは If-Match を付けた1回の PutObject で行うため、マルチパートになる大きさは扱いません。
+const MaxEditSize = 64 << 20
+
+// EditConflictError は編集中にオブジェクトが更新・削除されたため、書き戻さなかったことを表します
+type EditConflictError struct {
+	Bucket string
+	Key    string
+}
+
+func (e *EditConflictError) Error() string {
+	return i18n.T("aws.edit_conflict", e.Bucket, e.Key)
+}
+
+// EditableObject はエディタで編集するためにダウンロードしたオブジェクトです。
+// 書き戻すときに保持する属性と、競合を検出するためのダウンロードした版の ETag を持ちます。
+type EditableObject struct {
+	Bucket string
+	Key    string
+	// Path は編集する一時ファイルです。拡張子でエディタが形式を判別できるよう、キーと同じ名前にします
+	Path string
+	// ETag はダウンロードした版の ETag です。書き戻しの If-Match に使います
+	ETag string
+	// Size は S3 上のオブジェクトの大きさです。書き戻すと書き戻した大きさになります
+	Size int64
+
+	contentType        *string
+	contentEncoding    *string
+	contentDisposition *string
+	contentLanguage    *string
+	cacheControl       *string
+	expires            *time.Time
+	metadata           map[string]string
+	storageClass       types.StorageClass
+	encryption         Encryption
+	checksum           string // 一時ファイルの内容の SHA-256（変更の検出用）
+}
+
+// FetchForEdit はオブジェクトを一時ディレクトリにダウンロードします。使い終わったら Cleanup で削除してください
+func (c *S3Client) FetchForEdit(ctx context.Context, bucketName, key string) (*EditableObject, error) {
+	var resp *s3.GetObjectOutput
+	err := c.withCustomerKey(bucketName, key, false, func(sse sseCustomer) error {
+		var err error
+		resp, err = c.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket:               &bucketName,
+			Key:                  &key,
+			SSECustomerAlgorithm: sse.algorithm,
+			SSECustomerKey:       sse.key,
+			SSECustomerKeyMD5:    sse.keyMD5,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.ContentLength > MaxEditSize {
+		return nil, i18n.Errorf("aws.edit_too_large", key, resp.ContentLength, MaxEditSize)
+	}
+
+	dir, err := os.MkdirTemp("", "s3-cli-edit-*")
+	if err != nil {
+		return nil, err
+	}
+	obj := &EditableObject{
+		Bucket:             bucketName,
+		Key:                key,
+		Path:               filepath.Join(dir, tempFileName(key)),
+		ETag:               aws.ToString(resp.ETag),
+		Size:               resp.ContentLength,
+		contentType:        resp.ContentType,
+		contentEncoding:    resp.ContentEncoding,
+		contentDisposition: resp.ContentDisposition,
+		contentLanguage:    resp.ContentLanguage,
+		cacheControl:       resp.CacheControl,
+		expires:            resp.Expires,
+		metadata:           resp.Metadata,
+		storageClass:       resp.StorageClass,
+		encryption:         encryptionOf(resp.ServerSideEncryption, resp.SSEKMSKeyId, resp.BucketKeyEnabled, resp.SSECustomerAlgorithm),
+	}
+
+	file, err := os.OpenFile(obj.Path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		obj.Cleanup()
+		return nil, err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), c.bandwidth.Reader(ctx, resp.Body))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		obj.Cleanup()
+		return nil, err
+	}
+	obj.checksum = base64.StdEncoding.EncodeToString(hash.Sum(nil))
+	c.logger.Debug("object fetched for editing", "bucket", bucketName, "key", key, "etag", obj.ETag, "path", obj.Path)
+	return obj, nil
+}
+
+// Changed は一時ファイルがダウンロードした時点（または前回の書き戻し）から変更されたかを返します
+func (o *EditableObject) Changed() (bool, error) {
+	file, err := os.Open(o.Path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	checksum, err := sha256Base64(file)
+	if err != nil {
+		return false, err
+	}
+	return checksum != o.checksum, nil
+}
+
+// Cleanup は一時ファイルを削除します
+func (o *EditableObject) Cleanup() {
+	os.RemoveAll(filepath.Dir(o.Path))
+}
+
+// SaveEdited は編集した一時ファイルを、元のコンテンツタイプ・ユーザーメタデータ・暗号化を保ったまま書き戻します。
+// ダウンロードした版の ETag を If-Match に指定するため、その後に他の誰かが更新していた場合は
+// 上書きせずに EditConflictError を返します。
+func (c *S3Client) SaveEdited(ctx context.Context, o *EditableObject) error {
+	file, err := os.Open(o.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() > MaxEditSize {
+		return i18n.Errorf("aws.edit_too_large", o.Key, info.Size(), MaxEditSize)
+	}
+
+	sse, kmsKeyID, bucketKey, customer, err := c.uploadHeaders(o.encryption)
+	if err != nil {
+		return err
+	}
+	checksum, err := sha256Base64(file)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	out, err := c.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:             &o.Bucket,
+		Key:                &o.Key,
+		Body:               c.bandwidth.Reader(ctx, file),
+		ContentLength:      info.Size(),
+		ChecksumSHA256:     aws.String(checksum),
+		ContentType:        o.contentType,
+		ContentEncoding:    o.contentEncoding,
+		ContentDisposition: o.contentDisposition,
+		ContentLanguage:    o.contentLanguage,
+		CacheControl:       o.cacheControl,
+		Expires:            o.expires,
+		Metadata:           o.metadata,
+		StorageClass:       o.storageClass,
+
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          kmsKeyID,
+		BucketKeyEnabled:     bucketKey,
+		SSECustomerAlgorithm: customer.algorithm,
+		SSECustomerKey:       customer.key,
+		SSECustomerKeyMD5:    customer.keyMD5,
+	}, s3.WithAPIOptions(
+		// このSDKの PutObjectInput には IfMatch がないため、ヘッダーを直接付ける
+		smithyhttp.AddHeaderValue("If-Match", o.ETag),
+		v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware,
+	))
+	if isAPIError(err, "PreconditionFailed", "ConditionalRequestConflict", "NoSuchKey") {
+		c.logger.Warn("edited object changed on S3, not overwriting", "bucket", o.Bucket, "key", o.Key, "etag", o.ETag)
+		return &EditConflictError{Bucket: o.Bucket, Key: o.Key}
+	}
+	if err != nil {
+		return err
+	}
+
+	// 続けて編集した場合に、今回書き戻した版を基準にする
+	o.ETag = aws.ToString(out.ETag)
+	o.Size = info.Size()
+	o.checksum = checksum
+	c.logger.Info("edited object saved", "bucket", o.Bucket, "key", o.Key, "etag", o.ETag)
+	return nil
+}
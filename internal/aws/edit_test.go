@@ -0,0 +1,137 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// TestEditObject はオブジェクトの編集と書き戻しをテストします
+func TestEditObject(t *testing.T) {
+	tests := []struct {
+		name       string
+		edit       string // 一時ファイルに書き込む内容。空の場合は編集しない
+		concurrent func(fake *fakeS3)
+		changed    bool
+		conflict   bool
+		expected   string // 書き戻した後の S3 上の内容
+	}{
+		{name: "編集して書き戻す", edit: "edited\n", changed: true, expected: "edited\n"},
+		{name: "変更なし", changed: false, expected: "original\n"},
+		{
+			name:       "編集中に更新された",
+			edit:       "edited\n",
+			concurrent: func(fake *fakeS3) { fake.put("bucket", "docs/readme.md", []byte("other\n")) },
+			changed:    true,
+			conflict:   true,
+			expected:   "other\n",
+		},
+		{
+			name: "編集中に削除された",
+			edit: "edited\n",
+			concurrent: func(fake *fakeS3) {
+				fake.mu.Lock()
+				defer fake.mu.Unlock()
+				delete(fake.buckets["bucket"], "docs/readme.md")
+			},
+			changed:  true,
+			conflict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			fake := newFakeS3("bucket")
+			client := newFakeClient(fake)
+			metadata := map[string]string{"owner": "team"}
+			if _, err := fake.PutObject(ctx, &s3.PutObjectInput{
+				Bucket:      aws.String("bucket"),
+				Key:         aws.String("docs/readme.md"),
+				Body:        strings.NewReader("original\n"),
+				ContentType: aws.String("text/markdown"),
+				Metadata:    metadata,
+			}); err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+
+			obj, err := client.FetchForEdit(ctx, "bucket", "docs/readme.md")
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			defer obj.Cleanup()
+			// エディタが形式を判別できるよう、一時ファイルはキーと同じ名前になる
+			if got := filepath.Base(obj.Path); got != "readme.md" {
+				t.Errorf("期待結果 %v, 実際の結果 %v", "readme.md", got)
+			}
+
+			if tt.edit != "" {
+				if err := os.WriteFile(obj.Path, []byte(tt.edit), 0600); err != nil {
+					t.Fatalf("予期しないエラー: %v", err)
+				}
+			}
+			changed, err := obj.Changed()
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if changed != tt.changed {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tt.changed, changed)
+			}
+			if !changed {
+				return
+			}
+
+			if tt.concurrent != nil {
+				tt.concurrent(fake)
+			}
+			err = client.SaveEdited(ctx, obj)
+			var conflict *EditConflictError
+			if got := errors.As(err, &conflict); got != tt.conflict {
+				t.Fatalf("期待結果 %v, 実際の結果 %v", tt.conflict, err)
+			}
+			data, _ := fake.get("bucket", "docs/readme.md")
+			if string(data) != tt.expected {
+				t.Errorf("期待結果 %q, 実際の結果 %q", tt.expected, data)
+			}
+			if tt.conflict {
+				// 書き戻せなかった編集内容は一時ファイルに残る
+				if _, err := os.Stat(obj.Path); err != nil {
+					t.Errorf("予期しないエラー: %v", err)
+				}
+				return
+			}
+
+			// コンテンツタイプとユーザーメタデータは保たれる
+			meta := fake.meta["bucket/docs/readme.md"]
+			if meta.contentType != "text/markdown" {
+				t.Errorf("期待結果 %v, 実際の結果 %v", "text/markdown", meta.contentType)
+			}
+			if !reflect.DeepEqual(meta.metadata, metadata) {
+				t.Errorf("期待結果 %v, 実際の結果 %v", metadata, meta.metadata)
+			}
+			// 書き戻した版が次の書き戻しの基準になる
+			if changed, _ := obj.Changed(); changed {
+				t.Error("書き戻した後も変更ありと判定されました")
+			}
+			if err := client.SaveEdited(ctx, obj); err != nil {
+				t.Errorf("予期しないエラー: %v", err)
+			}
+		})
+	}
+}
+
+// TestFetchForEditTooLarge は大きすぎるオブジェクトを編集できないことをテストします
+func TestFetchForEditTooLarge(t *testing.T) {
+	fake := newFakeS3("bucket")
+	fake.put("bucket", "large.bin", make([]byte, MaxEditSize+1))
+	if _, err := newFakeClient(fake).FetchForEdit(context.Background(), "bucket", "large.bin"); err == nil {
+		t.Error("大きすぎるオブジェクトでエラーが返されませんでした")
+	}
+}
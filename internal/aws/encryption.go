@@ -0,0 +1,221 @@
+package aws
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// EncryptionMode はオブジェクトのサーバー側暗号化の方式です
+type EncryptionMode string
+
+const (
+	// EncryptionDefault はバケットの既定の暗号化に任せます（オブジェクトの情報では暗号化なしを表します）
+	EncryptionDefault EncryptionMode = ""
+	// EncryptionS3 はS3が管理する鍵で暗号化します（SSE-S3）
+	EncryptionS3 EncryptionMode = "sse-s3"
+	// EncryptionKMS はKMSの鍵で暗号化します（SSE-KMS）
+	EncryptionKMS EncryptionMode = "sse-kms"
+	// EncryptionCustomer は利用者が渡す鍵で暗号化します（SSE-C）
+	EncryptionCustomer EncryptionMode = "sse-c"
+)
+
+// ParseEncryptionMode は文字列をEncryptionModeに変換します。空文字は EncryptionDefault になります
+func ParseEncryptionMode(s string) (EncryptionMode, error) {
+	switch m := EncryptionMode(strings.ToLower(s)); m {
+	case EncryptionDefault, EncryptionS3, EncryptionKMS, EncryptionCustomer:
+		return m, nil
+	}
+	return "", i18n.Errorf("aws.unknown_encryption", s)
+}
+
+// Encryption はオブジェクトのサーバー側暗号化の設定です
+type Encryption struct {
+	Mode EncryptionMode `json:"mode,omitempty"`
+	// KMSKeyID は SSE-KMS の鍵のIDかARNです（空の場合はS3のAWSマネージドキー）
+	KMSKeyID string `json:"kms_key_id,omitempty"`
+	// BucketKey は SSE-KMS でS3バケットキーを使うかです
+	BucketKey bool `json:"bucket_key,omitempty"`
+}
+
+// customerKeySize は SSE-C の鍵（AES-256）のバイト数です
+const customerKeySize = 32
+
+// CustomerKey は SSE-C の鍵です
+type CustomerKey []byte
+
+// ParseCustomerKey は32バイトの鍵か、そのbase64表記から SSE-C の鍵を作ります
+func ParseCustomerKey(data []byte) (CustomerKey, error) {
+	if len(data) == customerKeySize {
+		return CustomerKey(data), nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(decoded) != customerKeySize {
+		return nil, i18n.Errorf("aws.invalid_customer_key", customerKeySize)
+	}
+	return CustomerKey(decoded), nil
+}
+
+// LoadCustomerKey はファイルか環境変数から SSE-C の鍵を読み込みます。
+// ファイルを優先し、どちらも指定されていない場合は nil を返します。
+func LoadCustomerKey(path, envName string) (CustomerKey, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return ParseCustomerKey(data)
+	}
+	if value := os.Getenv(envName); value != "" {
+		return ParseCustomerKey([]byte(value))
+	}
+	return nil, nil
+}
+
+// sseCustomer は SSE-C のリクエストヘッダーの値です。鍵がない場合はすべて nil です
+type sseCustomer struct {
+	algorithm, key, keyMD5 *string
+}
+
+// headers は鍵から SSE-C のヘッダーの値を作ります
+func (k CustomerKey) headers() sseCustomer {
+	if len(k) == 0 {
+		return sseCustomer{}
+	}
+	sum := md5.Sum(k)
+	return sseCustomer{
+		algorithm: aws.String(string(types.ServerSideEncryptionAes256)),
+		key:       aws.String(base64.StdEncoding.EncodeToString(k)),
+		keyMD5:    aws.String(base64.StdEncoding.EncodeToString(sum[:])),
+	}
+}
+
+// uploadHeaders はアップロードに付ける暗号化のヘッダーの値を返します
+func (c *S3Client) uploadHeaders(e Encryption) (sse types.ServerSideEncryption, kmsKeyID *string, bucketKey bool, customer sseCustomer, err error) {
+	switch e.Mode {
+	case EncryptionS3:
+		sse = types.ServerSideEncryptionAes256
+	case EncryptionKMS:
+		sse = types.ServerSideEncryptionAwsKms
+		if e.KMSKeyID != "" {
+			kmsKeyID = aws.String(e.KMSKeyID)
+		}
+		bucketKey = e.BucketKey
+	case EncryptionCustomer:
+		if len(c.customerKey) == 0 {
+			return "", nil, false, sseCustomer{}, i18n.Errorf("aws.customer_key_missing")
+		}
+		customer = c.customerKey.headers()
+	}
+	return sse, kmsKeyID, bucketKey, customer, nil
+}
+
+// needsCustomerKey は SSE-C で暗号化されたオブジェクトを鍵なしで読もうとした場合のエラーかを返します。
+// head は HeadObject の呼び出しかです。
+func needsCustomerKey(err error, head bool) bool {
+	var respErr *smithyhttp.ResponseError
+	if !errors.As(err, &respErr) || respErr.HTTPStatusCode() != http.StatusBadRequest {
+		return false
+	}
+	var apiErr smithy.APIError
+	if head {
+		// HEAD の応答には本文がないため、エラーコードが取れないか、ステータスから作られた BadRequest になる
+		return !errors.As(err, &apiErr) || apiErr.ErrorCode() == "BadRequest"
+	}
+	// GET の 400 は不正な Range などの場合もあるため、SSE-C の鍵がないことを示すエラーだけを対象にする
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidRequest" &&
+		strings.Contains(apiErr.ErrorMessage(), "Server Side Encryption")
+}
+
+// withCustomerKey は call を SSE-C のヘッダーなしで呼び、鍵が必要な場合は鍵を付けて呼び直します。
+// head は call が HeadObject を呼ぶかです。鍵が必要だったオブジェクトは記録し、以降は最初から鍵を付けます。
+func (c *S3Client) withCustomerKey(bucketName, key string, head bool, call func(sseCustomer) error) error {
+	id := bucketName + "/" + key
+	if _, ok := c.sseCObjects.Load(id); ok {
+		return call(c.customerKey.headers())
+	}
+	err := call(sseCustomer{})
+	if err == nil || !needsCustomerKey(err, head) {
+		return err
+	}
+	if len(c.customerKey) == 0 {
+		return i18n.Errorf("aws.customer_key_required", key)
+	}
+	c.sseCObjects.Store(id, true)
+	return call(c.customerKey.headers())
+}
+
+// ObjectDetails は HeadObject で取得するオブジェクトの詳細です
+type ObjectDetails struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	StorageClass string
+	ETag         string
+	ContentType  string
+	Encryption   Encryption
+	// Checksums はアルゴリズム名から追加のチェックサムの値です
+	Checksums map[string]string
+}
+
+// DescribeObject はオブジェクトの詳細を返します。SSE-C のオブジェクトには鍵を付けて問い合わせます
+func (c *S3Client) DescribeObject(ctx context.Context, bucketName, key string) (ObjectDetails, error) {
+	var head *s3.HeadObjectOutput
+	err := c.withCustomerKey(bucketName, key, true, func(sse sseCustomer) error {
+		var err error
+		head, err = c.client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket:               &bucketName,
+			Key:                  &key,
+			ChecksumMode:         types.ChecksumModeEnabled,
+			SSECustomerAlgorithm: sse.algorithm,
+			SSECustomerKey:       sse.key,
+			SSECustomerKeyMD5:    sse.keyMD5,
+		})
+		return err
+	})
+	if err != nil {
+		return ObjectDetails{}, err
+	}
+
+	details := ObjectDetails{
+		Key:          key,
+		Size:         head.ContentLength,
+		LastModified: aws.ToTime(head.LastModified),
+		StorageClass: string(head.StorageClass),
+		ETag:         aws.ToString(head.ETag),
+		ContentType:  aws.ToString(head.ContentType),
+		Encryption:   encryptionOf(head.ServerSideEncryption, head.SSEKMSKeyId, head.BucketKeyEnabled, head.SSECustomerAlgorithm),
+		Checksums: newObjectChecksums(aws.ToString(head.ETag),
+			head.ChecksumCRC32, head.ChecksumCRC32C, head.ChecksumSHA1, head.ChecksumSHA256,
+			head.ServerSideEncryption, head.SSECustomerAlgorithm).Checksums,
+	}
+	if details.StorageClass == "" {
+		details.StorageClass = string(types.ObjectStorageClassStandard)
+	}
+	return details, nil
+}
+
+// encryptionOf は応答のヘッダーからオブジェクトの暗号化の設定を作ります
+func encryptionOf(sse types.ServerSideEncryption, kmsKeyID *string, bucketKey bool, sseCustomer *string) Encryption {
+	switch {
+	case aws.ToString(sseCustomer) != "":
+		return Encryption{Mode: EncryptionCustomer}
+	case sse == types.ServerSideEncryptionAwsKms || sse == types.ServerSideEncryptionAwsKmsDsse:
+		return Encryption{Mode: EncryptionKMS, KMSKeyID: aws.ToString(kmsKeyID), BucketKey: bucketKey}
+	case sse == types.ServerSideEncryptionAes256:
+		return Encryption{Mode: EncryptionS3}
+	}
+	return Encryption{}
+}
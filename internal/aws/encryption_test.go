@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testCustomerKey はテスト用の SSE-C の鍵です
+var testCustomerKey = CustomerKey(bytes.Repeat([]byte{0x42}, customerKeySize))
+
+// TestParseCustomerKey は SSE-C の鍵の読み込みをテストします
+func TestParseCustomerKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "32バイトの鍵", data: testCustomerKey},
+		{name: "base64表記", data: []byte(base64.StdEncoding.EncodeToString(testCustomerKey) + "\n")},
+		{name: "短い鍵", data: []byte("short"), wantErr: true},
+		{name: "base64で長さが違う", data: []byte(base64.StdEncoding.EncodeToString([]byte("0123456789"))), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseCustomerKey(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("エラーを期待しましたが、成功しました: %v", key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if !bytes.Equal(key, testCustomerKey) {
+				t.Errorf("期待結果 %x, 実際の結果 %x", testCustomerKey, key)
+			}
+		})
+	}
+}
+
+// TestUploadEncryption は暗号化の設定がアップロードに反映され、オブジェクトの詳細に表示されることをテストします
+func TestUploadEncryption(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	tests := []struct {
+		name       string
+		encryption Encryption
+		partSize   int64
+	}{
+		{name: "暗号化の指定なし", encryption: Encryption{}},
+		{name: "SSE-S3", encryption: Encryption{Mode: EncryptionS3}},
+		{name: "SSE-KMS", encryption: Encryption{Mode: EncryptionKMS, KMSKeyID: "alias/backup", BucketKey: true}},
+		{name: "SSE-C", encryption: Encryption{Mode: EncryptionCustomer}},
+		{name: "SSE-C のマルチパート", encryption: Encryption{Mode: EncryptionCustomer}, partSize: 3000},
+		{name: "SSE-KMS のマルチパート", encryption: Encryption{Mode: EncryptionKMS}, partSize: 3000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeS3("bucket")
+			client := newFakeClient(fake)
+			client.customerKey = testCustomerKey
+			upload(t, client, data, UploadOptions{PartSize: tt.partSize, StateDir: t.TempDir(), Encryption: tt.encryption})
+
+			// 別のクライアントでも、必要な場合だけ鍵を付けて読めること
+			reader := newFakeClient(fake)
+			reader.customerKey = testCustomerKey
+			details, err := reader.DescribeObject(context.Background(), "bucket", "file.bin")
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if !reflect.DeepEqual(details.Encryption, tt.encryption) {
+				t.Errorf("期待結果 %+v, 実際の結果 %+v", tt.encryption, details.Encryption)
+			}
+
+			path, err := reader.DownloadObject(context.Background(), "bucket", "file.bin", DownloadOptions{OutputDir: t.TempDir()})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if got, _ := os.ReadFile(path); !bytes.Equal(got, data) {
+				t.Error("ダウンロードした内容が一致しません")
+			}
+		})
+	}
+}
+
+// TestCustomerKeyRequired は SSE-C の鍵がない場合のエラーをテストします
+func TestCustomerKeyRequired(t *testing.T) {
+	fake := newFakeS3("bucket")
+	writer := newFakeClient(fake)
+
+	// 鍵がなければ SSE-C でアップロードできない
+	if err := writer.UploadObject(context.Background(), writeTemp(t, []byte("secret")), "bucket", "file.bin",
+		UploadOptions{Encryption: Encryption{Mode: EncryptionCustomer}}); err == nil {
+		t.Fatal("鍵なしの SSE-C のアップロードでエラーが返されませんでした")
+	}
+	writer.customerKey = testCustomerKey
+	upload(t, writer, []byte("secret"), UploadOptions{Encryption: Encryption{Mode: EncryptionCustomer}})
+
+	reader := newFakeClient(fake)
+	_, err := reader.DownloadObject(context.Background(), "bucket", "file.bin", DownloadOptions{OutputDir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "file.bin") {
+		t.Errorf("鍵が必要なことを示すエラーを期待しましたが、実際の結果 %v", err)
+	}
+	if _, err := reader.DescribeObject(context.Background(), "bucket", "file.bin"); err == nil {
+		t.Error("鍵なしの HeadObject でエラーが返されませんでした")
+	}
+}
+
+func TestNeedsCustomerKey(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		head     bool
+		expected bool
+	}{
+		{name: "本文のない HEAD の 400", err: fakeResponseError(http.StatusBadRequest, "", ""), head: true, expected: true},
+		{name: "ステータスから作られた HEAD の BadRequest", err: fakeResponseError(http.StatusBadRequest, "BadRequest", "Bad Request"), head: true, expected: true},
+		{name: "鍵が必要な GET", err: fakeResponseError(http.StatusBadRequest, "InvalidRequest", "The object was stored using a form of Server Side Encryption."), expected: true},
+		{name: "本文のない GET の 400", err: fakeResponseError(http.StatusBadRequest, "", "")},
+		{name: "不正な Range", err: fakeResponseError(http.StatusBadRequest, "InvalidArgument", "Invalid Range")},
+		{name: "鍵を付けてはいけないオブジェクト", err: fakeResponseError(http.StatusBadRequest, "InvalidRequest", "The encryption parameters are not applicable to this object.")},
+		{name: "400 以外", err: fakeResponseError(http.StatusForbidden, "AccessDenied", "Access Denied"), head: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := needsCustomerKey(tc.err, tc.head); got != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestCustomerKeyUnrelatedError は SSE-C と関係のない 400 を、鍵を付けて呼び直さずにそのまま返すことをテストします
+func TestCustomerKeyUnrelatedError(t *testing.T) {
+	client := newFakeClient(newFakeS3("bucket"))
+	client.customerKey = testCustomerKey
+	badRange := fakeResponseError(http.StatusBadRequest, "InvalidArgument", "Invalid Range")
+	calls := 0
+	err := client.withCustomerKey("bucket", "file.bin", false, func(sse sseCustomer) error {
+		calls++
+		return badRange
+	})
+	if err != badRange || calls != 1 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []any{badRange, 1}, []any{err, calls})
+	}
+	if _, ok := client.sseCObjects.Load("bucket/file.bin"); ok {
+		t.Error("SSE-C のオブジェクトとして記録されました")
+	}
+}
+
+// writeTemp は内容を一時ファイルに書き込み、そのパスを返します
+func writeTemp(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "file.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,889 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/tsuna-can/s3-cli/internal/logging"
+)
+
+// fakeS3 はメモリ上にバケットとオブジェクトを持つ s3API の実装です
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string][]byte
+	uploads map[string]*fakeUpload
+	meta    map[string]fakeObjectMeta // "bucket/key" ごとの、内容から計算しない属性
+	// bucketInfo はバケットの作成時の設定です
+	bucketInfo map[string]fakeBucket
+	// versions はバケットごとの過去のバージョンと削除マーカーです（現在のバージョンは buckets にあります）
+	versions map[string][]fakeVersion
+	nextID   int
+
+	// corruptNext が true の場合、次の GetObject の本文は1バイト書き換えられます
+	corruptNext bool
+
+	// failBodyAfter が正の場合、次の GetObject の本文はそのバイト数を返した後にエラーになります
+	failBodyAfter int
+	// failPart が正の場合、次のその番号のパートの UploadPart はエラーになります
+	failPart int32
+	// ranges は GetObject で指定された Range です
+	ranges []string
+	// uploadedParts は UploadPart でアップロードされたパートの番号です
+	uploadedParts []int32
+	// listed は ListObjectsV2 で指定されたプレフィックスと区切り文字です（"prefix|delimiter"）
+	listed []string
+	// listHook が nil でない場合、ListObjectsV2 はロックを取る前にこれを呼び、エラーならそれを返します
+	listHook func(ctx context.Context, prefix string) error
+	// pageSize が正の場合、区切り文字を指定しない ListObjectsV2 はその件数ずつ返します
+	pageSize int
+}
+
+// fakeObjectMeta はアップロード時に決まるオブジェクトの属性です
+type fakeObjectMeta struct {
+	etag       string  // マルチパートの場合の "<md5>-N" 形式のETag
+	sha256     string  // アップロード時に送られたチェックサム
+	partSizes  []int64 // マルチパートの場合のパートの大きさ
+	encryption fakeEncryption
+	// contentType と metadata はアップロード時に指定された属性です
+	contentType string
+	metadata    map[string]string
+}
+
+// fakeEncryption はオブジェクトの暗号化の設定です
+type fakeEncryption struct {
+	sse            types.ServerSideEncryption
+	kmsKeyID       string
+	bucketKey      bool
+	customerKeyMD5 string
+}
+
+// encrypted は ETag が内容のMD5にならない暗号化（SSE-KMS、DSSE-KMS、SSE-C）かを返します
+func (e fakeEncryption) encrypted() bool {
+	return e.sse == types.ServerSideEncryptionAwsKms || e.sse == types.ServerSideEncryptionAwsKmsDsse || e.customerKeyMD5 != ""
+}
+
+// checkCustomerKey は読み書きのリクエストに付けられた SSE-C の鍵を確認します
+func (e fakeEncryption) checkCustomerKey(keyMD5 *string, head bool) error {
+	switch {
+	case e.customerKeyMD5 == "" && keyMD5 != nil:
+		return fakeResponseError(http.StatusBadRequest, "InvalidRequest", "The encryption parameters are not applicable to this object.")
+	case e.customerKeyMD5 != "" && keyMD5 == nil:
+		if head {
+			// HEAD の応答には本文がないため、エラーコードがない
+			return fakeResponseError(http.StatusBadRequest, "", "")
+		}
+		return fakeResponseError(http.StatusBadRequest, "InvalidRequest", "The object was stored using a form of Server Side Encryption.")
+	case e.customerKeyMD5 != "" && aws.ToString(keyMD5) != e.customerKeyMD5:
+		return fakeResponseError(http.StatusForbidden, "AccessDenied", "Access Denied")
+	}
+	return nil
+}
+
+// fakeResponseError はHTTPのステータスコード付きのエラーを作ります
+func fakeResponseError(status int, code, message string) error {
+	var err error = &smithy.GenericAPIError{Code: code, Message: message}
+	if code == "" {
+		err = errors.New(http.StatusText(status))
+	}
+	return &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+		Err:      err,
+	}
+}
+
+// requestHeader は s3.WithAPIOptions で追加されるHTTPヘッダーを返します。
+// SDKの入力の型にない If-Match などは、ミドルウェアでヘッダーとして付けられるためです。
+func requestHeader(optFns []func(*s3.Options)) http.Header {
+	var options s3.Options
+	for _, fn := range optFns {
+		fn(&options)
+	}
+	stack := middleware.NewStack("fake", smithyhttp.NewStackRequest)
+	for _, fn := range options.APIOptions {
+		// 署名などのミドルウェアを差し替えるオプションは、空のスタックでは失敗するため無視する
+		_ = fn(stack)
+	}
+	header := http.Header{}
+	capture := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, middleware.Metadata, error) {
+		if req, ok := input.(*smithyhttp.Request); ok {
+			header = req.Header
+		}
+		return nil, middleware.Metadata{}, nil
+	})
+	middleware.DecorateHandler(capture, stack).Handle(context.Background(), nil)
+	return header
+}
+
+// newFakeEncryption はリクエストの暗号化の指定を記録します
+func newFakeEncryption(sse types.ServerSideEncryption, kmsKeyID *string, bucketKey bool, customerKeyMD5 *string) fakeEncryption {
+	return fakeEncryption{sse: sse, kmsKeyID: aws.ToString(kmsKeyID), bucketKey: bucketKey, customerKeyMD5: aws.ToString(customerKeyMD5)}
+}
+
+// fakeBucket はバケットの設定です
+type fakeBucket struct {
+	region     string
+	objectLock bool
+	versioning types.BucketVersioningStatus
+	lifecycle  []types.LifecycleRule
+	cors       []types.CORSRule
+	policy     string
+	tags       []types.Tag
+}
+
+// fakeVersion は過去のバージョンか削除マーカーです
+type fakeVersion struct {
+	key          string
+	versionID    string
+	deleteMarker bool
+}
+
+// fakeUpload は完了していないマルチパートアップロードです
+type fakeUpload struct {
+	bucket     string
+	key        string
+	initiated  time.Time
+	parts      map[int32][]byte
+	encryption fakeEncryption
+}
+
+// newFakeS3 は空のバケットを持つ fakeS3 を作成します
+func newFakeS3(buckets ...string) *fakeS3 {
+	f := &fakeS3{
+		buckets:    map[string]map[string][]byte{},
+		uploads:    map[string]*fakeUpload{},
+		meta:       map[string]fakeObjectMeta{},
+		bucketInfo: map[string]fakeBucket{},
+		versions:   map[string][]fakeVersion{},
+	}
+	for _, b := range buckets {
+		f.buckets[b] = map[string][]byte{}
+	}
+	return f
+}
+
+// newFakeClient は fakeS3 を使う S3Client を作成します
+func newFakeClient(fake *fakeS3) *S3Client {
+	return &S3Client{
+		client:    fake,
+		region:    "us-east-1",
+		profile:   "default",
+		logger:    logging.Disabled().Logger,
+		bandwidth: NewBandwidthLimiter(0),
+	}
+}
+
+// put はオブジェクトを登録します
+func (f *fakeS3) put(bucket, key string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.buckets[bucket][key] = data
+	delete(f.meta, bucket+"/"+key)
+}
+
+// objectETag はオブジェクトのETagを返します
+func (f *fakeS3) objectETag(bucket, key string, data []byte) string {
+	if m, ok := f.meta[bucket+"/"+key]; ok && m.etag != "" {
+		return m.etag
+	} else if ok && m.encryption.encrypted() {
+		// 暗号化されたオブジェクトのETagは内容のMD5ではない
+		return etag(append([]byte("encrypted:"), data...))
+	}
+	return etag(data)
+}
+
+// sha256Of は内容のSHA256をS3のチェックサムの表記で返します
+func sha256Of(data []byte) string {
+	sum := sha256.Sum256(data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// badDigest はチェックサムが一致しない場合のエラーです
+func badDigest() error {
+	return &smithy.GenericAPIError{Code: "BadDigest", Message: "The sha256 you specified did not match the calculated checksum."}
+}
+
+// get はオブジェクトの内容を返します
+func (f *fakeS3) get(bucket, key string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.buckets[bucket][key]
+	return data, ok
+}
+
+// etag はオブジェクトやパートの内容から ETag を計算します
+func etag(data []byte) string {
+	return fmt.Sprintf("%q", fmt.Sprintf("%x", md5.Sum(data)))
+}
+
+// errFakeInterrupted は転送の中断を表すエラーです
+var errFakeInterrupted = errors.New("connection reset")
+
+// failingReader は指定したバイト数を返した後にエラーになる io.Reader です
+type failingReader struct {
+	r    io.Reader
+	left int
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.left <= 0 {
+		return 0, errFakeInterrupted
+	}
+	if len(p) > r.left {
+		p = p[:r.left]
+	}
+	n, err := r.r.Read(p)
+	r.left -= n
+	return n, err
+}
+
+func (f *fakeS3) objects(bucket string) (map[string][]byte, error) {
+	objects, ok := f.buckets[bucket]
+	if !ok {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchBucket", Message: "The specified bucket does not exist"}
+	}
+	return objects, nil
+}
+
+func (f *fakeS3) ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	names := make([]string, 0, len(f.buckets))
+	for name := range f.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := &s3.ListBucketsOutput{}
+	for _, name := range names {
+		out.Buckets = append(out.Buckets, types.Bucket{Name: aws.String(name)})
+	}
+	return out, nil
+}
+
+func (f *fakeS3) CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := aws.ToString(params.Bucket)
+	if _, ok := f.buckets[name]; ok {
+		return nil, &types.BucketAlreadyOwnedByYou{Message: aws.String("Your previous request to create the named bucket succeeded and you already own it.")}
+	}
+	var options s3.Options
+	for _, fn := range optFns {
+		fn(&options)
+	}
+	info := fakeBucket{region: "us-east-1", objectLock: params.ObjectLockEnabledForBucket}
+	if c := params.CreateBucketConfiguration; c != nil {
+		info.region = string(c.LocationConstraint)
+		if info.region != options.Region {
+			return nil, &smithy.GenericAPIError{Code: "IllegalLocationConstraintException", Message: "The location constraint is incompatible with the region of the request"}
+		}
+	}
+	if info.objectLock {
+		info.versioning = types.BucketVersioningStatusEnabled
+	}
+	f.buckets[name] = map[string][]byte{}
+	f.bucketInfo[name] = info
+	return &s3.CreateBucketOutput{}, nil
+}
+
+func (f *fakeS3) PutBucketVersioning(ctx context.Context, params *s3.PutBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.PutBucketVersioningOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := aws.ToString(params.Bucket)
+	if _, err := f.objects(name); err != nil {
+		return nil, err
+	}
+	info := f.bucketInfo[name]
+	info.versioning = params.VersioningConfiguration.Status
+	f.bucketInfo[name] = info
+	return &s3.PutBucketVersioningOutput{}, nil
+}
+
+// updateBucket はバケットの設定を更新します
+func (f *fakeS3) updateBucket(bucket *string, update func(info *fakeBucket) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := aws.ToString(bucket)
+	if _, err := f.objects(name); err != nil {
+		return err
+	}
+	info := f.bucketInfo[name]
+	if err := update(&info); err != nil {
+		return err
+	}
+	f.bucketInfo[name] = info
+	return nil
+}
+
+// bucketSetting はバケットの設定を返します。設定がない場合は code のエラーです
+func (f *fakeS3) bucketSetting(bucket *string, code string, isSet func(info fakeBucket) bool) (fakeBucket, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := aws.ToString(bucket)
+	if _, err := f.objects(name); err != nil {
+		return fakeBucket{}, err
+	}
+	info := f.bucketInfo[name]
+	if !isSet(info) {
+		return fakeBucket{}, &smithy.GenericAPIError{Code: code, Message: "The configuration does not exist"}
+	}
+	return info, nil
+}
+
+func (f *fakeS3) GetBucketVersioning(ctx context.Context, params *s3.GetBucketVersioningInput, optFns ...func(*s3.Options)) (*s3.GetBucketVersioningOutput, error) {
+	info, err := f.bucketSetting(params.Bucket, "", func(fakeBucket) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	return &s3.GetBucketVersioningOutput{Status: info.versioning}, nil
+}
+
+func (f *fakeS3) GetBucketLifecycleConfiguration(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+	info, err := f.bucketSetting(params.Bucket, "NoSuchLifecycleConfiguration", func(b fakeBucket) bool { return len(b.lifecycle) > 0 })
+	if err != nil {
+		return nil, err
+	}
+	return &s3.GetBucketLifecycleConfigurationOutput{Rules: info.lifecycle}, nil
+}
+
+func (f *fakeS3) PutBucketLifecycleConfiguration(ctx context.Context, params *s3.PutBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketLifecycleConfigurationOutput, error) {
+	return &s3.PutBucketLifecycleConfigurationOutput{}, f.updateBucket(params.Bucket, func(info *fakeBucket) error {
+		if len(params.LifecycleConfiguration.Rules) == 0 {
+			return &smithy.GenericAPIError{Code: "MalformedXML", Message: "The XML you provided was not well-formed"}
+		}
+		info.lifecycle = params.LifecycleConfiguration.Rules
+		return nil
+	})
+}
+
+func (f *fakeS3) DeleteBucketLifecycle(ctx context.Context, params *s3.DeleteBucketLifecycleInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketLifecycleOutput, error) {
+	return &s3.DeleteBucketLifecycleOutput{}, f.updateBucket(params.Bucket, func(info *fakeBucket) error {
+		info.lifecycle = nil
+		return nil
+	})
+}
+
+func (f *fakeS3) GetBucketCors(ctx context.Context, params *s3.GetBucketCorsInput, optFns ...func(*s3.Options)) (*s3.GetBucketCorsOutput, error) {
+	info, err := f.bucketSetting(params.Bucket, "NoSuchCORSConfiguration", func(b fakeBucket) bool { return len(b.cors) > 0 })
+	if err != nil {
+		return nil, err
+	}
+	return &s3.GetBucketCorsOutput{CORSRules: info.cors}, nil
+}
+
+func (f *fakeS3) PutBucketCors(ctx context.Context, params *s3.PutBucketCorsInput, optFns ...func(*s3.Options)) (*s3.PutBucketCorsOutput, error) {
+	return &s3.PutBucketCorsOutput{}, f.updateBucket(params.Bucket, func(info *fakeBucket) error {
+		info.cors = params.CORSConfiguration.CORSRules
+		return nil
+	})
+}
+
+func (f *fakeS3) DeleteBucketCors(ctx context.Context, params *s3.DeleteBucketCorsInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketCorsOutput, error) {
+	return &s3.DeleteBucketCorsOutput{}, f.updateBucket(params.Bucket, func(info *fakeBucket) error {
+		info.cors = nil
+		return nil
+	})
+}
+
+func (f *fakeS3) GetBucketPolicy(ctx context.Context, params *s3.GetBucketPolicyInput, optFns ...func(*s3.Options)) (*s3.GetBucketPolicyOutput, error) {
+	info, err := f.bucketSetting(params.Bucket, "NoSuchBucketPolicy", func(b fakeBucket) bool { return b.policy != "" })
+	if err != nil {
+		return nil, err
+	}
+	return &s3.GetBucketPolicyOutput{Policy: aws.String(info.policy)}, nil
+}
+
+func (f *fakeS3) PutBucketPolicy(ctx context.Context, params *s3.PutBucketPolicyInput, optFns ...func(*s3.Options)) (*s3.PutBucketPolicyOutput, error) {
+	return &s3.PutBucketPolicyOutput{}, f.updateBucket(params.Bucket, func(info *fakeBucket) error {
+		// S3 と同じく空白を除いて保存する
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, []byte(aws.ToString(params.Policy))); err != nil {
+			return &smithy.GenericAPIError{Code: "MalformedPolicy", Message: "Policies must be valid JSON"}
+		}
+		info.policy = compact.String()
+		return nil
+	})
+}
+
+func (f *fakeS3) DeleteBucketPolicy(ctx context.Context, params *s3.DeleteBucketPolicyInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketPolicyOutput, error) {
+	return &s3.DeleteBucketPolicyOutput{}, f.updateBucket(params.Bucket, func(info *fakeBucket) error {
+		info.policy = ""
+		return nil
+	})
+}
+
+func (f *fakeS3) GetBucketTagging(ctx context.Context, params *s3.GetBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.GetBucketTaggingOutput, error) {
+	info, err := f.bucketSetting(params.Bucket, "NoSuchTagSet", func(b fakeBucket) bool { return len(b.tags) > 0 })
+	if err != nil {
+		return nil, err
+	}
+	return &s3.GetBucketTaggingOutput{TagSet: info.tags}, nil
+}
+
+func (f *fakeS3) PutBucketTagging(ctx context.Context, params *s3.PutBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.PutBucketTaggingOutput, error) {
+	return &s3.PutBucketTaggingOutput{}, f.updateBucket(params.Bucket, func(info *fakeBucket) error {
+		info.tags = params.Tagging.TagSet
+		return nil
+	})
+}
+
+func (f *fakeS3) DeleteBucketTagging(ctx context.Context, params *s3.DeleteBucketTaggingInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketTaggingOutput, error) {
+	return &s3.DeleteBucketTaggingOutput{}, f.updateBucket(params.Bucket, func(info *fakeBucket) error {
+		info.tags = nil
+		return nil
+	})
+}
+
+func (f *fakeS3) DeleteBucket(ctx context.Context, params *s3.DeleteBucketInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := aws.ToString(params.Bucket)
+	objects, err := f.objects(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) > 0 || len(f.versions[name]) > 0 {
+		return nil, &smithy.GenericAPIError{Code: "BucketNotEmpty", Message: "The bucket you tried to delete is not empty"}
+	}
+	delete(f.buckets, name)
+	delete(f.bucketInfo, name)
+	return &s3.DeleteBucketOutput{}, nil
+}
+
+func (f *fakeS3) ListObjectVersions(ctx context.Context, params *s3.ListObjectVersionsInput, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := aws.ToString(params.Bucket)
+	objects, err := f.objects(name)
+	if err != nil {
+		return nil, err
+	}
+	// 現在のバージョンは "null" のバージョンIDで返す
+	all := append([]fakeVersion(nil), f.versions[name]...)
+	for key := range objects {
+		all = append(all, fakeVersion{key: key, versionID: "null"})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].key != all[j].key {
+			return all[i].key < all[j].key
+		}
+		return all[i].versionID < all[j].versionID
+	})
+
+	keyMarker, versionMarker := aws.ToString(params.KeyMarker), aws.ToString(params.VersionIdMarker)
+	maxKeys := int(params.MaxKeys)
+	if maxKeys <= 0 {
+		maxKeys = 1000
+	}
+	out := &s3.ListObjectVersionsOutput{}
+	count := 0
+	for _, v := range all {
+		if keyMarker != "" && (v.key < keyMarker || v.key == keyMarker && v.versionID <= versionMarker) {
+			continue
+		}
+		if count == maxKeys {
+			out.IsTruncated = true
+			break
+		}
+		if v.deleteMarker {
+			out.DeleteMarkers = append(out.DeleteMarkers, types.DeleteMarkerEntry{Key: aws.String(v.key), VersionId: aws.String(v.versionID)})
+		} else {
+			out.Versions = append(out.Versions, types.ObjectVersion{Key: aws.String(v.key), VersionId: aws.String(v.versionID)})
+		}
+		out.NextKeyMarker, out.NextVersionIdMarker = aws.String(v.key), aws.String(v.versionID)
+		count++
+	}
+	if !out.IsTruncated {
+		out.NextKeyMarker, out.NextVersionIdMarker = nil, nil
+	}
+	return out, nil
+}
+
+func (f *fakeS3) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := aws.ToString(params.Bucket)
+	objects, err := f.objects(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(params.Delete.Objects) > 1000 {
+		return nil, &smithy.GenericAPIError{Code: "MalformedXML", Message: "The XML you provided was not well-formed"}
+	}
+	out := &s3.DeleteObjectsOutput{}
+	for _, id := range params.Delete.Objects {
+		key, versionID := aws.ToString(id.Key), aws.ToString(id.VersionId)
+		if versionID == "" || versionID == "null" {
+			delete(objects, key)
+			delete(f.meta, name+"/"+key)
+		} else {
+			versions := f.versions[name][:0]
+			for _, v := range f.versions[name] {
+				if v.key != key || v.versionID != versionID {
+					versions = append(versions, v)
+				}
+			}
+			f.versions[name] = versions
+		}
+		if !params.Delete.Quiet {
+			out.Deleted = append(out.Deleted, types.DeletedObject{Key: id.Key, VersionId: id.VersionId})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeS3) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	prefix, delimiter := aws.ToString(params.Prefix), aws.ToString(params.Delimiter)
+	if f.listHook != nil {
+		if err := f.listHook(ctx, prefix); err != nil {
+			return nil, err
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listed = append(f.listed, prefix+"|"+delimiter)
+	objects, err := f.objects(aws.ToString(params.Bucket))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	out := &s3.ListObjectsV2Output{}
+	if f.pageSize > 0 && delimiter == "" {
+		// 継続トークンは前のページの最後のキーとする
+		if token := aws.ToString(params.ContinuationToken); token != "" {
+			keys = keys[sort.SearchStrings(keys, token+"\x00"):]
+		}
+		if len(keys) > f.pageSize {
+			keys = keys[:f.pageSize]
+			out.IsTruncated = true
+			out.NextContinuationToken = aws.String(keys[len(keys)-1])
+		}
+	}
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				common := key[:len(prefix)+i+len(delimiter)]
+				if !seen[common] {
+					seen[common] = true
+					out.CommonPrefixes = append(out.CommonPrefixes, types.CommonPrefix{Prefix: aws.String(common)})
+				}
+				continue
+			}
+		}
+		out.Contents = append(out.Contents, types.Object{
+			Key:          aws.String(key),
+			Size:         int64(len(objects[key])),
+			LastModified: aws.Time(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)),
+		})
+	}
+	return out, nil
+}
+
+func (f *fakeS3) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	objects, err := f.objects(aws.ToString(params.Bucket))
+	if err != nil {
+		return nil, err
+	}
+	data, ok := objects[aws.ToString(params.Key)]
+	if !ok {
+		return nil, &types.NoSuchKey{Message: aws.String("The specified key does not exist.")}
+	}
+	bucket, key := aws.ToString(params.Bucket), aws.ToString(params.Key)
+	meta := f.meta[bucket+"/"+key]
+	if err := meta.encryption.checkCustomerKey(params.SSECustomerKeyMD5, false); err != nil {
+		return nil, err
+	}
+	objectETag := f.objectETag(bucket, key, data)
+	if params.IfMatch != nil && aws.ToString(params.IfMatch) != objectETag {
+		return nil, &smithy.GenericAPIError{Code: "PreconditionFailed", Message: "At least one of the pre-conditions you specified did not hold"}
+	}
+
+	body := data
+	if params.Range != nil {
+		f.ranges = append(f.ranges, aws.ToString(params.Range))
+		var start int
+		if _, err := fmt.Sscanf(aws.ToString(params.Range), "bytes=%d-", &start); err != nil || start >= len(data) {
+			return nil, &smithy.GenericAPIError{Code: "InvalidRange", Message: "The requested range is not satisfiable"}
+		}
+		body = data[start:]
+	}
+
+	out := &s3.GetObjectOutput{
+		ContentLength:        int64(len(body)),
+		ETag:                 aws.String(objectETag),
+		ServerSideEncryption: meta.encryption.sse,
+		Metadata:             meta.metadata,
+	}
+	if meta.contentType != "" {
+		out.ContentType = aws.String(meta.contentType)
+	}
+	if meta.encryption.customerKeyMD5 != "" {
+		out.SSECustomerAlgorithm = aws.String("AES256")
+	}
+	// 範囲指定の場合は全体のチェックサムを返さない
+	if params.ChecksumMode == types.ChecksumModeEnabled && params.Range == nil && meta.sha256 != "" {
+		out.ChecksumSHA256 = aws.String(meta.sha256)
+	}
+	if f.corruptNext && len(body) > 0 {
+		body = append([]byte(nil), body...)
+		body[len(body)/2] ^= 0xff
+		f.corruptNext = false
+	}
+
+	var r io.Reader = bytes.NewReader(body)
+	if f.failBodyAfter > 0 {
+		r = &failingReader{r: r, left: f.failBodyAfter}
+		f.failBodyAfter = 0
+	}
+	out.Body = io.NopCloser(r)
+	return out, nil
+}
+
+func (f *fakeS3) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	objects, err := f.objects(aws.ToString(params.Bucket))
+	if err != nil {
+		return nil, err
+	}
+	bucket, key := aws.ToString(params.Bucket), aws.ToString(params.Key)
+	data, ok := objects[key]
+	if !ok {
+		return nil, &smithy.GenericAPIError{Code: "NotFound", Message: "Not Found"}
+	}
+	meta := f.meta[bucket+"/"+key]
+	if err := meta.encryption.checkCustomerKey(params.SSECustomerKeyMD5, true); err != nil {
+		return nil, err
+	}
+	objectETag := f.objectETag(bucket, key, data)
+	if params.IfMatch != nil && aws.ToString(params.IfMatch) != objectETag {
+		return nil, &smithy.GenericAPIError{Code: "PreconditionFailed", Message: "At least one of the pre-conditions you specified did not hold"}
+	}
+
+	out := &s3.HeadObjectOutput{
+		ContentLength:        int64(len(data)),
+		ETag:                 aws.String(objectETag),
+		ServerSideEncryption: meta.encryption.sse,
+		BucketKeyEnabled:     meta.encryption.bucketKey,
+	}
+	if meta.encryption.kmsKeyID != "" {
+		out.SSEKMSKeyId = aws.String(meta.encryption.kmsKeyID)
+	}
+	if meta.encryption.customerKeyMD5 != "" {
+		out.SSECustomerAlgorithm = aws.String("AES256")
+	}
+	if params.PartNumber > 0 {
+		if int(params.PartNumber) > len(meta.partSizes) {
+			if params.PartNumber != 1 {
+				return nil, &smithy.GenericAPIError{Code: "InvalidPartNumber", Message: "The requested partnumber is not satisfiable"}
+			}
+		} else {
+			out.ContentLength = meta.partSizes[params.PartNumber-1]
+		}
+	}
+	if params.ChecksumMode == types.ChecksumModeEnabled && meta.sha256 != "" {
+		out.ChecksumSHA256 = aws.String(meta.sha256)
+	}
+	return out, nil
+}
+
+func (f *fakeS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	// 本文の読み出し中はロックを持たない（帯域制限で待つため）
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	objects, err := f.objects(aws.ToString(params.Bucket))
+	if err != nil {
+		return nil, err
+	}
+	if params.ChecksumSHA256 != nil && aws.ToString(params.ChecksumSHA256) != sha256Of(data) {
+		return nil, badDigest()
+	}
+	key := aws.ToString(params.Bucket) + "/" + aws.ToString(params.Key)
+	if ifMatch := requestHeader(optFns).Get("If-Match"); ifMatch != "" {
+		current, ok := objects[aws.ToString(params.Key)]
+		if !ok {
+			return nil, &types.NoSuchKey{Message: aws.String("The specified key does not exist.")}
+		}
+		if ifMatch != f.objectETag(aws.ToString(params.Bucket), aws.ToString(params.Key), current) {
+			return nil, &smithy.GenericAPIError{Code: "PreconditionFailed", Message: "At least one of the pre-conditions you specified did not hold"}
+		}
+	}
+	objects[aws.ToString(params.Key)] = data
+	f.meta[key] = fakeObjectMeta{
+		sha256:      aws.ToString(params.ChecksumSHA256),
+		encryption:  newFakeEncryption(params.ServerSideEncryption, params.SSEKMSKeyId, params.BucketKeyEnabled, params.SSECustomerKeyMD5),
+		contentType: aws.ToString(params.ContentType),
+		metadata:    params.Metadata,
+	}
+	return &s3.PutObjectOutput{ETag: aws.String(f.objectETag(aws.ToString(params.Bucket), aws.ToString(params.Key), data))}, nil
+}
+
+func (f *fakeS3) upload(id string) (*fakeUpload, error) {
+	u, ok := f.uploads[id]
+	if !ok {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchUpload", Message: "The specified upload does not exist"}
+	}
+	return u, nil
+}
+
+func (f *fakeS3) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.objects(aws.ToString(params.Bucket)); err != nil {
+		return nil, err
+	}
+	f.nextID++
+	id := fmt.Sprintf("upload-%d", f.nextID)
+	f.uploads[id] = &fakeUpload{
+		bucket:     aws.ToString(params.Bucket),
+		key:        aws.ToString(params.Key),
+		initiated:  time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(f.nextID) * time.Hour),
+		parts:      map[int32][]byte{},
+		encryption: newFakeEncryption(params.ServerSideEncryption, params.SSEKMSKeyId, params.BucketKeyEnabled, params.SSECustomerKeyMD5),
+	}
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String(id)}, nil
+}
+
+func (f *fakeS3) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	f.mu.Lock()
+	if f.failPart > 0 && f.failPart == params.PartNumber {
+		f.failPart = 0
+		f.mu.Unlock()
+		return nil, errFakeInterrupted
+	}
+	f.mu.Unlock()
+
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, err := f.upload(aws.ToString(params.UploadId))
+	if err != nil {
+		return nil, err
+	}
+	if params.ChecksumSHA256 != nil && aws.ToString(params.ChecksumSHA256) != sha256Of(data) {
+		return nil, badDigest()
+	}
+	if u.encryption.customerKeyMD5 != aws.ToString(params.SSECustomerKeyMD5) {
+		return nil, fakeResponseError(http.StatusBadRequest, "InvalidRequest", "The SSE-C parameters do not match the upload.")
+	}
+	u.parts[params.PartNumber] = data
+	f.uploadedParts = append(f.uploadedParts, params.PartNumber)
+	return &s3.UploadPartOutput{ETag: aws.String(etag(data))}, nil
+}
+
+func (f *fakeS3) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, err := f.upload(aws.ToString(params.UploadId))
+	if err != nil {
+		return nil, err
+	}
+	var data, digests, checksums []byte
+	meta := fakeObjectMeta{encryption: u.encryption}
+	for _, p := range params.MultipartUpload.Parts {
+		part, ok := u.parts[p.PartNumber]
+		if !ok || etag(part) != aws.ToString(p.ETag) {
+			return nil, &smithy.GenericAPIError{Code: "InvalidPart", Message: "One or more of the specified parts could not be found"}
+		}
+		if p.ChecksumSHA256 != nil && aws.ToString(p.ChecksumSHA256) != sha256Of(part) {
+			return nil, &smithy.GenericAPIError{Code: "InvalidPart", Message: "The checksum of the part did not match"}
+		}
+		data = append(data, part...)
+		md5sum := md5.Sum(part)
+		digests = append(digests, md5sum[:]...)
+		shasum := sha256.Sum256(part)
+		checksums = append(checksums, shasum[:]...)
+		meta.partSizes = append(meta.partSizes, int64(len(part)))
+	}
+	n := len(params.MultipartUpload.Parts)
+	total := md5.Sum(digests)
+	meta.etag = fmt.Sprintf("\"%x-%d\"", total, n)
+	// 追加のチェックサムは、パートのチェックサムから計算した "-N" 付きの値になる
+	meta.sha256 = fmt.Sprintf("%s-%d", sha256Of(checksums), n)
+	f.buckets[u.bucket][u.key] = data
+	f.meta[u.bucket+"/"+u.key] = meta
+	delete(f.uploads, aws.ToString(params.UploadId))
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (f *fakeS3) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.upload(aws.ToString(params.UploadId)); err != nil {
+		return nil, err
+	}
+	delete(f.uploads, aws.ToString(params.UploadId))
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func (f *fakeS3) ListParts(ctx context.Context, params *s3.ListPartsInput, optFns ...func(*s3.Options)) (*s3.ListPartsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, err := f.upload(aws.ToString(params.UploadId))
+	if err != nil {
+		return nil, err
+	}
+	out := &s3.ListPartsOutput{}
+	for number, data := range u.parts {
+		out.Parts = append(out.Parts, types.Part{PartNumber: number, ETag: aws.String(etag(data)), Size: int64(len(data))})
+	}
+	sort.Slice(out.Parts, func(i, j int) bool { return out.Parts[i].PartNumber < out.Parts[j].PartNumber })
+	return out, nil
+}
+
+func (f *fakeS3) ListMultipartUploads(ctx context.Context, params *s3.ListMultipartUploadsInput, optFns ...func(*s3.Options)) (*s3.ListMultipartUploadsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.objects(aws.ToString(params.Bucket)); err != nil {
+		return nil, err
+	}
+	out := &s3.ListMultipartUploadsOutput{}
+	for id, u := range f.uploads {
+		if u.bucket == aws.ToString(params.Bucket) {
+			out.Uploads = append(out.Uploads, types.MultipartUpload{
+				Key:       aws.String(u.key),
+				UploadId:  aws.String(id),
+				Initiated: aws.Time(u.initiated),
+			})
+		}
+	}
+	sort.Slice(out.Uploads, func(i, j int) bool {
+		return aws.ToString(out.Uploads[i].Key) < aws.ToString(out.Uploads[j].Key)
+	})
+	return out, nil
+}
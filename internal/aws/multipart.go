@@ -0,0 +1,378 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// DefaultPartSize はマルチパートアップロードの既定のパートサイズです。
+// これより大きいファイルはマルチパートでアップロードします。
+const DefaultPartSize int64 = 16 << 20
+
+// maxParts はマルチパートアップロード1件のパート数の上限です
+const maxParts = 10000
+
+// MultipartUpload は完了していないマルチパートアップロードです
+type MultipartUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+	// Parts と Size はアップロード済みのパートの数と合計サイズです（CountParts で設定します）
+	Parts int
+	Size  int64
+}
+
+// uploadState は中断したマルチパートアップロードを再開するための情報です
+type uploadState struct {
+	Path     string    `json:"path"`
+	Bucket   string    `json:"bucket"`
+	Key      string    `json:"key"`
+	UploadID string    `json:"upload_id"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	PartSize int64     `json:"part_size"`
+	// ChecksumAlgorithm はパートごとに送るチェックサムのアルゴリズムです
+	ChecksumAlgorithm string `json:"checksum_algorithm"`
+	// Encryption と CustomerKeyMD5 はアップロード開始時の暗号化の設定です。変わった場合は最初からやり直します
+	Encryption     Encryption     `json:"encryption"`
+	CustomerKeyMD5 string         `json:"customer_key_md5,omitempty"`
+	Parts          []uploadedPart `json:"parts"`
+}
+
+// uploadedPart はアップロード済みのパートです
+type uploadedPart struct {
+	Number         int32  `json:"number"`
+	ETag           string `json:"etag"`
+	ChecksumSHA256 string `json:"checksum_sha256"`
+}
+
+// DefaultUploadStateDir はマルチパートアップロードの再開用の情報を保存する既定のディレクトリを返します
+func DefaultUploadStateDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "s3-cli", "uploads"), nil
+}
+
+// uploadStatePath はファイルとアップロード先の組に対応する状態ファイルのパスを返します
+func uploadStatePath(dir, path, bucket, key string) string {
+	sum := sha256.Sum256([]byte(path + "\x00" + bucket + "\x00" + key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// loadUploadState は状態ファイルを読み込みます。ファイルがない場合は nil を返します
+func loadUploadState(statePath string) (*uploadState, error) {
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state uploadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		// 壊れた状態ファイルは使わずに最初からやり直す
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// save は状態ファイルを書き込みます。途中で中断しても壊れないよう、一時ファイルから名前を変えます
+func (s *uploadState) save(statePath string) error {
+	if err := os.MkdirAll(filepath.Dir(statePath), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 同じファイルを同時にアップロードしている他の s3-cli と一時ファイルを取り合わないよう、名前は毎回変える
+	f, err := os.CreateTemp(filepath.Dir(statePath), "upload-*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), statePath)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// partSizeFor はパート数が上限を超えないようにパートサイズを決めます
+func partSizeFor(size, partSize int64) int64 {
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+	if min := (size + maxParts - 1) / maxParts; partSize < min {
+		partSize = min
+	}
+	return partSize
+}
+
+// uploadMultipart はファイルをマルチパートでアップロードします。
+// アップロードIDと完了したパートを状態ファイルに記録し、中断された場合は次回そこから再開します。
+func (c *S3Client) uploadMultipart(ctx context.Context, file *os.File, info os.FileInfo, bucketName, key string, opts UploadOptions) error {
+	stateDir := opts.StateDir
+	if stateDir == "" {
+		dir, err := DefaultUploadStateDir()
+		if err != nil {
+			return err
+		}
+		stateDir = dir
+	}
+	absPath, err := filepath.Abs(file.Name())
+	if err != nil {
+		return err
+	}
+	statePath := uploadStatePath(stateDir, absPath, bucketName, key)
+
+	sse, kmsKeyID, bucketKey, customer, err := c.uploadHeaders(opts.Encryption)
+	if err != nil {
+		return err
+	}
+	keyMD5 := aws.ToString(customer.keyMD5)
+
+	state, err := c.resumableUpload(ctx, statePath, absPath, info, bucketName, key, opts.Encryption, customer)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		out, err := c.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:               &bucketName,
+			Key:                  &key,
+			ChecksumAlgorithm:    types.ChecksumAlgorithmSha256,
+			ServerSideEncryption: sse,
+			SSEKMSKeyId:          kmsKeyID,
+			BucketKeyEnabled:     bucketKey,
+			SSECustomerAlgorithm: customer.algorithm,
+			SSECustomerKey:       customer.key,
+			SSECustomerKeyMD5:    customer.keyMD5,
+		})
+		if err != nil {
+			return err
+		}
+		state = &uploadState{
+			Path:              absPath,
+			Bucket:            bucketName,
+			Key:               key,
+			UploadID:          aws.ToString(out.UploadId),
+			Size:              info.Size(),
+			ModTime:           info.ModTime(),
+			PartSize:          partSizeFor(info.Size(), opts.PartSize),
+			ChecksumAlgorithm: AlgorithmSHA256,
+			Encryption:        opts.Encryption,
+			CustomerKeyMD5:    keyMD5,
+		}
+		if err := state.save(statePath); err != nil {
+			return err
+		}
+		c.logger.Info("multipart upload started", "bucket", bucketName, "key", key, "upload_id", state.UploadID)
+	} else {
+		c.logger.Info("resuming multipart upload", "bucket", bucketName, "key", key, "upload_id", state.UploadID, "parts", len(state.Parts))
+	}
+
+	size := info.Size()
+	done := map[int32]bool{}
+	var uploaded int64
+	for _, p := range state.Parts {
+		done[p.Number] = true
+		uploaded += min(state.PartSize, size-int64(p.Number-1)*state.PartSize)
+	}
+	if opts.Progress != nil {
+		opts.Progress(uploaded, size)
+	}
+
+	partCount := int32((size + state.PartSize - 1) / state.PartSize)
+	for number := int32(1); number <= partCount; number++ {
+		if done[number] {
+			continue
+		}
+		offset := int64(number-1) * state.PartSize
+		length := min(state.PartSize, size-offset)
+
+		var progress func(done, total int64)
+		if opts.Progress != nil {
+			base := uploaded
+			progress = func(done, total int64) { opts.Progress(base+done, size) }
+		}
+		checksum, err := sha256Base64(io.NewSectionReader(file, offset, length))
+		if err != nil {
+			return err
+		}
+		body := newProgressReader(c.bandwidth.Reader(ctx, io.NewSectionReader(file, offset, length)), length, progress)
+		out, err := c.client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:         &bucketName,
+			Key:            &key,
+			UploadId:       &state.UploadID,
+			PartNumber:     number,
+			Body:           body,
+			ContentLength:  length,
+			ChecksumSHA256: aws.String(checksum),
+
+			SSECustomerAlgorithm: customer.algorithm,
+			SSECustomerKey:       customer.key,
+			SSECustomerKeyMD5:    customer.keyMD5,
+		}, s3.WithAPIOptions(v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware))
+		if err != nil {
+			// 状態ファイルは残し、次回はこのパートから再開する
+			return err
+		}
+		state.Parts = append(state.Parts, uploadedPart{Number: number, ETag: aws.ToString(out.ETag), ChecksumSHA256: checksum})
+		if err := state.save(statePath); err != nil {
+			return err
+		}
+		uploaded += length
+	}
+
+	sort.Slice(state.Parts, func(i, j int) bool { return state.Parts[i].Number < state.Parts[j].Number })
+	completed := make([]types.CompletedPart, len(state.Parts))
+	for i, p := range state.Parts {
+		completed[i] = types.CompletedPart{PartNumber: p.Number, ETag: aws.String(p.ETag), ChecksumSHA256: aws.String(p.ChecksumSHA256)}
+	}
+	if _, err := c.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          &bucketName,
+		Key:             &key,
+		UploadId:        &state.UploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+
+		SSECustomerAlgorithm: customer.algorithm,
+		SSECustomerKey:       customer.key,
+		SSECustomerKeyMD5:    customer.keyMD5,
+	}); err != nil {
+		return err
+	}
+	return os.Remove(statePath)
+}
+
+// resumableUpload は状態ファイルから再開できるアップロードを返します。再開できない場合は nil を返します。
+// ファイルや暗号化の設定が変更されていた場合は、以前のアップロードを中止します。
+func (c *S3Client) resumableUpload(ctx context.Context, statePath, path string, info os.FileInfo, bucketName, key string, encryption Encryption, customer sseCustomer) (*uploadState, error) {
+	state, err := loadUploadState(statePath)
+	if err != nil || state == nil {
+		return nil, err
+	}
+	if state.Path != path || state.Bucket != bucketName || state.Key != key ||
+		state.Size != info.Size() || !state.ModTime.Equal(info.ModTime()) || state.PartSize <= 0 ||
+		state.ChecksumAlgorithm != AlgorithmSHA256 ||
+		state.Encryption != encryption || state.CustomerKeyMD5 != aws.ToString(customer.keyMD5) {
+		c.logger.Info("local file or encryption changed, discarding multipart upload", "bucket", bucketName, "key", key, "upload_id", state.UploadID)
+		if err := c.AbortMultipartUpload(ctx, bucketName, key, state.UploadID); err != nil && !isAPIError(err, "NoSuchUpload") {
+			return nil, err
+		}
+		return nil, os.Remove(statePath)
+	}
+
+	// サーバー側にも残っているパートだけを使う
+	listed, err := c.listParts(ctx, bucketName, key, state.UploadID, customer)
+	if isAPIError(err, "NoSuchUpload") {
+		c.logger.Info("multipart upload no longer exists, restarting", "bucket", bucketName, "key", key, "upload_id", state.UploadID)
+		return nil, os.Remove(statePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	serverParts := make(map[int32]string, len(listed))
+	for _, p := range listed {
+		serverParts[p.PartNumber] = aws.ToString(p.ETag)
+	}
+	parts := state.Parts[:0]
+	for _, p := range state.Parts {
+		if serverParts[p.Number] == p.ETag {
+			parts = append(parts, p)
+		}
+	}
+	state.Parts = parts
+	return state, nil
+}
+
+// listParts はアップロード済みのパートを返します。SSE-C のアップロードでは鍵が必要です
+func (c *S3Client) listParts(ctx context.Context, bucketName, key, uploadID string, customer sseCustomer) ([]types.Part, error) {
+	var parts []types.Part
+	input := &s3.ListPartsInput{
+		Bucket:               &bucketName,
+		Key:                  &key,
+		UploadId:             &uploadID,
+		SSECustomerAlgorithm: customer.algorithm,
+		SSECustomerKey:       customer.key,
+		SSECustomerKeyMD5:    customer.keyMD5,
+	}
+	for {
+		out, err := c.client.ListParts(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, out.Parts...)
+		if !out.IsTruncated {
+			return parts, nil
+		}
+		input.PartNumberMarker = out.NextPartNumberMarker
+	}
+}
+
+// ListMultipartUploads はバケット内の完了していないマルチパートアップロードを、開始日時の古い順に返します
+func (c *S3Client) ListMultipartUploads(ctx context.Context, bucketName string) ([]MultipartUpload, error) {
+	var uploads []MultipartUpload
+	input := &s3.ListMultipartUploadsInput{Bucket: &bucketName}
+	for {
+		out, err := c.client.ListMultipartUploads(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range out.Uploads {
+			uploads = append(uploads, MultipartUpload{
+				Key:       aws.ToString(u.Key),
+				UploadID:  aws.ToString(u.UploadId),
+				Initiated: aws.ToTime(u.Initiated),
+			})
+		}
+		if !out.IsTruncated {
+			break
+		}
+		input.KeyMarker, input.UploadIdMarker = out.NextKeyMarker, out.NextUploadIdMarker
+	}
+	sort.SliceStable(uploads, func(i, j int) bool { return uploads[i].Initiated.Before(uploads[j].Initiated) })
+	return uploads, nil
+}
+
+// CountParts はアップロード済みのパートの数と合計サイズを upload に設定します
+func (c *S3Client) CountParts(ctx context.Context, bucketName string, upload *MultipartUpload) error {
+	parts, err := c.listParts(ctx, bucketName, upload.Key, upload.UploadID, sseCustomer{})
+	if err != nil {
+		return err
+	}
+	upload.Parts, upload.Size = len(parts), 0
+	for _, p := range parts {
+		upload.Size += p.Size
+	}
+	return nil
+}
+
+// AbortMultipartUpload はマルチパートアップロードを中止し、アップロード済みのパートを削除します
+func (c *S3Client) AbortMultipartUpload(ctx context.Context, bucketName, key, uploadID string) error {
+	_, err := c.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   &bucketName,
+		Key:      &key,
+		UploadId: &uploadID,
+	})
+	return err
+}
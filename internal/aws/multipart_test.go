@@ -0,0 +1,174 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// TestResumeMultipartUpload は中断したマルチパートアップロードが、アップロード済みのパートを除いて再開されることをテストします
+func TestResumeMultipartUpload(t *testing.T) {
+	data := bytes.Repeat([]byte("abcdefghij"), 1000)
+	path := filepath.Join(t.TempDir(), "upload.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeS3("bucket")
+	client := newFakeClient(fake)
+	opts := UploadOptions{PartSize: 3000, StateDir: t.TempDir()}
+
+	// 3番目のパートで中断される
+	fake.failPart = 3
+	if err := client.UploadObject(context.Background(), path, "bucket", "upload.bin", opts); !errors.Is(err, errFakeInterrupted) {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", errFakeInterrupted, err)
+	}
+	if _, ok := fake.get("bucket", "upload.bin"); ok {
+		t.Fatal("中断したアップロードが完了しています")
+	}
+	states, _ := filepath.Glob(filepath.Join(opts.StateDir, "*.json"))
+	if len(states) != 1 {
+		t.Fatalf("状態ファイルの数: 期待結果 %d, 実際の結果 %d", 1, len(states))
+	}
+
+	var lastDone int64
+	opts.Progress = func(done, total int64) { lastDone = done }
+	if err := client.UploadObject(context.Background(), path, "bucket", "upload.bin", opts); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expected := []int32{1, 2, 3, 4}
+	if !reflect.DeepEqual(fake.uploadedParts, expected) {
+		t.Errorf("アップロードされたパート: 期待結果 %v, 実際の結果 %v", expected, fake.uploadedParts)
+	}
+	if lastDone != int64(len(data)) {
+		t.Errorf("進捗: 期待結果 %d, 実際の結果 %d", len(data), lastDone)
+	}
+	got, _ := fake.get("bucket", "upload.bin")
+	if !bytes.Equal(got, data) {
+		t.Error("アップロードした内容が一致しません")
+	}
+	if states, _ := filepath.Glob(filepath.Join(opts.StateDir, "*.json")); len(states) != 0 {
+		t.Errorf("完了後に状態ファイルが残っています: %v", states)
+	}
+	if len(fake.uploads) != 0 {
+		t.Errorf("完了していないアップロードが残っています: %d", len(fake.uploads))
+	}
+}
+
+// TestResumeMultipartUploadChangedFile はファイルが変更された場合に、以前のアップロードを中止して最初からやり直すことをテストします
+func TestResumeMultipartUploadChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload.bin")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("a"), 5000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeS3("bucket")
+	client := newFakeClient(fake)
+	opts := UploadOptions{PartSize: 2000, StateDir: t.TempDir()}
+
+	fake.failPart = 2
+	if err := client.UploadObject(context.Background(), path, "bucket", "upload.bin", opts); err == nil {
+		t.Fatal("中断したアップロードでエラーが返されませんでした")
+	}
+
+	updated := bytes.Repeat([]byte("b"), 4500)
+	if err := os.WriteFile(path, updated, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.UploadObject(context.Background(), path, "bucket", "upload.bin", opts); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	got, _ := fake.get("bucket", "upload.bin")
+	if !bytes.Equal(got, updated) {
+		t.Error("アップロードした内容が一致しません")
+	}
+	if len(fake.uploads) != 0 {
+		t.Errorf("以前のアップロードが中止されていません: %d", len(fake.uploads))
+	}
+}
+
+// TestListAndAbortMultipartUploads は完了していないアップロードの一覧と中止をテストします
+func TestListAndAbortMultipartUploads(t *testing.T) {
+	fake := newFakeS3("bucket", "other")
+	client := newFakeClient(fake)
+	ctx := context.Background()
+	for _, target := range [][2]string{{"bucket", "b.bin"}, {"bucket", "a.bin"}, {"other", "c.bin"}} {
+		input := &s3.CreateMultipartUploadInput{Bucket: aws.String(target[0]), Key: aws.String(target[1])}
+		if _, err := fake.CreateMultipartUpload(ctx, input); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	uploads, err := client.ListMultipartUploads(ctx, "bucket")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	// 開始日時の古い順に並ぶ
+	var keys []string
+	for _, u := range uploads {
+		keys = append(keys, u.Key)
+	}
+	if expected := []string{"b.bin", "a.bin"}; !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", expected, keys)
+	}
+	if !uploads[0].Initiated.Before(uploads[1].Initiated) || uploads[0].Initiated.IsZero() {
+		t.Errorf("開始日時が正しくありません: %v, %v", uploads[0].Initiated, uploads[1].Initiated)
+	}
+
+	if _, err := fake.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String("bucket"),
+		Key:        aws.String("a.bin"),
+		UploadId:   aws.String(uploads[1].UploadID),
+		PartNumber: 1,
+		Body:       bytes.NewReader(make([]byte, 1500)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CountParts(ctx, "bucket", &uploads[1]); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if uploads[1].Parts != 1 || uploads[1].Size != 1500 {
+		t.Errorf("パート: 期待結果 %d件 %dバイト, 実際の結果 %d件 %dバイト", 1, 1500, uploads[1].Parts, uploads[1].Size)
+	}
+
+	if err := client.AbortMultipartUpload(ctx, "bucket", uploads[0].Key, uploads[0].UploadID); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	uploads, err = client.ListMultipartUploads(ctx, "bucket")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if len(uploads) != 1 || uploads[0].Key != "a.bin" {
+		t.Errorf("中止後の一覧が正しくありません: %+v", uploads)
+	}
+	if err := client.AbortMultipartUpload(ctx, "bucket", "b.bin", "missing"); !isAPIError(err, "NoSuchUpload") {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "NoSuchUpload", err)
+	}
+}
+
+// TestPartSizeFor はパート数が上限を超えないパートサイズをテストします
+func TestPartSizeFor(t *testing.T) {
+	testCases := []struct {
+		name     string
+		size     int64
+		partSize int64
+		expected int64
+	}{
+		{name: "既定のパートサイズ", size: 100 << 20, partSize: 0, expected: DefaultPartSize},
+		{name: "指定したパートサイズ", size: 100 << 20, partSize: 5 << 20, expected: 5 << 20},
+		{name: "パート数の上限を超える", size: 100000 << 20, partSize: 5 << 20, expected: 10 << 20},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := partSizeFor(tc.size, tc.partSize); got != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// objectBody は帯域制限したオブジェクトの本文です。Close で元の本文を閉じます
+type objectBody struct {
+	io.Reader
+	io.Closer
+}
+
+// OpenObject はオブジェクトの本文を読み出せるようにします。外部のプログラムに渡すためのもので、
+// ファイルには保存しません。読み終わったら Close してください。
+func (c *S3Client) OpenObject(ctx context.Context, bucketName, key string) (io.ReadCloser, error) {
+	var resp *s3.GetObjectOutput
+	err := c.withCustomerKey(bucketName, key, false, func(sse sseCustomer) error {
+		var err error
+		resp, err = c.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket:               &bucketName,
+			Key:                  &key,
+			SSECustomerAlgorithm: sse.algorithm,
+			SSECustomerKey:       sse.key,
+			SSECustomerKeyMD5:    sse.keyMD5,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug("object opened", "bucket", bucketName, "key", key, "size", resp.ContentLength)
+	return objectBody{Reader: c.bandwidth.Reader(ctx, resp.Body), Closer: resp.Body}, nil
+}
+
+// DownloadTemp はオブジェクトを新しい一時ディレクトリにキーと同じ名前で保存し、そのパスを返します。
+// 使い終わったら filepath.Dir(path) ごと削除してください。
+func (c *S3Client) DownloadTemp(ctx context.Context, bucketName, key string) (string, error) {
+	body, err := c.OpenObject(ctx, bucketName, key)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	dir, err := os.MkdirTemp("", "s3-cli-open-*")
+	if err != nil {
+		return "", err
+	}
+	outputPath := filepath.Join(dir, tempFileName(key))
+	file, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err == nil {
+		_, err = io.Copy(file, body)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return outputPath, nil
+}
+
+// tempFileName は一時ファイルの名前を返します。拡張子でプログラムが形式を判別できるよう、キーの最後の要素を使います
+func tempFileName(key string) string {
+	name := path.Base(key)
+	if name == "." || name == "/" {
+		return "object"
+	}
+	return name
+}
@@ -0,0 +1,46 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestOpenObject はオブジェクトの本文の読み出しと、一時ファイルへの保存をテストします
+func TestOpenObject(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeS3("bucket")
+	fake.put("bucket", "data/report.csv", []byte("a,b\n1,2\n"))
+	client := newFakeClient(fake)
+
+	body, err := client.OpenObject(ctx, "bucket", "data/report.csv")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if string(data) != "a,b\n1,2\n" {
+		t.Errorf("期待結果 %q, 実際の結果 %q", "a,b\n1,2\n", data)
+	}
+
+	path, err := client.DownloadTemp(ctx, "bucket", "data/report.csv")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	defer os.RemoveAll(filepath.Dir(path))
+	if got := filepath.Base(path); got != "report.csv" {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "report.csv", got)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "a,b\n1,2\n" {
+		t.Errorf("期待結果 %q, 実際の結果 %q", "a,b\n1,2\n", data)
+	}
+
+	if _, err := client.DownloadTemp(ctx, "bucket", "missing.csv"); err == nil {
+		t.Error("存在しないオブジェクトでエラーが返されませんでした")
+	}
+}
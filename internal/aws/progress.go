@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"io"
+)
+
+// progressReader は読み出したバイト数を通知する io.Reader です
+type progressReader struct {
+	r        io.Reader
+	done     int64
+	total    int64
+	progress func(done, total int64)
+}
+
+// newProgressReader は読み出しのたびに progress を呼び出す io.Reader を返します。
+// progress が nil の場合は r をそのまま返します。r が io.Seeker の場合はSeekも引き継ぎます。
+func newProgressReader(r io.Reader, total int64, progress func(done, total int64)) io.Reader {
+	if progress == nil {
+		return r
+	}
+	pr := &progressReader{r: r, total: total, progress: progress}
+	if seeker, ok := r.(io.Seeker); ok {
+		return &progressReadSeeker{progressReader: pr, seeker: seeker}
+	}
+	return pr
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.done += int64(n)
+		r.progress(r.done, r.total)
+	}
+	return n, err
+}
+
+// progressReadSeeker は io.Seeker を引き継いだ progressReader です。
+// 再試行で先頭に戻った場合は、進捗も戻します。
+type progressReadSeeker struct {
+	*progressReader
+	seeker io.Seeker
+}
+
+func (r *progressReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	pos, err := r.seeker.Seek(offset, whence)
+	if err == nil {
+		r.done = pos
+	}
+	return pos, err
+}
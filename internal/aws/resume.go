@@ -0,0 +1,87 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/smithy-go"
+)
+
+// partialSuffix はダウンロード中のファイルに付ける拡張子です。
+// 完了するまでは "<保存先>.part" に書き込み、完了後に保存先へ名前を変えます。
+const partialSuffix = ".part"
+
+// partialMetaSuffix は途中までダウンロードしたファイルの情報を保存するファイルの拡張子です
+const partialMetaSuffix = ".part.json"
+
+// IsPartialDownload は中断したダウンロードが残したファイル（.part、.part.json）かを返します
+func IsPartialDownload(path string) bool {
+	return strings.HasSuffix(path, partialSuffix) || strings.HasSuffix(path, partialMetaSuffix)
+}
+
+// partialMeta は途中までダウンロードしたファイルが、どのオブジェクトのどの版のものかを表します。
+// 範囲指定のGETではオブジェクト全体のチェックサムが返らないため、最初の応答の値を保存しておきます。
+type partialMeta struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	objectChecksums
+	// PartSize はマルチパートのETagを検証するためのパートサイズです
+	PartSize int64 `json:"part_size,omitempty"`
+}
+
+// resumeOffset は outputPath の途中までのダウンロードを再開できる場合に、
+// その長さと保存した情報を返します。再開できない場合は0を返します。
+func resumeOffset(outputPath, bucket, key string) (int64, partialMeta) {
+	data, err := os.ReadFile(outputPath + partialMetaSuffix)
+	if err != nil {
+		return 0, partialMeta{}
+	}
+	var meta partialMeta
+	if err := json.Unmarshal(data, &meta); err != nil || meta.Bucket != bucket || meta.Key != key || meta.ETag == "" {
+		return 0, partialMeta{}
+	}
+	info, err := os.Stat(outputPath + partialSuffix)
+	if err != nil || info.Size() == 0 || info.Size() >= meta.Size {
+		// 全体を書き終えている場合も、範囲指定のGETはできないため最初からやり直す
+		return 0, partialMeta{}
+	}
+	return info.Size(), meta
+}
+
+// writePartialMeta は途中までのダウンロードを再開するための情報を保存します
+func writePartialMeta(outputPath string, meta partialMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath+partialMetaSuffix, data, 0644)
+}
+
+// removePartial は途中までダウンロードしたファイルとその情報を削除します
+func removePartial(outputPath string) {
+	os.Remove(outputPath + partialSuffix)
+	os.Remove(outputPath + partialMetaSuffix)
+}
+
+// isAPIError は err が指定したコードのS3のエラーかを返します
+func isAPIError(err error, codes ...string) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range codes {
+		if apiErr.ErrorCode() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeFrom は offset 以降を取得する Range ヘッダーの値を返します
+func rangeFrom(offset int64) string {
+	return fmt.Sprintf("bytes=%d-", offset)
+}
@@ -0,0 +1,108 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestResumeDownload は中断したダウンロードが続きから再開されることをテストします
+func TestResumeDownload(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	fake := newFakeS3("bucket")
+	fake.put("bucket", "dir/file.bin", data)
+	client := newFakeClient(fake)
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "dir", "file.bin")
+
+	// 最初のダウンロードは途中で切断される
+	fake.failBodyAfter = 4000
+	if _, err := client.DownloadObject(context.Background(), "bucket", "dir/file.bin", DownloadOptions{OutputDir: dir}); !errors.Is(err, errFakeInterrupted) {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", errFakeInterrupted, err)
+	}
+	if _, err := os.Stat(outputPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("中断したダウンロードが保存先に書き込まれています: %v", err)
+	}
+	if info, err := os.Stat(outputPath + partialSuffix); err != nil || info.Size() != 4000 {
+		t.Fatalf("途中までのファイルが残っていません: %v", err)
+	}
+
+	var lastDone, lastTotal int64
+	path, err := client.DownloadObject(context.Background(), "bucket", "dir/file.bin", DownloadOptions{
+		OutputDir: dir,
+		Progress:  func(done, total int64) { lastDone, lastTotal = done, total },
+	})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if path != outputPath {
+		t.Errorf("期待結果 %v, 実際の結果 %v", outputPath, path)
+	}
+	if len(fake.ranges) != 1 || fake.ranges[0] != "bytes=4000-" {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []string{"bytes=4000-"}, fake.ranges)
+	}
+	if lastDone != int64(len(data)) || lastTotal != int64(len(data)) {
+		t.Errorf("進捗: 期待結果 %d/%d, 実際の結果 %d/%d", len(data), len(data), lastDone, lastTotal)
+	}
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("ダウンロードした内容が一致しません")
+	}
+	for _, suffix := range []string{partialSuffix, partialMetaSuffix} {
+		if _, err := os.Stat(outputPath + suffix); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s が削除されていません: %v", suffix, err)
+		}
+	}
+}
+
+func TestIsPartialDownload(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "out/a.log.part", expected: true},
+		{path: "out/a.log.part.json", expected: true},
+		{path: "out/a.log", expected: false},
+		{path: "out/config.json", expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			if got := IsPartialDownload(tc.path); got != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestResumeDownloadChangedObject は途中までのファイルの後にオブジェクトが更新された場合に最初からやり直すことをテストします
+func TestResumeDownloadChangedObject(t *testing.T) {
+	fake := newFakeS3("bucket")
+	fake.put("bucket", "file.txt", []byte("old contents of the object"))
+	client := newFakeClient(fake)
+	dir := t.TempDir()
+
+	fake.failBodyAfter = 5
+	if _, err := client.DownloadObject(context.Background(), "bucket", "file.txt", DownloadOptions{OutputDir: dir}); err == nil {
+		t.Fatal("中断したダウンロードでエラーが返されませんでした")
+	}
+
+	updated := []byte("new contents")
+	fake.put("bucket", "file.txt", updated)
+	path, err := client.DownloadObject(context.Background(), "bucket", "file.txt", DownloadOptions{OutputDir: dir})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if !bytes.Equal(got, updated) {
+		t.Errorf("期待結果 %q, 実際の結果 %q", updated, got)
+	}
+}
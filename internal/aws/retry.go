@@ -0,0 +1,215 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"golang.org/x/time/rate"
+)
+
+// RetryMode はリトライの方式です
+type RetryMode string
+
+const (
+	// RetryStandard は指数バックオフで再試行します（既定）
+	RetryStandard RetryMode = "standard"
+	// RetryAdaptive はスロットリングを検知すると送信速度も落とします
+	RetryAdaptive RetryMode = "adaptive"
+)
+
+// ParseRetryMode は文字列をRetryModeに変換します。空文字は RetryStandard になります
+func ParseRetryMode(s string) (RetryMode, error) {
+	switch m := RetryMode(s); m {
+	case "":
+		return RetryStandard, nil
+	case RetryStandard, RetryAdaptive:
+		return m, nil
+	}
+	return "", i18n.Errorf("aws.unknown_retry_mode", s)
+}
+
+// RetryEvent はリクエストを再試行する直前の状態です
+type RetryEvent struct {
+	// Attempt はこれから行う試行が何回目かです
+	Attempt     int
+	MaxAttempts int
+	// Delay は再試行までの待ち時間です
+	Delay time.Duration
+	// Throttled はスロットリング（SlowDown や 503）による再試行かどうかです
+	Throttled bool
+	// Reason は失敗の理由です（"503 SlowDown" など）
+	Reason string
+}
+
+// newRetryer はリトライの方式と回数から Retryer を作成します。
+// maxRetries が負の場合はSDKの既定の回数を使います。
+func newRetryer(mode RetryMode, maxRetries int, onRetry func(RetryEvent)) func() aws.Retryer {
+	return func() aws.Retryer {
+		standard := func(o *retry.StandardOptions) {
+			if maxRetries >= 0 {
+				o.MaxAttempts = maxRetries + 1
+			}
+		}
+
+		var retryer aws.RetryerV2
+		if mode == RetryAdaptive {
+			retryer = retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+				o.StandardOptions = append(o.StandardOptions, standard)
+			})
+		} else {
+			retryer = retry.NewStandard(standard)
+		}
+		if onRetry == nil {
+			return retryer
+		}
+		return observedRetryer{RetryerV2: retryer, onRetry: onRetry}
+	}
+}
+
+// observedRetryer は再試行のたびに onRetry を呼び出す Retryer です
+type observedRetryer struct {
+	aws.RetryerV2
+	onRetry func(RetryEvent)
+}
+
+// RetryDelay は再試行の待ち時間を返します。SDKは待ち時間を決めた直後に待機するため、ここで通知します
+func (r observedRetryer) RetryDelay(attempt int, err error) (time.Duration, error) {
+	delay, delayErr := r.RetryerV2.RetryDelay(attempt, err)
+	if delayErr == nil {
+		throttled, reason := describeRetryError(err)
+		r.onRetry(RetryEvent{
+			Attempt:     attempt + 1,
+			MaxAttempts: r.MaxAttempts(),
+			Delay:       delay,
+			Throttled:   throttled,
+			Reason:      reason,
+		})
+	}
+	return delay, delayErr
+}
+
+// describeRetryError は再試行の原因がスロットリングかどうかと、表示用の理由を返します
+func describeRetryError(err error) (bool, string) {
+	status := 0
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		status = respErr.HTTPStatusCode()
+	}
+
+	code := ""
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code = apiErr.ErrorCode()
+	}
+
+	throttled := status == http.StatusServiceUnavailable || status == http.StatusTooManyRequests ||
+		retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err).Bool()
+
+	switch {
+	case status != 0 && code != "":
+		return throttled, fmt.Sprintf("%d %s", status, code)
+	case status != 0:
+		return throttled, fmt.Sprintf("%d %s", status, http.StatusText(status))
+	case code != "":
+		return throttled, code
+	}
+	return throttled, err.Error()
+}
+
+// rateLimitMiddleware は送信するリクエスト（再試行を含む）を1秒あたりrps件までに制限するミドルウェアを返します。
+// 1つのクライアントのすべての呼び出しで同じリミッターを共有します。
+func rateLimitMiddleware(rps float64) func(*middleware.Stack) error {
+	limiter := rate.NewLimiter(rate.Limit(rps), 1)
+	return func(stack *middleware.Stack) error {
+		return stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc("S3CLIRateLimit",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				if err := limiter.Wait(ctx); err != nil {
+					return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+				}
+				return next.HandleFinalize(ctx, in)
+			}), "Retry", middleware.After)
+	}
+}
+
+// streamingOperations はレスポンスの本文を呼び出し後に読み出す操作です。
+// 呼び出しから戻った時点で期限を切ると本文を読めなくなるため、呼び出し単位の制限時間を適用しません。
+var streamingOperations = map[string]bool{
+	"GetObject": true,
+}
+
+// TimeoutOperations は操作の種類ごとの制限時間（ClientOptions.OperationTimeouts）に指定できる種類です
+var TimeoutOperations = []string{"list", "head", "put", "copy", "delete"}
+
+// operationKinds はAPIの操作名から操作の種類への対応です。ここにない操作は ClientOptions.Timeout を使います
+var operationKinds = map[string]string{
+	"ListBuckets":             "list",
+	"ListObjectsV2":           "list",
+	"ListObjectVersions":      "list",
+	"ListMultipartUploads":    "list",
+	"ListParts":               "list",
+	"HeadBucket":              "head",
+	"HeadObject":              "head",
+	"PutObject":               "put",
+	"CreateMultipartUpload":   "put",
+	"UploadPart":              "put",
+	"CompleteMultipartUpload": "put",
+	"CopyObject":              "copy",
+	"UploadPartCopy":          "copy",
+	"DeleteObject":            "delete",
+	"DeleteObjects":           "delete",
+	"DeleteBucket":            "delete",
+	"AbortMultipartUpload":    "delete",
+}
+
+// DefaultOperationTimeouts は操作の種類ごとの既定の制限時間です。ここにない種類は ClientOptions.Timeout を使います。
+// アップロードとコピーはオブジェクトの大きさに応じて時間がかかるため、長めにしています。
+var DefaultOperationTimeouts = map[string]time.Duration{
+	"put":  10 * time.Minute,
+	"copy": 10 * time.Minute,
+}
+
+// operationTimeout はAPIの操作 op の制限時間を返します（0の場合は無制限）。
+// 操作の種類ごとの指定、既定の制限時間、timeout の順に使います。
+func operationTimeout(op string, timeout time.Duration, overrides map[string]time.Duration) time.Duration {
+	kind, ok := operationKinds[op]
+	if !ok {
+		return timeout
+	}
+	if d, ok := overrides[kind]; ok {
+		return d
+	}
+	if d, ok := DefaultOperationTimeouts[kind]; ok {
+		return d
+	}
+	return timeout
+}
+
+// timeoutMiddleware はAPI呼び出し1回（再試行を含む）に、操作ごとの制限時間を設けるミドルウェアを返します
+func timeoutMiddleware(timeout time.Duration, overrides map[string]time.Duration) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("S3CLITimeout",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				op := awsmiddleware.GetOperationName(ctx)
+				if streamingOperations[op] {
+					return next.HandleInitialize(ctx, in)
+				}
+				d := operationTimeout(op, timeout, overrides)
+				if d <= 0 {
+					return next.HandleInitialize(ctx, in)
+				}
+				ctx, cancel := context.WithTimeout(ctx, d)
+				defer cancel()
+				return next.HandleInitialize(ctx, in)
+			}), middleware.After)
+	}
+}
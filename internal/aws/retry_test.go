@@ -0,0 +1,204 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestClient はテスト用のHTTPサーバーに接続するS3Clientを作成します
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ClientOptions) *S3Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+
+	opts.EndpointURL = server.URL
+	client, err := NewS3Client(opts)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	return client
+}
+
+const listBucketsResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<ListAllMyBucketsResult><Buckets><Bucket><Name>example</Name></Bucket></Buckets></ListAllMyBucketsResult>`
+
+// TestRetryEvents は SlowDown による再試行がスロットリングとして通知されることをテストします
+func TestRetryEvents(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>`))
+			return
+		}
+		w.Write([]byte(listBucketsResponse))
+	}
+
+	var events []RetryEvent
+	client := newTestClient(t, handler, ClientOptions{
+		MaxRetries: 2,
+		OnRetry: func(e RetryEvent) {
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		},
+	})
+
+	buckets, err := client.ListBuckets(context.Background())
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if len(buckets) != 1 {
+		t.Errorf("バケット数: 期待結果 %v, 実際の結果 %v", 1, len(buckets))
+	}
+	if len(events) != 1 {
+		t.Fatalf("再試行の通知回数: 期待結果 %v, 実際の結果 %v", 1, len(events))
+	}
+	e := events[0]
+	if !e.Throttled || e.Reason != "503 SlowDown" || e.Attempt != 2 || e.MaxAttempts != 3 {
+		t.Errorf("再試行の通知が期待と異なります: %+v", e)
+	}
+}
+
+// TestMaxRetries はリトライ回数の上限で諦めることをテストします
+func TestMaxRetries(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	client := newTestClient(t, handler, ClientOptions{MaxRetries: 0})
+	if _, err := client.ListBuckets(context.Background()); err == nil {
+		t.Fatal("エラーが返されませんでした")
+	}
+	if requests != 1 {
+		t.Errorf("リクエスト数: 期待結果 %v, 実際の結果 %v", 1, requests)
+	}
+}
+
+// TestRateLimit は1秒あたりのリクエスト数が上限を超えないことをテストします
+func TestRateLimit(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(listBucketsResponse))
+	}
+	client := newTestClient(t, handler, ClientOptions{MaxRetries: -1, MaxRPS: 20})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := client.ListBuckets(context.Background()); err != nil {
+			t.Fatalf("予期しないエラー: %v", err)
+		}
+	}
+	// 最初の1件はすぐに送られ、残りの4件は50msずつ待つ
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("5件のリクエストが %v で終わりました（200ms以上かかるはずです）", elapsed)
+	}
+}
+
+// TestTimeout は呼び出しの制限時間を超えるとエラーになることをテストします
+func TestTimeout(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}
+	client := newTestClient(t, handler, ClientOptions{MaxRetries: 0, Timeout: 100 * time.Millisecond})
+
+	start := time.Now()
+	if _, err := client.ListBuckets(context.Background()); err == nil {
+		t.Fatal("エラーが返されませんでした")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("制限時間を過ぎても %v 待ちました", elapsed)
+	}
+}
+
+func TestParseRetryMode(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected RetryMode
+		wantErr  bool
+	}{
+		{input: "", expected: RetryStandard},
+		{input: "standard", expected: RetryStandard},
+		{input: "adaptive", expected: RetryAdaptive},
+		{input: "fast", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := ParseRetryMode(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("エラーの有無が期待と異なります: %v", err)
+			}
+			if actual != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestOperationTimeout(t *testing.T) {
+	testCases := []struct {
+		name      string
+		op        string
+		overrides map[string]time.Duration
+		expected  time.Duration
+	}{
+		{name: "種類のない操作は全体の制限時間", op: "GetBucketPolicy", expected: 30 * time.Second},
+		{name: "既定のない種類は全体の制限時間", op: "ListObjectsV2", expected: 30 * time.Second},
+		{name: "アップロードの既定", op: "UploadPart", expected: 10 * time.Minute},
+		{name: "種類ごとの指定", op: "HeadObject", overrides: map[string]time.Duration{"head": 5 * time.Second}, expected: 5 * time.Second},
+		{name: "既定より指定を優先", op: "CopyObject", overrides: map[string]time.Duration{"copy": time.Hour}, expected: time.Hour},
+		{name: "0は無制限", op: "PutObject", overrides: map[string]time.Duration{"put": 0}, expected: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := operationTimeout(tc.op, 30*time.Second, tc.overrides); got != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestOperationTimeoutOverride は操作の種類ごとの制限時間が全体の制限時間より優先されることをテストします
+func TestOperationTimeoutOverride(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}
+	client := newTestClient(t, handler, ClientOptions{
+		MaxRetries:        0,
+		OperationTimeouts: map[string]time.Duration{"list": 100 * time.Millisecond},
+	})
+
+	start := time.Now()
+	if _, err := client.ListBuckets(context.Background()); err == nil {
+		t.Fatal("エラーが返されませんでした")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("制限時間を過ぎても %v 待ちました", elapsed)
+	}
+}
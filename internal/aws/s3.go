@@ -2,26 +2,87 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/logging"
 )
 
 // S3Client provides an interface to AWS S3 operations
 type S3Client struct {
-	client      *s3.Client
+	client      s3API
 	region      string
 	profile     string
 	endpointURL string
+	logger      *slog.Logger
+	// transferTimeout はダウンロード1件の制限時間です（0の場合は無制限）
+	transferTimeout time.Duration
+	// bandwidth はすべての転送で共有する帯域制限です
+	bandwidth *BandwidthLimiter
+	// customerKey は SSE-C の鍵です（nilの場合は SSE-C のオブジェクトを読めません）
+	customerKey CustomerKey
+	// sseCObjects は SSE-C の鍵が必要だった "bucket/key" の集合です
+	sseCObjects sync.Map
+}
+
+// ClientOptions は S3Client の作成オプションです
+type ClientOptions struct {
+	Profile     string
+	EndpointURL string
+	// Logger はSDKのリクエストのトレースを記録するロガーです（nilの場合は記録しません）
+	Logger *slog.Logger
+	// MaxRetries はリトライの最大回数です（負の場合はSDKの既定値）
+	MaxRetries int
+	// RetryMode はリトライの方式です
+	RetryMode RetryMode
+	// Timeout は転送以外のAPI呼び出し1回（再試行を含む）の制限時間です（0の場合は無制限）
+	Timeout time.Duration
+	// OperationTimeouts は操作の種類（TimeoutOperations）ごとの制限時間で、
+	// DefaultOperationTimeouts と Timeout より優先します（0の場合は無制限）
+	OperationTimeouts map[string]time.Duration
+	// TransferTimeout はダウンロード1件の制限時間です（0の場合は無制限）
+	TransferTimeout time.Duration
+	// MaxRPS は1秒あたりに送信するリクエスト数の上限です（0の場合は無制限）
+	MaxRPS float64
+	// BandwidthLimit はすべての転送を合わせた1秒あたりのバイト数の上限です（0の場合は無制限）
+	BandwidthLimit int64
+	// CustomerKey は SSE-C で暗号化されたオブジェクトの読み書きに使う鍵です（nilの場合は使いません）
+	CustomerKey CustomerKey
+	// OnRetry はリクエストを再試行する直前に呼ばれます（別のゴルーチンから呼ばれることがあります）
+	OnRetry func(RetryEvent)
+}
+
+// ObjectInfo は ListObjectsV2 で返されるオブジェクトの属性です
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	StorageClass string
+	ETag         string
 }
 
 // NewS3Client creates a new S3 client using AWS configuration from ~/.aws/config
-func NewS3Client(profile string, endpointURL string) (*S3Client, error) {
+func NewS3Client(opts ClientOptions) (*S3Client, error) {
+	profile, endpointURL := opts.Profile, opts.EndpointURL
+	logger := opts.Logger
+	if logger == nil {
+		logger = logging.Disabled().Logger
+	}
+
 	var loadOptions []func(*config.LoadOptions) error
 
 	// プロファイルが指定されている場合は使用
@@ -39,10 +100,23 @@ func NewS3Client(profile string, endpointURL string) (*S3Client, error) {
 		}),
 	))
 
+	// SDKのリトライのログとリクエストごとのトレースをロガーに送る
+	apiOptions := []func(*middleware.Stack) error{traceMiddleware(logger), accessDeniedMiddleware}
+	apiOptions = append(apiOptions, timeoutMiddleware(opts.Timeout, opts.OperationTimeouts))
+	if opts.MaxRPS > 0 {
+		apiOptions = append(apiOptions, rateLimitMiddleware(opts.MaxRPS))
+	}
+	loadOptions = append(loadOptions,
+		config.WithLogger(sdkLogger{logger}),
+		config.WithClientLogMode(aws.LogRetries),
+		config.WithRetryer(newRetryer(opts.RetryMode, opts.MaxRetries, opts.OnRetry)),
+		config.WithAPIOptions(apiOptions),
+	)
+
 	// 設定を読み込む
 	cfg, err := config.LoadDefaultConfig(context.Background(), loadOptions...)
 	if err != nil {
-		return nil, fmt.Errorf("AWS設定の読み込みに失敗しました: %w", err)
+		return nil, i18n.Errorf("aws.load_config_failed", err)
 	}
 
 	// 設定から使用されているリージョンを取得
@@ -63,10 +137,14 @@ func NewS3Client(profile string, endpointURL string) (*S3Client, error) {
 
 	client := s3.NewFromConfig(cfg)
 	return &S3Client{
-		client:      client,
-		region:      region,
-		profile:     usedProfile,
-		endpointURL: endpointURL,
+		client:          client,
+		region:          region,
+		profile:         usedProfile,
+		endpointURL:     endpointURL,
+		logger:          logger,
+		transferTimeout: opts.TransferTimeout,
+		bandwidth:       NewBandwidthLimiter(opts.BandwidthLimit),
+		customerKey:     opts.CustomerKey,
 	}, nil
 }
 
@@ -85,6 +163,11 @@ func (c *S3Client) GetEndpointURL() string {
 	return c.endpointURL
 }
 
+// Bandwidth はすべての転送で共有する帯域制限を返します。実行中に制限を変更できます
+func (c *S3Client) Bandwidth() *BandwidthLimiter {
+	return c.bandwidth
+}
+
 // ListBuckets returns a list of all S3 buckets
 func (c *S3Client) ListBuckets(ctx context.Context) ([]string, error) {
 	result, err := c.client.ListBuckets(ctx, &s3.ListBucketsInput{})
@@ -100,52 +183,333 @@ func (c *S3Client) ListBuckets(ctx context.Context) ([]string, error) {
 	return bucketNames, nil
 }
 
-// ListObjects returns a list of objects in the specified bucket
-func (c *S3Client) ListObjects(ctx context.Context, bucketName string) ([]string, error) {
-	result, err := c.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
-		Bucket: &bucketName,
+// ListObjects returns a list of objects in the specified bucket whose keys start with prefix.
+// 1回の ListObjectsV2 で返るのは1000件までのため、すべてのページを取得します。
+func (c *S3Client) ListObjects(ctx context.Context, bucketName, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := c.WalkObjects(ctx, bucketName, prefix, func(page []ObjectInfo) error {
+		objects = append(objects, page...)
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
+	return objects, nil
+}
+
+// newObjectInfo はSDKのオブジェクト型をObjectInfoに変換します
+func newObjectInfo(object types.Object) ObjectInfo {
+	info := ObjectInfo{
+		Key:          aws.ToString(object.Key),
+		Size:         object.Size,
+		LastModified: aws.ToTime(object.LastModified),
+		StorageClass: string(object.StorageClass),
+		ETag:         aws.ToString(object.ETag),
+	}
+	if info.StorageClass == "" {
+		// StorageClassが省略された場合はSTANDARDとして扱う
+		info.StorageClass = string(types.ObjectStorageClassStandard)
+	}
+	return info
+}
+
+// ConflictPolicy はダウンロード先に同名ファイルが存在する場合の動作です
+type ConflictPolicy string
+
+const (
+	// ConflictError はエラーにします（既定）
+	ConflictError ConflictPolicy = "error"
+	// ConflictSkip はダウンロードせずにスキップします
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite は上書きします
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictRename は "name (1).ext" のような別名で保存します
+	ConflictRename ConflictPolicy = "rename"
+)
+
+// ErrSkipped は同名ファイルが存在するためダウンロードをスキップしたことを表します
+var ErrSkipped error = skippedError{}
+
+// skippedError はメッセージを表示時の言語で返すため、文言を保持しないエラー型です
+type skippedError struct{}
+
+func (skippedError) Error() string {
+	return i18n.T("aws.skipped")
+}
 
-	var objectKeys []string
-	for _, object := range result.Contents {
-		objectKeys = append(objectKeys, *object.Key)
+// ParseConflictPolicy は文字列をConflictPolicyに変換します。空文字は ConflictError になります
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(s); p {
+	case "":
+		return ConflictError, nil
+	case ConflictError, ConflictSkip, ConflictOverwrite, ConflictRename:
+		return p, nil
 	}
+	return "", i18n.Errorf("aws.unknown_conflict_policy", s)
+}
 
-	return objectKeys, nil
+// DownloadOptions はダウンロードのオプションです
+type DownloadOptions struct {
+	// OutputDir は保存先のディレクトリです。キーのパスはこの下に再現されます
+	OutputDir string
+	// ConflictPolicy は同名ファイルが存在する場合の動作です
+	ConflictPolicy ConflictPolicy
+	// Progress は書き込みのたびに、書き込んだバイト数と全体のバイト数で呼ばれます
+	Progress func(done, total int64)
 }
 
-// DownloadObject は指定したバケット・キーのオブジェクトをローカルにダウンロードします
-func (c *S3Client) DownloadObject(ctx context.Context, bucketName, key, outputDir string) error {
-	outputPath := filepath.Join(outputDir, key)
+// DownloadObject は指定したバケット・キーのオブジェクトをローカルにダウンロードし、保存先のパスを返します。
+// 中断されたダウンロードは "<保存先>.part" に残り、次回は同じ版（ETag）の場合に続きから再開します。
+func (c *S3Client) DownloadObject(ctx context.Context, bucketName, key string, opts DownloadOptions) (string, error) {
+	outputPath := filepath.Join(opts.OutputDir, key)
 
 	// 同名ファイルが既に存在するかチェック
 	if _, err := os.Stat(outputPath); err == nil {
-		return fmt.Errorf("ファイルが既に存在します: %s", outputPath)
+		switch opts.ConflictPolicy {
+		case ConflictSkip:
+			return outputPath, ErrSkipped
+		case ConflictOverwrite:
+		case ConflictRename:
+			outputPath = availablePath(outputPath)
+		default:
+			return "", i18n.Errorf("aws.file_exists", outputPath)
+		}
 	}
 
 	// ディレクトリが存在しない場合は作成
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return err
+		return "", err
 	}
 
-	outFile, err := os.Create(outputPath)
+	// 本文の読み出しまで含めて制限時間を設ける
+	if c.transferTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.transferTimeout)
+		defer cancel()
+	}
+
+	offset, meta := resumeOffset(outputPath, bucketName, key)
+	input := &s3.GetObjectInput{
+		Bucket:       &bucketName,
+		Key:          &key,
+		ChecksumMode: types.ChecksumModeEnabled,
+	}
+	if offset > 0 {
+		input.Range = aws.String(rangeFrom(offset))
+		input.IfMatch = aws.String(meta.ETag)
+	}
+	var resp *s3.GetObjectOutput
+	get := func() error {
+		return c.withCustomerKey(bucketName, key, false, func(sse sseCustomer) error {
+			input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = sse.algorithm, sse.key, sse.keyMD5
+			var err error
+			resp, err = c.client.GetObject(ctx, input, s3.WithAPIOptions(skipSDKChecksumValidation))
+			return err
+		})
+	}
+	err := get()
+	if offset > 0 && isAPIError(err, "PreconditionFailed", "InvalidRange") {
+		// 途中までのファイルの後にオブジェクトが更新された場合は最初からやり直す
+		c.logger.Info("partial download is stale, restarting", "bucket", bucketName, "key", key, "offset", offset)
+		removePartial(outputPath)
+		offset = 0
+		input.Range, input.IfMatch = nil, nil
+		err = get()
+	}
 	if err != nil {
-		return err
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if offset > 0 {
+		c.logger.Info("resuming download", "bucket", bucketName, "key", key, "offset", offset)
+		flags = os.O_WRONLY | os.O_APPEND
+	} else {
+		meta = partialMeta{
+			Bucket: bucketName,
+			Key:    key,
+			Size:   resp.ContentLength,
+			objectChecksums: newObjectChecksums(aws.ToString(resp.ETag),
+				resp.ChecksumCRC32, resp.ChecksumCRC32C, resp.ChecksumSHA1, resp.ChecksumSHA256,
+				resp.ServerSideEncryption, resp.SSECustomerAlgorithm),
+		}
+		if spec, ok := meta.spec(); ok && spec.parts > 0 {
+			meta.PartSize = c.partSize(ctx, bucketName, key, meta.ETag)
+		}
+		if err := writePartialMeta(outputPath, meta); err != nil {
+			return "", err
+		}
+	}
+	outFile, err := os.OpenFile(outputPath+partialSuffix, flags, 0644)
+	if err != nil {
+		return "", err
 	}
 	defer outFile.Close()
 
-	resp, err := c.client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: &bucketName,
-		Key:    &key,
+	// 書き込む内容のチェックサムを計算する。再開した場合はダウンロード済みの部分から計算する
+	var w io.Writer = outFile
+	spec, verify := meta.spec()
+	if verify && spec.parts > 0 && meta.PartSize <= 0 {
+		verify = false
+	}
+	var v *verifier
+	if verify {
+		v = newVerifier(spec, meta.PartSize)
+		if offset > 0 {
+			if err := hashFile(outputPath+partialSuffix, v); err != nil {
+				return "", err
+			}
+		}
+		w = io.MultiWriter(outFile, v)
+	}
+
+	progress := opts.Progress
+	if progress != nil && offset > 0 {
+		// 進捗は再開前にダウンロード済みの分も含めて通知する
+		progress = func(done, total int64) { opts.Progress(offset+done, offset+total) }
+	}
+	body := c.bandwidth.Reader(ctx, resp.Body)
+	if _, err := io.Copy(w, newProgressReader(body, resp.ContentLength, progress)); err != nil {
+		return "", err
+	}
+	if err := outFile.Close(); err != nil {
+		return "", err
+	}
+
+	if verify {
+		if err := v.check(key); err != nil {
+			// 壊れた内容から再開しないよう、途中までのファイルも削除する
+			removePartial(outputPath)
+			c.logger.Error("checksum mismatch", "bucket", bucketName, "key", key, "error", err)
+			return "", err
+		}
+		c.logger.Debug("download verified", "bucket", bucketName, "key", key, "algorithm", spec.algorithm)
+	} else {
+		c.logger.Info("download not verified: no usable checksum", "bucket", bucketName, "key", key, "etag", meta.ETag)
+	}
+
+	if err := os.Rename(outputPath+partialSuffix, outputPath); err != nil {
+		return "", err
+	}
+	os.Remove(outputPath + partialMetaSuffix)
+	return outputPath, nil
+}
+
+// partSize はマルチパートでアップロードされたオブジェクトの最初のパートの大きさを返します。
+// 取得できない場合は0を返します。
+func (c *S3Client) partSize(ctx context.Context, bucketName, key, etag string) int64 {
+	var head *s3.HeadObjectOutput
+	err := c.withCustomerKey(bucketName, key, true, func(sse sseCustomer) error {
+		var err error
+		head, err = c.client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket:               &bucketName,
+			Key:                  &key,
+			PartNumber:           1,
+			IfMatch:              aws.String(etag),
+			SSECustomerAlgorithm: sse.algorithm,
+			SSECustomerKey:       sse.key,
+			SSECustomerKeyMD5:    sse.keyMD5,
+		})
+		return err
 	})
+	if err != nil {
+		c.logger.Warn("failed to get part size", "bucket", bucketName, "key", key, "error", err)
+		return 0
+	}
+	return head.ContentLength
+}
+
+// UploadOptions はアップロードのオプションです
+type UploadOptions struct {
+	// Progress は送信のたびに、送信したバイト数と全体のバイト数で呼ばれます
+	Progress func(done, total int64)
+	// PartSize はマルチパートアップロードのパートサイズです（0の場合は DefaultPartSize）。
+	// これより大きいファイルはマルチパートでアップロードします。
+	PartSize int64
+	// StateDir は中断したマルチパートアップロードを再開するための情報を保存するディレクトリです
+	// （空の場合は DefaultUploadStateDir）
+	StateDir string
+	// Encryption はサーバー側暗号化の設定です。SSE-C の鍵はクライアントの鍵を使います
+	Encryption Encryption
+}
+
+// UploadObject はローカルのファイルを指定したバケット・キーにアップロードします。
+// 大きなファイルはマルチパートでアップロードし、中断された場合は次回の同じアップロードで再開します。
+func (c *S3Client) UploadObject(ctx context.Context, path, bucketName, key string, opts UploadOptions) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	_, err = io.Copy(outFile, resp.Body)
+	if c.transferTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.transferTimeout)
+		defer cancel()
+	}
+
+	if opts.Encryption.Mode == EncryptionCustomer {
+		// 以降の読み出しでは最初から鍵を付ける
+		defer func() {
+			if err == nil {
+				c.sseCObjects.Store(bucketName+"/"+key, true)
+			}
+		}()
+	}
+
+	size := info.Size()
+	if size > partSizeFor(size, opts.PartSize) {
+		return c.uploadMultipart(ctx, file, info, bucketName, key, opts)
+	}
+
+	sse, kmsKeyID, bucketKey, customer, err := c.uploadHeaders(opts.Encryption)
+	if err != nil {
+		return err
+	}
+
+	// S3が受け取った内容を検証できるよう、チェックサムを送る
+	checksum, err := sha256Base64(file)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	body := newProgressReader(c.bandwidth.Reader(ctx, file), size, opts.Progress)
+	_, err = c.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:         &bucketName,
+		Key:            &key,
+		Body:           body,
+		ContentLength:  size,
+		ChecksumSHA256: aws.String(checksum),
+
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          kmsKeyID,
+		BucketKeyEnabled:     bucketKey,
+		SSECustomerAlgorithm: customer.algorithm,
+		SSECustomerKey:       customer.key,
+		SSECustomerKeyMD5:    customer.keyMD5,
+	}, s3.WithAPIOptions(
+		// 署名のために本文を先読みすると帯域制限の分だけ時間がかかるため、本文は署名しない
+		v4.SwapComputePayloadSHA256ForUnsignedPayloadMiddleware,
+	))
 	return err
 }
+
+// availablePath は "name (1).ext" のように番号を付けて、存在しないパスを返します
+func availablePath(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Stat(candidate); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package aws
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// SearchOptions は SearchObjects の検索条件です
+type SearchOptions struct {
+	Bucket string
+	Prefix string
+	// Match はキーが一致するかを判定します。nilの場合はすべてのキーが一致します
+	Match func(key string) bool
+	// Concurrency が2以上の場合、直下のサブプレフィックスごとに並列で走査します
+	Concurrency int
+}
+
+// SearchProgress は検索の途中経過です
+type SearchProgress struct {
+	Scanned int          // 今回走査したオブジェクト数
+	Matched []ObjectInfo // 今回一致したオブジェクト
+}
+
+// WalkObjects はプレフィックス配下のオブジェクトをページ単位で走査します
+func (c *S3Client) WalkObjects(ctx context.Context, bucketName, prefix string, fn func(page []ObjectInfo) error) error {
+	paginator := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
+		Bucket: &bucketName,
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		page := make([]ObjectInfo, 0, len(output.Contents))
+		for _, object := range output.Contents {
+			page = append(page, newObjectInfo(object))
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListPrefixes はプレフィックス直下の「ディレクトリ」と、その階層にあるオブジェクトを返します
+func (c *S3Client) ListPrefixes(ctx context.Context, bucketName, prefix string) ([]string, []ObjectInfo, error) {
+	paginator := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
+		Bucket:    &bucketName,
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	})
+	var prefixes []string
+	var objects []ObjectInfo
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range output.CommonPrefixes {
+			prefixes = append(prefixes, aws.ToString(p.Prefix))
+		}
+		for _, object := range output.Contents {
+			objects = append(objects, newObjectInfo(object))
+		}
+	}
+	return prefixes, objects, nil
+}
+
+// SearchObjects はサーバー側でプレフィックス配下を走査し、一致したオブジェクトを
+// ページごとにprogressへ送信します。ctxをキャンセルすると途中で終了します。
+func (c *S3Client) SearchObjects(ctx context.Context, opts SearchOptions, progress chan<- SearchProgress) error {
+	send := func(page []ObjectInfo) error {
+		p := SearchProgress{Scanned: len(page)}
+		for _, obj := range page {
+			if opts.Match == nil || opts.Match(obj.Key) {
+				p.Matched = append(p.Matched, obj)
+			}
+		}
+		select {
+		case progress <- p:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if opts.Concurrency < 2 {
+		return c.WalkObjects(ctx, opts.Bucket, opts.Prefix, send)
+	}
+
+	// 直下の階層を列挙し、サブプレフィックスごとに並列で走査する
+	prefixes, objects, err := c.ListPrefixes(ctx, opts.Bucket, opts.Prefix)
+	if err != nil {
+		return err
+	}
+	if err := send(objects); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan string)
+	errs := make(chan error, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for prefix := range jobs {
+				if err := c.WalkObjects(ctx, opts.Bucket, prefix, send); err != nil {
+					errs <- err
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, prefix := range prefixes {
+		select {
+		case jobs <- prefix:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,159 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newSearchFake は logs/ 配下に直下のオブジェクトと2つのサブプレフィックスを持つバケットを返します
+func newSearchFake() *fakeS3 {
+	fake := newFakeS3("bucket")
+	for _, key := range []string{"logs/index.html", "logs/a/1.log", "logs/a/2.txt", "logs/b/3.log", "logs/b/c/4.log", "other/5.log"} {
+		fake.put("bucket", key, []byte(key))
+	}
+	return fake
+}
+
+// collectSearch は SearchObjects の途中経過をすべて受け取り、走査数と一致したキーを返します
+func collectSearch(client *S3Client, ctx context.Context, opts SearchOptions) (int, []string, error) {
+	progress := make(chan SearchProgress)
+	done := make(chan error, 1)
+	go func() {
+		done <- client.SearchObjects(ctx, opts, progress)
+		close(progress)
+	}()
+	scanned := 0
+	var keys []string
+	for p := range progress {
+		scanned += p.Scanned
+		for _, obj := range p.Matched {
+			keys = append(keys, obj.Key)
+		}
+	}
+	sort.Strings(keys)
+	return scanned, keys, <-done
+}
+
+// TestListObjectsPages は ListObjects が1ページで終わらない一覧をすべて返すことをテストします
+func TestListObjectsPages(t *testing.T) {
+	fake := newSearchFake()
+	fake.pageSize = 2
+	objects, err := newFakeClient(fake).ListObjects(context.Background(), "bucket", "logs/")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+	}
+	expected := []string{"logs/a/1.log", "logs/a/2.txt", "logs/b/3.log", "logs/b/c/4.log", "logs/index.html"}
+	if !reflect.DeepEqual(keys, expected) || len(fake.listed) != 3 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []any{expected, 3}, []any{keys, len(fake.listed)})
+	}
+}
+
+func TestSearchObjects(t *testing.T) {
+	match := func(key string) bool { return strings.HasSuffix(key, ".log") }
+	testCases := []struct {
+		name        string
+		concurrency int
+		listed      []string // ListObjectsV2 で指定されたプレフィックスと区切り文字
+	}{
+		{name: "順に走査", concurrency: 1, listed: []string{"logs/|"}},
+		{name: "サブプレフィックスごとに並列で走査", concurrency: 3, listed: []string{"logs/a/|", "logs/b/|", "logs/|/"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := newSearchFake()
+			client := newFakeClient(fake)
+			scanned, keys, err := collectSearch(client, context.Background(),
+				SearchOptions{Bucket: "bucket", Prefix: "logs/", Match: match, Concurrency: tc.concurrency})
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			expected := []string{"logs/a/1.log", "logs/b/3.log", "logs/b/c/4.log"}
+			if scanned != 5 || !reflect.DeepEqual(keys, expected) {
+				t.Errorf("期待結果 %v, 実際の結果 %v", []any{5, expected}, []any{scanned, keys})
+			}
+			sort.Strings(fake.listed)
+			if !reflect.DeepEqual(fake.listed, tc.listed) {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.listed, fake.listed)
+			}
+		})
+	}
+}
+
+// TestSearchObjectsWorkerError は1つのサブプレフィックスの走査に失敗すると、ほかの走査もキャンセルされることをテストします
+func TestSearchObjectsWorkerError(t *testing.T) {
+	fake := newSearchFake()
+	failed := errors.New("list failed")
+	canceled := make(chan struct{})
+	fake.listHook = func(ctx context.Context, prefix string) error {
+		switch prefix {
+		case "logs/a/":
+			// キャンセルされるまで終わらない走査
+			<-ctx.Done()
+			close(canceled)
+			return ctx.Err()
+		case "logs/b/":
+			return failed
+		}
+		return nil
+	}
+	client := newFakeClient(fake)
+
+	result := make(chan error, 1)
+	go func() {
+		_, _, err := collectSearch(client, context.Background(), SearchOptions{Bucket: "bucket", Prefix: "logs/", Concurrency: 2})
+		result <- err
+	}()
+	select {
+	case err := <-result:
+		if !errors.Is(err, failed) {
+			t.Errorf("期待結果 %v, 実際の結果 %v", failed, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ほかの走査がキャンセルされず、検索が終わりません")
+	}
+	select {
+	case <-canceled:
+	default:
+		t.Error("ほかの走査がキャンセルされていません")
+	}
+}
+
+// TestSearchObjectsCancel は途中経過を受け取る側がいなくても、ctx のキャンセルで終了することをテストします
+func TestSearchObjectsCancel(t *testing.T) {
+	testCases := []struct {
+		name        string
+		concurrency int
+	}{
+		{name: "順に走査", concurrency: 1},
+		{name: "並列で走査", concurrency: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeClient(newSearchFake())
+			ctx, cancel := context.WithCancel(context.Background())
+			progress := make(chan SearchProgress) // 誰も受け取らない
+			result := make(chan error, 1)
+			go func() {
+				result <- client.SearchObjects(ctx, SearchOptions{Bucket: "bucket", Prefix: "logs/", Concurrency: tc.concurrency}, progress)
+			}()
+			cancel()
+			select {
+			case err := <-result:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("期待結果 %v, 実際の結果 %v", context.Canceled, err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("キャンセルしても検索が終わりません")
+			}
+		})
+	}
+}
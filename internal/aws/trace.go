@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// traceMiddleware はAPI呼び出しごとに操作名・HTTPステータス・リクエストID・
+// 所要時間・試行回数をデバッグログに記録するミドルウェアを返します。
+// リトライを含めた全体を計測するため、Initializeステップに追加します。
+func traceMiddleware(logger *slog.Logger) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("S3CLITrace",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				start := time.Now()
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				if !logger.Enabled(ctx, slog.LevelDebug) && err == nil {
+					return out, metadata, err
+				}
+
+				attrs := []slog.Attr{
+					slog.String("op", awsmiddleware.GetOperationName(ctx)),
+					slog.Int("status", responseStatus(metadata, err)),
+					slog.Duration("latency", time.Since(start)),
+				}
+				if id, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+					attrs = append(attrs, slog.String("request_id", id))
+				}
+				if results, ok := retry.GetAttemptResults(metadata); ok {
+					attrs = append(attrs, slog.Int("attempts", len(results.Results)))
+				}
+
+				level := slog.LevelDebug
+				if err != nil {
+					level = slog.LevelWarn
+					attrs = append(attrs, slog.String("error", err.Error()))
+				}
+				logger.LogAttrs(ctx, level, "s3 request", attrs...)
+				return out, metadata, err
+			}), middleware.After)
+	}
+}
+
+// responseStatus はHTTPレスポンスのステータスコードを返します（レスポンスがない場合は0）
+func responseStatus(metadata middleware.Metadata, err error) int {
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.HTTPStatusCode()
+	}
+	if resp, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok {
+		return resp.StatusCode
+	}
+	return 0
+}
+
+// sdkLogger はSDK自身のログ（リトライの理由など）をslogに送るアダプターです
+type sdkLogger struct {
+	logger *slog.Logger
+}
+
+// Logf は smithy-go の logging.Logger を実装します
+func (l sdkLogger) Logf(classification logging.Classification, format string, v ...interface{}) {
+	level := slog.LevelDebug
+	if classification == logging.Warn {
+		level = slog.LevelWarn
+	}
+	l.logger.Log(context.Background(), level, fmt.Sprintf(format, v...), slog.String("source", "aws-sdk"))
+}
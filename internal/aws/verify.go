@@ -0,0 +1,98 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// VerifyStatus はローカルのファイルとオブジェクトを比べた結果です
+type VerifyStatus string
+
+const (
+	// VerifyOK は内容が一致したことを表します
+	VerifyOK VerifyStatus = "ok"
+	// VerifyMismatch はチェックサムが一致しないことを表します
+	VerifyMismatch VerifyStatus = "mismatch"
+	// VerifySizeDiffers はサイズが異なることを表します
+	VerifySizeDiffers VerifyStatus = "size"
+	// VerifyMissing はローカルのファイルがないことを表します
+	VerifyMissing VerifyStatus = "missing"
+	// VerifyUnverifiable は使えるチェックサムがなく、サイズしか比べられなかったことを表します
+	VerifyUnverifiable VerifyStatus = "unverifiable"
+)
+
+// VerifyResult は VerifyFile の結果です
+type VerifyResult struct {
+	Status VerifyStatus
+	// Algorithm は比べたチェックサムのアルゴリズムです
+	Algorithm string
+	// LocalSize と Size はローカルのファイルとオブジェクトの大きさです
+	LocalSize, Size int64
+	// Err は Status が VerifyMismatch の場合の *ChecksumError です
+	Err error
+}
+
+// VerifyFile はローカルのファイルがオブジェクトと同じ内容かを、サイズとチェックサムで確認します
+func (c *S3Client) VerifyFile(ctx context.Context, bucketName, key, path string) (VerifyResult, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return VerifyResult{Status: VerifyMissing}, nil
+	}
+	if err != nil {
+		return VerifyResult{}, err
+	}
+
+	var head *s3.HeadObjectOutput
+	err = c.withCustomerKey(bucketName, key, true, func(sse sseCustomer) error {
+		var err error
+		head, err = c.client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket:               &bucketName,
+			Key:                  &key,
+			ChecksumMode:         types.ChecksumModeEnabled,
+			SSECustomerAlgorithm: sse.algorithm,
+			SSECustomerKey:       sse.key,
+			SSECustomerKeyMD5:    sse.keyMD5,
+		})
+		return err
+	})
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	result := VerifyResult{LocalSize: info.Size(), Size: head.ContentLength}
+	if head.ContentLength != info.Size() {
+		result.Status = VerifySizeDiffers
+		return result, nil
+	}
+
+	sums := newObjectChecksums(aws.ToString(head.ETag),
+		head.ChecksumCRC32, head.ChecksumCRC32C, head.ChecksumSHA1, head.ChecksumSHA256,
+		head.ServerSideEncryption, head.SSECustomerAlgorithm)
+	spec, ok := sums.spec()
+	result.Status = VerifyUnverifiable
+	if !ok {
+		return result, nil
+	}
+	var partSize int64
+	if spec.parts > 0 {
+		if partSize = c.partSize(ctx, bucketName, key, sums.ETag); partSize <= 0 {
+			return result, nil
+		}
+	}
+
+	v := newVerifier(spec, partSize)
+	if err := hashFile(path, v); err != nil {
+		return VerifyResult{}, err
+	}
+	result.Algorithm = spec.algorithm
+	if err := v.check(key); err != nil {
+		result.Status, result.Err = VerifyMismatch, err
+		return result, nil
+	}
+	result.Status = VerifyOK
+	return result, nil
+}
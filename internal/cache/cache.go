@@ -0,0 +1,180 @@
+// Package cache はオブジェクト一覧の取得結果をキャッシュします。
+// メモリに保持するほか、指定されたディレクトリがあれば次に起動したときのためにディスクにも保存します。
+package cache
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+)
+
+// Listing はバケットのプレフィックス配下のオブジェクト一覧と、それを取得した日時です
+type Listing struct {
+	Objects   []aws.ObjectInfo `json:"objects"`
+	FetchedAt time.Time        `json:"fetched_at"`
+}
+
+// Cache はプロファイルとエンドポイントの組ひとつ分のオブジェクト一覧のキャッシュです
+type Cache struct {
+	ttl time.Duration
+	dir string // ディスクに保存するディレクトリ（空の場合はメモリだけ）
+
+	mu      sync.Mutex
+	entries map[string]map[string]Listing // バケット → プレフィックス → 一覧
+	gens    map[string]uint64             // バケット → 一覧を捨てた回数
+}
+
+// DefaultDir はディスクに保存するキャッシュの既定のディレクトリを返します（例: ~/.cache/s3-cli/listings）
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "s3-cli", "listings"), nil
+}
+
+// New はキャッシュを作成します。dir が空でなければ、その下にプロファイルとエンドポイントの組ごとに保存します。
+// 取得から ttl 以上たった一覧は古いものとして扱います。
+func New(dir string, ttl time.Duration, profile, endpoint string) *Cache {
+	if dir != "" {
+		if profile == "" {
+			profile = "default"
+		}
+		sum := sha256.Sum256([]byte(profile + "|" + endpoint))
+		dir = filepath.Join(dir, hex.EncodeToString(sum[:8]))
+	}
+	return &Cache{ttl: ttl, dir: dir, entries: make(map[string]map[string]Listing), gens: make(map[string]uint64)}
+}
+
+// Get はキャッシュした一覧を返します。メモリになければディスクから読み込みます
+func (c *Cache) Get(bucket, prefix string) (Listing, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if l, ok := c.entries[bucket][prefix]; ok {
+		return l, true
+	}
+	if c.dir == "" {
+		return Listing{}, false
+	}
+	// 壊れたファイルは無視し、次に取得したときに書き直す
+	l, err := c.read(bucket, prefix)
+	if err != nil {
+		return Listing{}, false
+	}
+	c.set(bucket, prefix, l)
+	return l, true
+}
+
+// Put は取得した一覧を記録します。ディスクに保存できなかった場合もメモリには記録します
+func (c *Cache) Put(bucket, prefix string, l Listing) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(bucket, prefix, l)
+	if c.dir == "" {
+		return nil
+	}
+	return c.write(bucket, prefix, l)
+}
+
+// Generation はバケットの一覧を捨てるたびに増える世代を返します。
+// 一覧の取得を始めるときに記録し、PutIfCurrent に渡します。
+func (c *Cache) Generation(bucket string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gens[bucket]
+}
+
+// PutIfCurrent は取得を始めてからバケットの一覧が捨てられていない（世代が gen のまま）場合だけ、一覧を記録します。
+// 取得中にオブジェクトが書き換えられた場合に、古い一覧を書き戻さないために使います。記録したかどうかを返します。
+func (c *Cache) PutIfCurrent(bucket, prefix string, gen uint64, l Listing) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gens[bucket] != gen {
+		return false, nil
+	}
+	c.set(bucket, prefix, l)
+	if c.dir == "" {
+		return true, nil
+	}
+	return true, c.write(bucket, prefix, l)
+}
+
+// Stale は一覧が取得から ttl 以上たっているかを返します
+func (c *Cache) Stale(l Listing) bool {
+	return time.Since(l.FetchedAt) >= c.ttl
+}
+
+// InvalidateBucket はバケットのすべての一覧を捨てます。オブジェクトを書き換えたときに使います
+func (c *Cache) InvalidateBucket(bucket string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, bucket)
+	c.gens[bucket]++
+	if c.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(filepath.Join(c.dir, bucket))
+}
+
+func (c *Cache) set(bucket, prefix string, l Listing) {
+	if c.entries[bucket] == nil {
+		c.entries[bucket] = make(map[string]Listing)
+	}
+	c.entries[bucket][prefix] = l
+}
+
+// path は一覧を保存するファイルのパスを返します。プレフィックスはファイル名に使えない文字を含むためハッシュにします
+func (c *Cache) path(bucket, prefix string) string {
+	sum := sha256.Sum256([]byte(prefix))
+	return filepath.Join(c.dir, bucket, hex.EncodeToString(sum[:16])+".json.gz")
+}
+
+func (c *Cache) read(bucket, prefix string) (Listing, error) {
+	var l Listing
+	f, err := os.Open(c.path(bucket, prefix))
+	if err != nil {
+		return l, err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return l, err
+	}
+	err = json.NewDecoder(r).Decode(&l)
+	return l, err
+}
+
+// write は一覧を gzip で圧縮したJSONとして保存します。書き込み中に終了しても壊れないよう、一時ファイルから置き換えます
+func (c *Cache) write(bucket, prefix string, l Listing) error {
+	path := c.path(bucket, prefix)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// 同じ一覧を同時に保存する他の s3-cli と一時ファイルを取り合わないよう、名前は毎回変える
+	f, err := os.CreateTemp(filepath.Dir(path), "listing-*.tmp")
+	if err != nil {
+		return err
+	}
+	w := gzip.NewWriter(f)
+	err = json.NewEncoder(w).Encode(l)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
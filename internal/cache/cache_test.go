@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+)
+
+func TestCache(t *testing.T) {
+	testCases := []struct {
+		name string
+		dir  bool
+	}{
+		{name: "メモリのみ"},
+		{name: "ディスクにも保存", dir: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := ""
+			if tc.dir {
+				dir = t.TempDir()
+			}
+			c := New(dir, time.Minute, "", "")
+			if _, ok := c.Get("logs", "2026/"); ok {
+				t.Fatal("空のキャッシュから一覧が返されました")
+			}
+
+			listing := Listing{
+				Objects:   []aws.ObjectInfo{{Key: "2026/app.log", Size: 42, StorageClass: "STANDARD"}},
+				FetchedAt: time.Now().Add(-2 * time.Minute).UTC().Truncate(time.Second),
+			}
+			if err := c.Put("logs", "2026/", listing); err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			got, ok := c.Get("logs", "2026/")
+			if !ok || !reflect.DeepEqual(got, listing) {
+				t.Errorf("期待結果 %v, 実際の結果 %v", listing, got)
+			}
+			if !c.Stale(got) {
+				t.Errorf("ttl を過ぎた一覧が古いものとして扱われませんでした: %v", got.FetchedAt)
+			}
+			if _, ok := c.Get("logs", ""); ok {
+				t.Error("別のプレフィックスの一覧が返されました")
+			}
+
+			// ディスクに保存した一覧は、次に起動したときにも読める
+			again, ok := New(dir, time.Minute, "default", "").Get("logs", "2026/")
+			if ok != tc.dir {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.dir, ok)
+			}
+			if tc.dir && !reflect.DeepEqual(again, listing) {
+				t.Errorf("期待結果 %v, 実際の結果 %v", listing, again)
+			}
+
+			if err := c.InvalidateBucket("logs"); err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if _, ok := c.Get("logs", "2026/"); ok {
+				t.Error("捨てたバケットの一覧が返されました")
+			}
+		})
+	}
+}
+
+// TestCachePutIfCurrent は取得中にバケットの一覧が捨てられた場合に、古い一覧を記録しないことをテストします
+func TestCachePutIfCurrent(t *testing.T) {
+	testCases := []struct {
+		name       string
+		invalidate bool
+		expected   bool
+	}{
+		{name: "捨てられていない", expected: true},
+		{name: "取得中に捨てられた", invalidate: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			c := New(dir, time.Minute, "", "")
+			gen := c.Generation("logs")
+			if tc.invalidate {
+				if err := c.InvalidateBucket("logs"); err != nil {
+					t.Fatalf("予期しないエラー: %v", err)
+				}
+			}
+			listing := Listing{Objects: []aws.ObjectInfo{{Key: "old.log"}}, FetchedAt: time.Now()}
+			put, err := c.PutIfCurrent("logs", "", gen, listing)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if _, ok := c.Get("logs", ""); put != tc.expected || ok != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, []bool{put, ok})
+			}
+			// ディスクにも書き戻さない
+			if _, ok := New(dir, time.Minute, "", "").Get("logs", ""); ok != tc.expected {
+				t.Errorf("ディスク: 期待結果 %v, 実際の結果 %v", tc.expected, ok)
+			}
+		})
+	}
+}
+
+// TestCacheScope はプロファイルとエンドポイントの組ごとに別々に保存されることをテストします
+func TestCacheScope(t *testing.T) {
+	dir := t.TempDir()
+	listing := Listing{Objects: []aws.ObjectInfo{{Key: "a"}}, FetchedAt: time.Now()}
+	if err := New(dir, time.Minute, "prod", "").Put("logs", "", listing); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if _, ok := New(dir, time.Minute, "dev", "").Get("logs", ""); ok {
+		t.Error("別のプロファイルの一覧が返されました")
+	}
+	if _, ok := New(dir, time.Minute, "prod", "http://localhost:4566").Get("logs", ""); ok {
+		t.Error("別のエンドポイントの一覧が返されました")
+	}
+}
+
+// TestCacheBrokenFile は壊れたファイルを無視することをテストします
+func TestCacheBrokenFile(t *testing.T) {
+	c := New(t.TempDir(), time.Minute, "", "")
+	path := c.path("logs", "")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("not gzip"), 0600); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if _, ok := c.Get("logs", ""); ok {
+		t.Error("壊れたファイルから一覧が返されました")
+	}
+}
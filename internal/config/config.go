@@ -0,0 +1,287 @@
+// Package config は s3-cli の設定ファイル（config.yaml）を読み書きします。
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"gopkg.in/yaml.v3"
+)
+
+// Config は設定ファイルの内容です。フラグで指定された値は設定ファイルの値より優先されます。
+type Config struct {
+	// Profile はデフォルトで使うAWSプロファイルです
+	Profile string `yaml:"profile,omitempty"`
+	// Endpoint はデフォルトのエンドポイントです。URLまたはEndpointsのエイリアス名を指定します
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// Endpoints はエイリアス名からエンドポイントURLへの対応です
+	Endpoints map[string]string `yaml:"endpoints,omitempty"`
+	// OutputDir はダウンロード先のディレクトリです
+	OutputDir string `yaml:"output_dir,omitempty"`
+	// ConflictPolicy は同名ファイルが存在する場合の動作です（error, skip, overwrite, rename）
+	ConflictPolicy string `yaml:"conflict_policy,omitempty"`
+	// Concurrency はバックグラウンド処理の並列数です
+	Concurrency int `yaml:"concurrency,omitempty"`
+	// MaxRetries はS3呼び出しのリトライの最大回数です（未指定の場合はフラグの既定値）
+	MaxRetries *int `yaml:"max_retries,omitempty"`
+	// RetryMode はリトライの方式です（standard, adaptive）
+	RetryMode string `yaml:"retry_mode,omitempty"`
+	// Timeout は転送以外のS3呼び出し1回の制限時間です（例: 30s）
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// OperationTimeouts は操作の種類（list, head, put, copy, delete）ごとの制限時間です（例: put: 30m）
+	OperationTimeouts map[string]time.Duration `yaml:"operation_timeouts,omitempty"`
+	// TransferTimeout はダウンロード1件の制限時間です（例: 30m）
+	TransferTimeout time.Duration `yaml:"transfer_timeout,omitempty"`
+	// MaxRPS は1秒あたりのリクエスト数の上限です
+	MaxRPS float64 `yaml:"max_rps,omitempty"`
+	// BandwidthLimit はすべての転送を合わせた帯域の上限です（例: 20MB/s）
+	BandwidthLimit string `yaml:"bandwidth_limit,omitempty"`
+	// SSE はアップロードするオブジェクトのサーバー側暗号化です（sse-s3, sse-kms, sse-c）
+	SSE string `yaml:"sse,omitempty"`
+	// SSEKMSKeyID は SSE-KMS の鍵のIDかARNです
+	SSEKMSKeyID string `yaml:"sse_kms_key_id,omitempty"`
+	// BucketKey は SSE-KMS でS3バケットキーを使うかです
+	BucketKey bool `yaml:"bucket_key,omitempty"`
+	// SSECKeyFile は SSE-C の鍵のファイルです
+	SSECKeyFile string `yaml:"sse_c_key_file,omitempty"`
+	// Theme は配色テーマの名前です
+	Theme string `yaml:"theme,omitempty"`
+	// Lang は表示言語です（en, ja）
+	Lang string `yaml:"lang,omitempty"`
+	// Keybindings は操作名からキーの一覧への対応です
+	Keybindings map[string][]string `yaml:"keybindings,omitempty"`
+	// OpenWith は拡張子（"*" はその他すべて）から、オブジェクトを開くコマンドへの対応です
+	OpenWith map[string]string `yaml:"open_with,omitempty"`
+	// CacheTTL はキャッシュしたオブジェクト一覧を取り直さずに使う期間です（例: 10m）
+	CacheTTL time.Duration `yaml:"cache_ttl,omitempty"`
+	// DiskCache はオブジェクト一覧のキャッシュをディスクにも保存するかです
+	DiskCache bool `yaml:"disk_cache,omitempty"`
+}
+
+// conflictPolicies は conflict_policy に指定できる値です
+var conflictPolicies = []string{"error", "skip", "overwrite", "rename"}
+
+// retryModes は retry_mode に指定できる値です
+var retryModes = []string{"standard", "adaptive"}
+
+// Template は設定ファイルを新規作成するときの内容です
+const Template = `# s3-cli configuration
+# Values given as command line flags take precedence over this file.
+
+# profile: default
+# endpoint: local              # URL or a name from "endpoints"
+# endpoints:
+#   local: http://localhost:4566
+#   minio: http://minio.internal:9000
+# output_dir: ~/Downloads
+# conflict_policy: error       # error | skip | overwrite | rename
+# concurrency: 4
+# max_retries: 2
+# retry_mode: standard         # standard | adaptive
+# timeout: 30s                 # per S3 call, except downloads
+# operation_timeouts:          # per kind of call: list | head | put | copy | delete
+#   put: 10m                   # (default: 10m for put and copy, "timeout" for the rest)
+# transfer_timeout: 0s         # per download (0: no limit)
+# max_rps: 0                   # requests per second (0: no limit)
+# bandwidth_limit: 20MB/s      # shared by all transfers (0: no limit)
+# sse: sse-kms                 # uploads: sse-s3 | sse-kms | sse-c (default: bucket setting)
+# sse_kms_key_id: alias/backup
+# bucket_key: true
+# sse_c_key_file: ~/.config/s3-cli/sse-c.key   # 32-byte key or its base64
+# theme: dark
+# lang: en                     # en | ja (default: from LANG)
+# keybindings:
+#   down: [j, down]
+#   up: [k, up]
+# open_with:                   # "open with" commands by extension; the object is piped to stdin,
+#   json: jq -C . | less -R    # or saved to a temporary file that replaces {}
+#   parquet: "| parquet-tools show {}"   # a leading | shows the output in $PAGER
+#   pdf: xdg-open {}
+#   "*": less
+# cache_ttl: 5m                # object lists younger than this are shown without relisting
+# disk_cache: false            # also keep object lists in $XDG_CACHE_HOME/s3-cli/listings
+`
+
+// DefaultPath は設定ファイルの既定のパスを返します。
+// $XDG_CONFIG_HOME が設定されていればその下、なければ ~/.config の下を使います。
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", i18n.Errorf("config.home_dir", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "s3-cli", "config.yaml"), nil
+}
+
+// Load は設定ファイルを読み込んで検証します。ファイルが存在しない場合は空の設定を返します。
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, i18n.Errorf("config.read_failed", err)
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, i18n.Errorf("config.file_error", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse はYAMLを解析して検証します。未知のキーはエラーになります。
+func Parse(data []byte) (*Config, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if err := checkKnownKeys(&root); err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// checkKnownKeys はトップレベルのキーがすべて既知のものかを確認します
+func checkKnownKeys(root *yaml.Node) error {
+	if len(root.Content) == 0 {
+		return nil
+	}
+	mapping := root.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return i18n.Errorf("config.top_level_not_mapping", mapping.Line)
+	}
+
+	known := knownKeys()
+	for i := 0; i < len(mapping.Content); i += 2 {
+		key := mapping.Content[i]
+		if !contains(known, key.Value) {
+			return i18n.Errorf("config.unknown_key", key.Line, key.Value, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// knownKeys はConfigのyamlタグから使えるキーの一覧を返します
+func knownKeys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Validate は設定値を検証します
+func (c *Config) Validate() error {
+	if c.ConflictPolicy != "" && !contains(conflictPolicies, c.ConflictPolicy) {
+		return i18n.Errorf("config.invalid_conflict_policy", c.ConflictPolicy, strings.Join(conflictPolicies, ", "))
+	}
+	if c.Concurrency < 0 {
+		return i18n.Errorf("config.invalid_concurrency", c.Concurrency)
+	}
+	if c.MaxRetries != nil && *c.MaxRetries < 0 {
+		return i18n.Errorf("config.negative_value", "max_retries", *c.MaxRetries)
+	}
+	if c.RetryMode != "" && !contains(retryModes, c.RetryMode) {
+		return i18n.Errorf("config.invalid_choice", "retry_mode", c.RetryMode, strings.Join(retryModes, ", "))
+	}
+	if c.Timeout < 0 {
+		return i18n.Errorf("config.negative_value", "timeout", c.Timeout)
+	}
+	for kind, d := range c.OperationTimeouts {
+		if !contains(aws.TimeoutOperations, kind) {
+			return i18n.Errorf("config.invalid_choice", "operation_timeouts", kind, strings.Join(aws.TimeoutOperations, ", "))
+		}
+		if d < 0 {
+			return i18n.Errorf("config.negative_value", "operation_timeouts."+kind, d)
+		}
+	}
+	if c.TransferTimeout < 0 {
+		return i18n.Errorf("config.negative_value", "transfer_timeout", c.TransferTimeout)
+	}
+	if c.CacheTTL < 0 {
+		return i18n.Errorf("config.negative_value", "cache_ttl", c.CacheTTL)
+	}
+	if c.MaxRPS < 0 {
+		return i18n.Errorf("config.negative_value", "max_rps", c.MaxRPS)
+	}
+	if _, err := aws.ParseBandwidth(c.BandwidthLimit); err != nil {
+		return fmt.Errorf("bandwidth_limit: %w", err)
+	}
+	if _, err := aws.ParseEncryptionMode(c.SSE); err != nil {
+		return fmt.Errorf("sse: %w", err)
+	}
+	if c.Lang != "" {
+		if _, err := i18n.Parse(c.Lang); err != nil {
+			return fmt.Errorf("lang: %w", err)
+		}
+	}
+	for name, url := range c.Endpoints {
+		if url == "" {
+			return i18n.Errorf("config.empty_endpoint", name)
+		}
+	}
+	for ext, command := range c.OpenWith {
+		// 出力をページャーに流す印の "|" だけの場合も空とみなす
+		if strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(command), "|")) == "" {
+			return i18n.Errorf("config.empty_open_with", ext)
+		}
+	}
+	return nil
+}
+
+// ResolveEndpoint はエイリアス名をエンドポイントURLに変換します。
+// エイリアスとして登録されていない値はそのまま返します。
+func (c *Config) ResolveEndpoint(nameOrURL string) string {
+	if url, ok := c.Endpoints[nameOrURL]; ok {
+		return url
+	}
+	return nameOrURL
+}
+
+// ExpandHome は先頭の "~/" をホームディレクトリに展開します
+func ExpandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`
+profile: dev
+endpoint: local
+endpoints:
+  local: http://localhost:4566
+output_dir: ~/Downloads
+conflict_policy: rename
+concurrency: 8
+timeout: 45s
+operation_timeouts:
+  put: 30m
+max_retries: 0
+theme: dark
+keybindings:
+  down: [j, down]
+open_with:
+  json: jq -C . | less -R
+cache_ttl: 1h
+disk_cache: true
+`)
+	cfg, err := Parse(data)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if cfg.Profile != "dev" || cfg.ConflictPolicy != "rename" || cfg.Concurrency != 8 {
+		t.Errorf("読み込んだ値が期待と異なります: %+v", cfg)
+	}
+	if cfg.Timeout != 45*time.Second || cfg.MaxRetries == nil || *cfg.MaxRetries != 0 {
+		t.Errorf("timeout/max_retries が期待と異なります: %v %v", cfg.Timeout, cfg.MaxRetries)
+	}
+	if got := cfg.OperationTimeouts["put"]; got != 30*time.Minute {
+		t.Errorf("operation_timeouts: 期待結果 %v, 実際の結果 %v", 30*time.Minute, got)
+	}
+	if got := cfg.ResolveEndpoint(cfg.Endpoint); got != "http://localhost:4566" {
+		t.Errorf("エイリアスの解決: 期待結果 %q, 実際の結果 %q", "http://localhost:4566", got)
+	}
+	if got := cfg.ResolveEndpoint("http://example.com"); got != "http://example.com" {
+		t.Errorf("URLはそのまま返されるはずです: %q", got)
+	}
+	if len(cfg.Keybindings["down"]) != 2 {
+		t.Errorf("keybindingsが期待と異なります: %v", cfg.Keybindings)
+	}
+	if got := cfg.OpenWith["json"]; got != "jq -C . | less -R" {
+		t.Errorf("open_with: 期待結果 %q, 実際の結果 %q", "jq -C . | less -R", got)
+	}
+	if cfg.CacheTTL != time.Hour || !cfg.DiskCache {
+		t.Errorf("cache_ttl/disk_cache が期待と異なります: %v %v", cfg.CacheTTL, cfg.DiskCache)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		message string
+	}{
+		{name: "未知のキー", data: "profile: dev\ncolour: red\n", message: `2行目: 不明なキー "colour"`},
+		{name: "不正な競合時の動作", data: "conflict_policy: ask\n", message: "conflict_policy"},
+		{name: "負の並列数", data: "concurrency: -1\n", message: "concurrency"},
+		{name: "空のエンドポイント", data: "endpoints:\n  local: \"\"\n", message: "endpoints.local"},
+		{name: "空のコマンド", data: "open_with:\n  csv: \" \"\n", message: "open_with.csv"},
+		{name: "ページャーの印だけのコマンド", data: "open_with:\n  csv: \"| \"\n", message: "open_with.csv"},
+		{name: "不正なリトライ方式", data: "retry_mode: fast\n", message: "retry_mode"},
+		{name: "負のリトライ回数", data: "max_retries: -1\n", message: "max_retries"},
+		{name: "負のキャッシュ期間", data: "cache_ttl: -1m\n", message: "cache_ttl"},
+		{name: "不正な制限時間", data: "timeout: soon\n", message: "soon"},
+		{name: "不明な操作の制限時間", data: "operation_timeouts:\n  upload: 1m\n", message: "operation_timeouts"},
+		{name: "負の操作の制限時間", data: "operation_timeouts:\n  list: -1s\n", message: "operation_timeouts.list"},
+		{name: "不正な帯域制限", data: "bandwidth_limit: fast\n", message: "bandwidth_limit"},
+		{name: "不正な暗号化方式", data: "sse: aes\n", message: "sse"},
+		{name: "型の誤り", data: "concurrency: many\n", message: "cannot unmarshal"},
+		{name: "トップレベルがリスト", data: "- a\n- b\n", message: "トップレベル"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.data))
+			if err == nil {
+				t.Fatal("エラーが返されませんでした")
+			}
+			if !strings.Contains(err.Error(), tc.message) {
+				t.Errorf("エラーメッセージに %q が含まれていません: %v", tc.message, err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	// ファイルがない場合は空の設定になる
+	cfg, err := Load(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if cfg.Profile != "" {
+		t.Errorf("空の設定が返されませんでした: %+v", cfg)
+	}
+
+	// ひな形はそのまま読み込める
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(Template), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Errorf("ひな形の読み込みに失敗しました: %v", err)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if path != "/tmp/xdg/s3-cli/config.yaml" {
+		t.Errorf("期待結果 %q, 実際の結果 %q", "/tmp/xdg/s3-cli/config.yaml", path)
+	}
+}
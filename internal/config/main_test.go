@@ -0,0 +1,14 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// TestMain はメッセージを検証するテストのため、表示言語を日本語にしてから実行します
+func TestMain(m *testing.M) {
+	i18n.Set(i18n.Japanese)
+	os.Exit(m.Run())
+}
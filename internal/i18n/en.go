@@ -0,0 +1,324 @@
+package i18n
+
+// en は英語のメッセージカタログです
+var en = map[string]string{
+	// 言語
+	"lang.unknown": "unknown language %q (one of %s)",
+
+	// コマンド
+	"cmd.endpoint_required": "--endpoint-url or \"endpoint\" in the config file is required",
+	"cmd.config_missing":    "config file not found: %s",
+	"cmd.editor_failed":     "failed to run the editor: %w",
+	"cmd.invalid_s3_uri":    "not an S3 URI (expected s3://bucket/key): %s",
+	"cmd.open_twice":        "give the start location either as an argument or with --open, not both",
+	"cmd.upload_done":       "Uploaded: %s → s3://%s/%s",
+
+	// 設定ファイル
+	"config.home_dir":                "cannot determine the home directory: %w",
+	"config.read_failed":             "cannot read the config file: %w",
+	"config.file_error":              "config file %s: %w",
+	"config.top_level_not_mapping":   "line %d: the top level of the config file must be a mapping of keys to values",
+	"config.unknown_key":             "line %d: unknown key %q (valid keys: %s)",
+	"config.invalid_conflict_policy": "conflict_policy %q is not allowed (one of %s)",
+	"config.invalid_concurrency":     "concurrency must be 1 or greater: %d",
+	"config.negative_value":          "%s must not be negative: %v",
+	"config.invalid_choice":          "%s %q is not allowed (one of %s)",
+	"config.empty_endpoint":          "endpoints.%s has an empty URL",
+	"config.empty_open_with":         "open_with.%s has an empty command",
+	"state.read_failed":              "cannot read the state file %s: %v",
+	"state.invalid_location":         "invalid location %q: use s3://bucket/prefix",
+	"config.unknown_keybinding":      "keybindings: unknown action %q (valid actions: %s)",
+	"config.keybinding_without_keys": "keybindings.%s: no keys given",
+	"config.unknown_theme":           "unknown theme %q (one of %s)",
+
+	// S3
+	"aws.load_config_failed":      "failed to load the AWS configuration: %w",
+	"aws.skipped":                 "skipped because a file with the same name exists",
+	"aws.unknown_conflict_policy": "unknown conflict policy %q (one of error, skip, overwrite, rename)",
+	"aws.unknown_retry_mode":      "unknown retry mode %q (standard or adaptive)",
+	"aws.invalid_bandwidth":       "invalid bandwidth %q (e.g. 20MB/s, 512KB/s or 0 for no limit)",
+	"aws.file_exists":             "file already exists: %s",
+	"aws.unknown_encryption":      "unknown encryption %q (one of sse-s3, sse-kms, sse-c)",
+	"aws.invalid_customer_key":    "the SSE-C key must be %d bytes, or their base64 encoding",
+	"aws.customer_key_missing":    "SSE-C needs a key: use --sse-c-key-file or the S3_CLI_SSE_C_KEY environment variable",
+	"aws.customer_key_required":   "%s is encrypted with a customer-provided key (SSE-C): use --sse-c-key-file or the S3_CLI_SSE_C_KEY environment variable",
+	"aws.bucket_name_length":      "invalid bucket name %q: it must be 3 to 63 characters long",
+	"aws.bucket_name_chars":       "invalid bucket name %q: only lowercase letters, digits, dots and hyphens are allowed",
+	"aws.bucket_name_edge":        "invalid bucket name %q: it must begin and end with a letter or digit",
+	"aws.bucket_name_dots":        "invalid bucket name %q: two dots must not be adjacent",
+	"aws.bucket_name_ip":          "invalid bucket name %q: it must not look like an IP address",
+	"aws.bucket_name_reserved":    "invalid bucket name %q: %q is reserved",
+	"aws.delete_failed":           "failed to delete %s (version %s): %s %s",
+	"aws.checksum_mismatch":       "checksum mismatch for %s (%s): expected %s, got %s",
+	"aws.access_denied":           "access denied: %s on %s",
+	"aws.edit_conflict":           "%s/%s was changed on S3 while it was being edited; the edits were not uploaded",
+	"aws.edit_too_large":          "%s is %d bytes; only objects up to %d bytes can be edited",
+	"aws.unknown_setting":         "unknown bucket setting %q",
+	"aws.setting_syntax":          "line %d, column %d: %v",
+	"aws.setting_invalid":         "invalid document: %v",
+	"aws.setting_trailing":        "unexpected data after the JSON document",
+	"aws.setting_required":        "%s: %s is required",
+	"aws.setting_one_of":          "%s: %s must be one of %s",
+	"aws.setting_exclusive":       "%s: specify exactly one of %s and %s",
+	"aws.setting_one_field":       "%s: specify exactly one of %s",
+	"aws.setting_positive":        "%s: %s must be a positive number",
+	"aws.setting_duplicate":       "%s: duplicate %s %q",
+	"aws.setting_too_many":        "%s: at most %d entries are allowed",
+	"aws.setting_no_action":       "%s: at least one action is required (Expiration, Transitions, NoncurrentVersionExpiration, NoncurrentVersionTransitions or AbortIncompleteMultipartUpload)",
+	"aws.tag_key_length":          "%s: the key must be 1 to 128 characters long",
+	"aws.tag_value_length":        "%s: the value must be at most 256 characters long",
+	"aws.tag_reserved":            "%s: keys starting with aws: are reserved (%q)",
+	"aws.policy_unknown_field":    "Policy: unknown field %q (Version, Id or Statement)",
+	"aws.policy_statement_type":   "Policy: Statement must be an object or an array of objects",
+	"aws.policy_string_list":      "%s: %s must be a string or a list of strings",
+
+	// 属性フィルター式
+	"query.unclosed_quote":    "unclosed double quote",
+	"query.unknown_field":     "unknown attribute %q (use size, modified, class or ext)",
+	"query.missing_size":      "no size given",
+	"query.invalid_size":      "cannot parse size %q as a number",
+	"query.invalid_size_unit": "size unit %q is not supported (B, KB, MB, GB, TB)",
+	"query.missing_age":       "no age or date given",
+	"query.age_operator":      "use <, <=, > or >= with an age",
+	"query.invalid_age":       "cannot parse %q as an age (e.g. 7d) or a date (e.g. 2026-10-01)",
+	"query.set_operator":      "operator %q is not allowed (use =, != or :)",
+	"query.missing_value":     "no value given",
+
+	// 一致方式
+	"match.invalid_regex":         "invalid regular expression: %w",
+	"match.invalid_glob":          "invalid glob pattern: %w",
+	"match.glob_unclosed_bracket": "invalid glob pattern: unclosed '['",
+
+	// キー操作
+	"key.up":              "up",
+	"key.down":            "down",
+	"key.page_up":         "prev page",
+	"key.page_down":       "next page",
+	"key.top":             "top",
+	"key.bottom":          "bottom",
+	"key.select":          "select",
+	"key.back":            "back",
+	"key.forward":         "forward",
+	"key.filter":          "filter",
+	"key.match_mode":      "match mode",
+	"key.search":          "search",
+	"key.cancel_search":   "cancel search",
+	"key.help":            "help",
+	"key.bandwidth_up":    "faster",
+	"key.bandwidth_down":  "slower",
+	"key.uploads":         "open uploads",
+	"key.usage":           "disk usage",
+	"key.export":          "export",
+	"key.open_prefix":     "open prefix",
+	"key.cancel_scan":     "cancel scan",
+	"key.rescan":          "scan again",
+	"key.toggle_select":   "select",
+	"key.abort":           "abort",
+	"key.abort_older":     "abort older than…",
+	"key.new_tab":         "new tab",
+	"key.close_tab":       "close tab",
+	"key.next_tab":        "next tab",
+	"key.prev_tab":        "previous tab",
+	"key.logs":            "logs",
+	"key.create_bucket":   "new bucket",
+	"key.delete_bucket":   "delete bucket",
+	"key.settings":        "settings",
+	"key.details":         "details",
+	"key.edit":            "edit",
+	"key.open_with":       "open with…",
+	"key.bookmark":        "bookmark",
+	"key.bookmarks":       "bookmarks",
+	"key.goto":            "go to location",
+	"key.refresh":         "refresh list",
+	"key.quit":            "quit",
+	"key.confirm_filter":  "apply filter",
+	"key.download":        "download",
+	"key.back_to_buckets": "back to buckets",
+
+	// 画面
+	"ui.run_failed":       "error running UI: %w",
+	"ui.error":            "Error: %v",
+	"ui.quit_hint":        "Press Ctrl+C to exit.",
+	"ui.header_profile":   "Profile",
+	"ui.header_endpoint":  "Endpoint",
+	"ui.header_bucket":    "Bucket",
+	"ui.help_title":       "Key bindings",
+	"ui.help_close":       "(%s: close)",
+	"ui.prompt_hint":      "(Enter: confirm, Esc: cancel)",
+	"ui.more_above":       "↑ (more)",
+	"ui.more_below":       "↓ (more)",
+	"ui.no_buckets":       "No buckets match the filter",
+	"ui.no_objects":       "No objects match the filter",
+	"ui.filter_buckets":   "Filter buckets...",
+	"ui.filter_objects":   "Filter objects... (e.g. size>100MB modified<7d ext:csv)",
+	"ui.download_done":    "Downloaded: %s/%s → %s",
+	"ui.download_skipped": "Skipped because a file with the same name exists: %s",
+
+	// ログ画面
+	"ui.logs_title":    "Debug log",
+	"ui.logs_file":     "File: %s",
+	"ui.logs_hint":     "(%s: close, %s/%s: scroll)",
+	"ui.logs_empty":    "No log records yet",
+	"ui.logs_disabled": "Logging is disabled. Start s3-cli with --debug or --log-file to record logs.",
+
+	// 状態表示
+	"status.throttled": "⏳ Throttled by S3 (%s), retrying in %v (attempt %d of %d)",
+	"status.retrying":  "⏳ S3 request failed (%s), retrying in %v (attempt %d of %d)",
+
+	// マルチパートアップロード
+	"mpu.none":          "No incomplete multipart uploads",
+	"mpu.header":        "INITIATED\tPARTS\tSIZE\tUPLOAD ID\tKEY",
+	"mpu.dry_run":       "dry run: nothing was aborted",
+	"mpu.aborted":       "Aborted: %s (%s)",
+	"mpu.aborted_count": "%d aborted, %d kept",
+
+	// マルチパートアップロード一覧
+	"uploads.summary":       "Incomplete multipart uploads: %d (%s), %d selected",
+	"uploads.columns":       "started / parts / size / upload ID",
+	"uploads.loading":       "loading…",
+	"uploads.empty":         "No incomplete multipart uploads",
+	"uploads.confirm_abort": "Abort %d upload(s)? Type y and press Enter",
+	"uploads.older_prompt":  "Abort all uploads started before this age (e.g. 7d, 12h, 0 for all)",
+	"uploads.none_older":    "no uploads older than %s",
+	"uploads.aborted":       "%d upload(s) aborted",
+
+	// 使用量の集計
+	"usage.running":           "📊 Scanning",
+	"usage.canceled":          "📊 Scan canceled (partial totals)",
+	"usage.failed":            "📊 Scan failed (partial totals): %v",
+	"usage.done":              "📊 Scan finished",
+	"usage.totals":            "%s objects, %s",
+	"usage.classes":           "Storage class: %s",
+	"usage.ages":              "Age: %s",
+	"usage.direct":            "(objects directly under this prefix)",
+	"usage.empty":             "No objects",
+	"usage.export_prompt":     "Export this level to a file (.csv for CSV, otherwise JSON)",
+	"usage.exported":          "exported to %s",
+	"usage.nothing_to_export": "nothing has been scanned at this level yet",
+	"usage.unknown_format":    "unknown format %q (text, json, csv)",
+	"usage.header":            "SIZE\tOBJECTS\t%\tPREFIX",
+	"usage.class_header":      "STORAGE CLASS\tSIZE\tOBJECTS\t%",
+	"usage.age_header":        "AGE\tSIZE\tOBJECTS\t%",
+
+	// 整合性の確認
+	"verify.ok":           "OK        %s (%s)",
+	"verify.mismatch":     "MISMATCH  %v",
+	"verify.size":         "SIZE      %s (local %d bytes, S3 %d bytes)",
+	"verify.missing":      "MISSING   %s (not found locally)",
+	"verify.extra":        "EXTRA     %s (not found in S3)",
+	"verify.unverifiable": "NO SUM    %s (size matches, no usable checksum)",
+	"verify.summary":      "%d checked: %d ok, %d mismatched, %d size differs, %d missing, %d extra, %d size-only",
+	"verify.failed":       "%d file(s) do not match",
+
+	// バケットの作成・削除
+	"bucket.list_failed":        "Cannot list buckets; showing the buckets opened before. %s: enter a bucket name",
+	"bucket.create_name":        "New bucket name",
+	"bucket.create_region":      "Region (empty: %s)",
+	"bucket.create_object_lock": "Enable Object Lock? This also enables versioning, permanently (y/N)",
+	"bucket.create_versioning":  "Enable versioning? (y/N)",
+	"bucket.creating":           "Creating bucket %s…",
+	"bucket.created":            "Bucket %s created",
+	"bucket.delete_confirm":     "Type the bucket name %s to delete it",
+	"bucket.name_mismatch":      "the name does not match %s",
+	"bucket.delete_empty":       "Empty it first, deleting every object, version and delete marker? (y/N)",
+	"bucket.deleting":           "Deleting bucket %s…",
+	"bucket.emptied_partially":  "%d object version(s) deleted before the error",
+	"bucket.deleted":            "Bucket %s deleted (%d object version(s) removed)",
+
+	// バケットの設定
+	"settings.title":              "Bucket settings: %s",
+	"settings.hint":               "(%s: edit / toggle versioning, %s: close)",
+	"settings.loading":            "Loading the settings of %s…",
+	"settings.name_versioning":    "Versioning",
+	"settings.name_lifecycle":     "Lifecycle rules",
+	"settings.name_cors":          "CORS",
+	"settings.name_policy":        "Bucket policy",
+	"settings.name_tags":          "Tags",
+	"settings.versioning_enabled": "Enabled",
+	"settings.versioning_suspend": "Suspended",
+	"settings.versioning_off":     "Never enabled",
+	"settings.not_set":            "Not set",
+	"settings.rules":              "%d rule(s)",
+	"settings.statements":         "%d statement(s)",
+	"settings.tags":               "%d tag(s)",
+	"settings.enable_versioning":  "Enable versioning of %s? (y/N)",
+	"settings.suspend_versioning": "Suspend versioning of %s? Existing versions are kept (y/N)",
+	"settings.versioning_done":    "Versioning of %s: %s",
+	"settings.edit_again":         "The edited %s is invalid. Edit it again? (y/N)",
+	"settings.confirm_apply":      "Apply these changes to the %s of %s? (y/N)",
+	"settings.confirm_remove":     "Remove the %s of %s? (y/N)",
+	"settings.diff_title":         "Changes to %s",
+	"settings.diff_more":          "… %d more line(s)",
+	"settings.diff_unchanged":     "⋯ %d unchanged line(s)",
+	"settings.no_changes":         "No changes to %s",
+	"settings.discarded":          "Changes to %s discarded",
+	"settings.applying":           "Applying the %s…",
+	"settings.applied":            "%s updated",
+	"settings.removed":            "%s removed",
+
+	// オブジェクトの編集
+	"edit.fetching":   "Downloading %s for editing…",
+	"edit.saving":     "Uploading the edited %s…",
+	"edit.saved":      "%s saved",
+	"edit.no_changes": "%s was not changed",
+	"edit.kept":       "Your edits are kept in %s",
+
+	// ブックマーク
+	"bookmark.title":     "Bookmarks and recent locations",
+	"bookmark.bookmarks": "Bookmarks",
+	"bookmark.recent":    "Recent",
+	"bookmark.empty":     "No bookmarks or recent locations yet (%s in the object list adds a bookmark)",
+	"bookmark.hint":      "(%s: open, %s: add / remove bookmark, %s: close)",
+	"bookmark.added":     "Bookmarked %s",
+	"bookmark.removed":   "Removed the bookmark %s",
+
+	// 一覧のキャッシュ
+	"listing.refreshing": "Showing the list cached %s ago; refreshing…",
+	"listing.stale":      "Showing the list cached %s ago (%s: refresh)",
+
+	// タブ
+	"tab.new":        "Open in a new tab ([profile@]bucket/prefix/, empty for the bucket list)",
+	"tab.buckets":    "buckets",
+	"tab.uploads":    "uploads: %s",
+	"tab.usage":      "usage: %s",
+	"tab.error_hint": "Press %s to close this tab or Ctrl+C to exit.",
+
+	// 場所の指定
+	"location.goto": "Go to (bucket, bucket/prefix/ or s3://bucket/key)",
+
+	// 外部のプログラムで開く
+	"open.prompt":   "Open %s with ({} is replaced with a temporary file, otherwise the object is piped to stdin; a leading | shows the output in the pager)",
+	"open.fetching": "Opening %s…",
+	"open.failed":   "%s: %w",
+
+	// オブジェクトの詳細
+	"details.title":              "Object details",
+	"details.hint":               "(%s: close)",
+	"details.loading":            "Loading details of %s…",
+	"details.key":                "Key",
+	"details.size":               "Size",
+	"details.modified":           "Last modified",
+	"details.class":              "Storage class",
+	"details.content_type":       "Content type",
+	"details.etag":               "ETag",
+	"details.encryption":         "Encryption",
+	"details.sse_none":           "none",
+	"details.sse_s3":             "SSE-S3 (AES256, S3 managed key)",
+	"details.sse_kms":            "SSE-KMS (key: %s)",
+	"details.sse_kms_bucket_key": "SSE-KMS (key: %s, bucket key)",
+	"details.sse_c":              "SSE-C (customer-provided key)",
+
+	// 転送パネル
+	"transfer.title":     "Transfers (bandwidth limit: %s, %s/%s: change)",
+	"transfer.unlimited": "unlimited",
+
+	// サーバー側検索
+	"search.prefix_prompt":  "Prefix to search (s3://%s/...)",
+	"search.pattern_prompt": "Key pattern [%s] (leave empty to match all)",
+	"search.running":        "🔎 Searching",
+	"search.canceled":       "🔎 Search canceled",
+	"search.failed":         "🔎 Search failed: %v",
+	"search.done":           "🔎 Search finished",
+	"search.counts":         "scanned %s / matched %s",
+	"search.cancel_hint":    "(%s: cancel)",
+}
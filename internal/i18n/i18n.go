@@ -0,0 +1,121 @@
+// Package i18n は画面表示やエラーメッセージの文言を言語ごとのカタログから引きます。
+//
+// 文言はキーで参照し、カタログにはfmtの書式文字列として登録します。
+// 言語は起動時に Set で一度だけ設定する想定です。
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Lang は表示言語です
+type Lang string
+
+const (
+	English  Lang = "en"
+	Japanese Lang = "ja"
+)
+
+// Langs は選択できる言語の一覧です
+var Langs = []Lang{English, Japanese}
+
+// catalogs は言語ごとのメッセージカタログです
+var catalogs = map[Lang]map[string]string{
+	English:  en,
+	Japanese: ja,
+}
+
+// current は現在の表示言語です
+var current = English
+
+// Set は表示言語を設定します
+func Set(lang Lang) {
+	if _, ok := catalogs[lang]; ok {
+		current = lang
+	}
+}
+
+// Current は現在の表示言語を返します
+func Current() Lang {
+	return current
+}
+
+// Parse は "ja" や "ja_JP.UTF-8"、"en-US" のような値を言語に変換します
+func Parse(s string) (Lang, error) {
+	code := strings.ToLower(s)
+	if i := strings.IndexAny(code, "_-.@"); i >= 0 {
+		code = code[:i]
+	}
+	for _, lang := range Langs {
+		if Lang(code) == lang {
+			return lang, nil
+		}
+	}
+	names := make([]string, len(Langs))
+	for i, lang := range Langs {
+		names[i] = string(lang)
+	}
+	return "", Errorf("lang.unknown", s, strings.Join(names, ", "))
+}
+
+// Detect は LC_ALL、LC_MESSAGES、LANG の順に環境変数から言語を決めます。
+// 対応していないロケール（C や POSIX など）の場合は英語を返します。
+func Detect() Lang {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		if lang, err := Parse(value); err == nil {
+			return lang
+		}
+		return English
+	}
+	return English
+}
+
+// Resolve はフラグ・設定ファイル・環境変数から表示言語を決めます。
+// フラグ、設定ファイルの順に優先し、どちらもなければ環境変数から判定します。
+func Resolve(flagValue, configValue string) (Lang, error) {
+	switch {
+	case flagValue != "":
+		return Parse(flagValue)
+	case configValue != "":
+		return Parse(configValue)
+	}
+	return Detect(), nil
+}
+
+// T はキーに対応する現在の言語の文言を返します。argsがあれば書式文字列として展開します。
+func T(key string, args ...any) string {
+	format := lookup(key)
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Errorf はキーに対応する文言からエラーを作成します。書式には %w も使えます。
+func Errorf(key string, args ...any) error {
+	return fmt.Errorf(lookup(key), args...)
+}
+
+// Has はキーがカタログに登録されているかを返します
+func Has(key string) bool {
+	_, ok := en[key]
+	return ok
+}
+
+// lookup は現在の言語の文言を返します。
+// 見つからない場合は英語、それもなければキーそのものを返します。
+func lookup(key string) string {
+	if s, ok := catalogs[current][key]; ok {
+		return s
+	}
+	if s, ok := en[key]; ok {
+		return s
+	}
+	return key
+}
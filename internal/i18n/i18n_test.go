@@ -0,0 +1,164 @@
+package i18n
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// verbPattern は書式文字列の動詞（%s, %q, %w など）に一致します
+var verbPattern = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// TestCatalogsHaveSameKeys はすべてのキーがすべてのカタログに同じ書式で存在することをテストします
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	for _, lang := range Langs {
+		catalog, ok := catalogs[lang]
+		if !ok {
+			t.Fatalf("%s のカタログがありません", lang)
+		}
+		for _, other := range Langs {
+			for key, format := range catalogs[other] {
+				translated, ok := catalog[key]
+				if !ok {
+					t.Errorf("%s のカタログにキー %q がありません", lang, key)
+					continue
+				}
+				expected := strings.Join(verbPattern.FindAllString(format, -1), " ")
+				actual := strings.Join(verbPattern.FindAllString(translated, -1), " ")
+				if expected != actual {
+					t.Errorf("キー %q の書式が一致しません: %s は [%s], %s は [%s]", key, other, expected, lang, actual)
+				}
+			}
+		}
+	}
+}
+
+// callPattern はソースコード中のカタログキーの参照に一致します
+var callPattern = regexp.MustCompile(`i18n\.(?:T|Errorf)\("([^"]+)"`)
+
+// TestSourceKeysExist はソースコードで参照しているキーがカタログに存在することをテストします
+func TestSourceKeysExist(t *testing.T) {
+	root := filepath.Join("..", "..")
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range callPattern.FindAllStringSubmatch(string(data), -1) {
+			if !Has(match[1]) {
+				t.Errorf("%s: キー %q がカタログにありません", path, match[1])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected Lang
+		wantErr  bool
+	}{
+		{input: "en", expected: English},
+		{input: "ja", expected: Japanese},
+		{input: "ja_JP.UTF-8", expected: Japanese},
+		{input: "en-US", expected: English},
+		{input: "JA", expected: Japanese},
+		{input: "fr", wantErr: true},
+		{input: "C", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := Parse(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("エラーが返されませんでした: %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if actual != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// TestResolve は表示言語の優先順位をテストします
+func TestResolve(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "ja_JP.UTF-8")
+
+	testCases := []struct {
+		name        string
+		flagValue   string
+		configValue string
+		expected    Lang
+	}{
+		{name: "環境変数", expected: Japanese},
+		{name: "設定ファイルが環境変数より優先", configValue: "en", expected: English},
+		{name: "フラグが設定ファイルより優先", flagValue: "ja", configValue: "en", expected: Japanese},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := Resolve(tc.flagValue, tc.configValue)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if actual != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, actual)
+			}
+		})
+	}
+
+	if _, err := Resolve("fr", ""); err == nil {
+		t.Error("未対応の言語でエラーが返されませんでした")
+	}
+}
+
+// TestDetect は対応していないロケールで英語になることをテストします
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LANG", "ja_JP.UTF-8")
+	if got := Detect(); got != English {
+		t.Errorf("LC_ALL=C: 期待結果 %v, 実際の結果 %v", English, got)
+	}
+
+	t.Setenv("LC_ALL", "")
+	if got := Detect(); got != Japanese {
+		t.Errorf("LANG=ja_JP.UTF-8: 期待結果 %v, 実際の結果 %v", Japanese, got)
+	}
+}
+
+func TestT(t *testing.T) {
+	defer Set(Current())
+
+	Set(Japanese)
+	if got := T("ui.download_done", "b", "k", "p"); got != "ダウンロード完了: b/k → p" {
+		t.Errorf("日本語: 実際の結果 %q", got)
+	}
+	Set(English)
+	if got := T("ui.download_done", "b", "k", "p"); got != "Downloaded: b/k → p" {
+		t.Errorf("英語: 実際の結果 %q", got)
+	}
+	if got := T("no.such.key"); got != "no.such.key" {
+		t.Errorf("未登録のキーはキーそのものを返すはずです: %q", got)
+	}
+}
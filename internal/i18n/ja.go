@@ -0,0 +1,324 @@
+package i18n
+
+// ja は日本語のメッセージカタログです
+var ja = map[string]string{
+	// 言語
+	"lang.unknown": "不明な言語 %q です（%s のいずれか）",
+
+	// コマンド
+	"cmd.endpoint_required": "--endpoint-url フラグまたは設定ファイルの endpoint は必須です",
+	"cmd.config_missing":    "設定ファイルがありません: %s",
+	"cmd.editor_failed":     "エディタの実行に失敗しました: %w",
+	"cmd.invalid_s3_uri":    "S3のURIではありません（s3://バケット/キー の形式で指定してください）: %s",
+	"cmd.open_twice":        "開く場所は引数か --open のどちらか一方で指定してください",
+	"cmd.upload_done":       "アップロードしました: %s → s3://%s/%s",
+
+	// 設定ファイル
+	"config.home_dir":                "ホームディレクトリを取得できません: %w",
+	"config.read_failed":             "設定ファイルを読み込めません: %w",
+	"config.file_error":              "設定ファイル %s: %w",
+	"config.top_level_not_mapping":   "%d行目: 設定ファイルのトップレベルはキーと値の組である必要があります",
+	"config.unknown_key":             "%d行目: 不明なキー %q です（使えるキー: %s）",
+	"config.invalid_conflict_policy": "conflict_policy %q は使えません（%s のいずれか）",
+	"config.invalid_concurrency":     "concurrency には1以上の値を指定してください: %d",
+	"config.negative_value":          "%s に負の値は指定できません: %v",
+	"config.invalid_choice":          "%s %q は使えません（%s のいずれか）",
+	"config.empty_endpoint":          "endpoints.%s のURLが空です",
+	"config.empty_open_with":         "open_with.%s のコマンドが空です",
+	"state.read_failed":              "状態ファイル %s を読み込めません: %v",
+	"state.invalid_location":         "場所 %q は使えません: s3://bucket/prefix の形式で指定してください",
+	"config.unknown_keybinding":      "keybindings: 不明な操作 %q です（使える操作: %s）",
+	"config.keybinding_without_keys": "keybindings.%s: キーが指定されていません",
+	"config.unknown_theme":           "不明なテーマ %q です（%s のいずれか）",
+
+	// S3
+	"aws.load_config_failed":      "AWS設定の読み込みに失敗しました: %w",
+	"aws.skipped":                 "同名のファイルが存在するためスキップしました",
+	"aws.unknown_conflict_policy": "不明な競合時の動作です: %q（error, skip, overwrite, rename のいずれか）",
+	"aws.unknown_retry_mode":      "不明なリトライ方式です: %q（standard, adaptive のいずれか）",
+	"aws.invalid_bandwidth":       "帯域 %q を解釈できません（20MB/s、512KB/s など。0 で無制限）",
+	"aws.unknown_encryption":      "不明な暗号化方式です: %q（sse-s3, sse-kms, sse-c のいずれか）",
+	"aws.invalid_customer_key":    "SSE-C の鍵は %d バイトか、そのbase64表記で指定してください",
+	"aws.customer_key_missing":    "SSE-C には鍵が必要です: --sse-c-key-file か環境変数 S3_CLI_SSE_C_KEY で指定してください",
+	"aws.customer_key_required":   "%s は利用者が指定した鍵（SSE-C）で暗号化されています: --sse-c-key-file か環境変数 S3_CLI_SSE_C_KEY で鍵を指定してください",
+	"aws.bucket_name_length":      "バケット名 %q は使えません: 3〜63文字にしてください",
+	"aws.bucket_name_chars":       "バケット名 %q は使えません: 英小文字・数字・ドット・ハイフンだけが使えます",
+	"aws.bucket_name_edge":        "バケット名 %q は使えません: 英小文字か数字で始めて終える必要があります",
+	"aws.bucket_name_dots":        "バケット名 %q は使えません: ドットを続けることはできません",
+	"aws.bucket_name_ip":          "バケット名 %q は使えません: IPアドレスの形式は使えません",
+	"aws.bucket_name_reserved":    "バケット名 %q は使えません: %q は予約されています",
+	"aws.delete_failed":           "%s（バージョン %s）を削除できませんでした: %s %s",
+	"aws.checksum_mismatch":       "%s のチェックサムが一致しません（%s）: 期待値 %s, 実際の値 %s",
+	"aws.access_denied":           "権限がありません: %s（%s）",
+	"aws.edit_conflict":           "%s/%s は編集中に S3 上で変更されたため、編集内容をアップロードしませんでした",
+	"aws.edit_too_large":          "%s は %d バイトです。編集できるのは %d バイトまでのオブジェクトです",
+	"aws.unknown_setting":         "不明なバケットの設定です: %q",
+	"aws.setting_syntax":          "%d 行 %d 列: %v",
+	"aws.setting_invalid":         "設定を読み込めません: %v",
+	"aws.setting_trailing":        "JSON の後に余分な内容があります",
+	"aws.setting_required":        "%s: %s は必須です",
+	"aws.setting_one_of":          "%s: %s は %s のいずれかにしてください",
+	"aws.setting_exclusive":       "%s: %s と %s のどちらか一方だけを指定してください",
+	"aws.setting_one_field":       "%s: %s のうち1つだけを指定してください",
+	"aws.setting_positive":        "%s: %s は正の数にしてください",
+	"aws.setting_duplicate":       "%s: %s %q が重複しています",
+	"aws.setting_too_many":        "%s: 指定できるのは %d 件までです",
+	"aws.setting_no_action":       "%s: 動作を1つ以上指定してください（Expiration、Transitions、NoncurrentVersionExpiration、NoncurrentVersionTransitions、AbortIncompleteMultipartUpload）",
+	"aws.tag_key_length":          "%s: キーは1〜128文字にしてください",
+	"aws.tag_value_length":        "%s: 値は256文字以内にしてください",
+	"aws.tag_reserved":            "%s: aws: で始まるキーは予約されています（%q）",
+	"aws.policy_unknown_field":    "Policy: 不明な項目です: %q（Version、Id、Statement）",
+	"aws.policy_statement_type":   "Policy: Statement はオブジェクトかオブジェクトの配列にしてください",
+	"aws.policy_string_list":      "%s: %s は文字列か文字列の配列にしてください",
+	"aws.file_exists":             "ファイルが既に存在します: %s",
+
+	// 属性フィルター式
+	"query.unclosed_quote":    "ダブルクォートが閉じられていません",
+	"query.unknown_field":     "不明な属性 %q です（size, modified, class, ext が使えます）",
+	"query.missing_size":      "サイズが指定されていません",
+	"query.invalid_size":      "サイズ %q を数値として解釈できません",
+	"query.invalid_size_unit": "サイズの単位 %q は使えません（B, KB, MB, GB, TB）",
+	"query.missing_age":       "経過時間または日付が指定されていません",
+	"query.age_operator":      "経過時間には <, <=, >, >= を使ってください",
+	"query.invalid_age":       "%q を経過時間（7d など）または日付（2026-10-01 など）として解釈できません",
+	"query.set_operator":      "演算子 %q は使えません（=, !=, : が使えます）",
+	"query.missing_value":     "値が指定されていません",
+
+	// 一致方式
+	"match.invalid_regex":         "正規表現が不正です: %w",
+	"match.invalid_glob":          "グロブパターンが不正です: %w",
+	"match.glob_unclosed_bracket": "グロブパターンが不正です: '[' が閉じられていません",
+
+	// キー操作
+	"key.up":              "上へ",
+	"key.down":            "下へ",
+	"key.page_up":         "前のページ",
+	"key.page_down":       "次のページ",
+	"key.top":             "先頭へ",
+	"key.bottom":          "末尾へ",
+	"key.select":          "選択",
+	"key.back":            "戻る",
+	"key.forward":         "進む",
+	"key.filter":          "フィルター",
+	"key.match_mode":      "一致方式切替",
+	"key.search":          "検索",
+	"key.cancel_search":   "検索キャンセル",
+	"key.help":            "ヘルプ",
+	"key.bandwidth_up":    "帯域を上げる",
+	"key.bandwidth_down":  "帯域を下げる",
+	"key.uploads":         "未完了一覧",
+	"key.usage":           "使用量",
+	"key.export":          "書き出す",
+	"key.open_prefix":     "プレフィックスを開く",
+	"key.cancel_scan":     "集計を中止",
+	"key.rescan":          "集計し直す",
+	"key.toggle_select":   "選択",
+	"key.abort":           "中止",
+	"key.abort_older":     "古いものを中止",
+	"key.new_tab":         "新しいタブ",
+	"key.close_tab":       "タブを閉じる",
+	"key.next_tab":        "次のタブ",
+	"key.prev_tab":        "前のタブ",
+	"key.logs":            "ログ表示",
+	"key.create_bucket":   "バケット作成",
+	"key.delete_bucket":   "バケット削除",
+	"key.settings":        "バケット設定",
+	"key.details":         "詳細",
+	"key.edit":            "編集",
+	"key.open_with":       "外部で開く",
+	"key.bookmark":        "ブックマーク",
+	"key.bookmarks":       "ブックマーク一覧",
+	"key.goto":            "場所へ移動",
+	"key.refresh":         "一覧を取り直す",
+	"key.quit":            "終了",
+	"key.confirm_filter":  "フィルター確定",
+	"key.download":        "ダウンロード",
+	"key.back_to_buckets": "バケット一覧に戻る",
+
+	// 画面
+	"ui.run_failed":       "UIの実行に失敗しました: %w",
+	"ui.error":            "エラー: %v",
+	"ui.quit_hint":        "Ctrl+Cで終了してください。",
+	"ui.header_profile":   "Profile",
+	"ui.header_endpoint":  "Endpoint",
+	"ui.header_bucket":    "Bucket",
+	"ui.help_title":       "キー操作一覧",
+	"ui.help_close":       "(%s: 閉じる)",
+	"ui.prompt_hint":      "(Enter: 決定, Esc: キャンセル)",
+	"ui.more_above":       "↑ (more)",
+	"ui.more_below":       "↓ (more)",
+	"ui.no_buckets":       "条件に一致するバケットが見つかりません",
+	"ui.no_objects":       "条件に一致するオブジェクトが見つかりません",
+	"ui.filter_buckets":   "バケットを絞り込み...",
+	"ui.filter_objects":   "オブジェクトを絞り込み...（例: size>100MB modified<7d ext:csv）",
+	"ui.download_done":    "ダウンロード完了: %s/%s → %s",
+	"ui.download_skipped": "同名のファイルが存在するためスキップしました: %s",
+
+	// ログ画面
+	"ui.logs_title":    "デバッグログ",
+	"ui.logs_file":     "ファイル: %s",
+	"ui.logs_hint":     "(%s: 閉じる, %s/%s: スクロール)",
+	"ui.logs_empty":    "ログはまだありません",
+	"ui.logs_disabled": "ログは無効です。--debug または --log-file を指定して起動すると記録されます。",
+
+	// 状態表示
+	"status.throttled": "⏳ S3 から流量制限されています（%s）。%v 後に再試行します（%d/%d 回目）",
+	"status.retrying":  "⏳ S3 の呼び出しに失敗しました（%s）。%v 後に再試行します（%d/%d 回目）",
+
+	// マルチパートアップロード
+	"mpu.none":          "完了していないマルチパートアップロードはありません",
+	"mpu.header":        "開始日時\tパート数\tサイズ\tアップロードID\tキー",
+	"mpu.dry_run":       "ドライラン: 中止していません",
+	"mpu.aborted":       "中止しました: %s (%s)",
+	"mpu.aborted_count": "%d 件を中止し、%d 件を残しました",
+
+	// マルチパートアップロード一覧
+	"uploads.summary":       "完了していないマルチパートアップロード: %d 件 (%s), %d 件選択中",
+	"uploads.columns":       "開始日時 / パート数 / サイズ / アップロードID",
+	"uploads.loading":       "読み込み中…",
+	"uploads.empty":         "完了していないマルチパートアップロードはありません",
+	"uploads.confirm_abort": "%d 件のアップロードを中止しますか？ y を入力して Enter を押してください",
+	"uploads.older_prompt":  "この経過時間より前に開始したアップロードをすべて中止します（例: 7d, 12h, 0 はすべて）",
+	"uploads.none_older":    "%s より古いアップロードはありません",
+	"uploads.aborted":       "%d 件のアップロードを中止しました",
+
+	// 使用量の集計
+	"usage.running":           "📊 集計中",
+	"usage.canceled":          "📊 集計を中止しました（途中までの合計）",
+	"usage.failed":            "📊 集計に失敗しました（途中までの合計）: %v",
+	"usage.done":              "📊 集計が終わりました",
+	"usage.totals":            "%s 個, %s",
+	"usage.classes":           "ストレージクラス: %s",
+	"usage.ages":              "経過時間: %s",
+	"usage.direct":            "（このプレフィックス直下のオブジェクト）",
+	"usage.empty":             "オブジェクトはありません",
+	"usage.export_prompt":     "この階層の集計をファイルに書き出します（.csv は CSV、それ以外は JSON）",
+	"usage.exported":          "%s に書き出しました",
+	"usage.nothing_to_export": "この階層はまだ集計していません",
+	"usage.unknown_format":    "不明な形式です: %q（text, json, csv）",
+	"usage.header":            "サイズ\tオブジェクト数\t%\tプレフィックス",
+	"usage.class_header":      "ストレージクラス\tサイズ\tオブジェクト数\t%",
+	"usage.age_header":        "経過時間\tサイズ\tオブジェクト数\t%",
+
+	// 整合性の確認
+	"verify.ok":           "一致        %s（%s）",
+	"verify.mismatch":     "不一致      %v",
+	"verify.size":         "サイズ違い  %s（ローカル %d バイト, S3 %d バイト）",
+	"verify.missing":      "ローカルなし %s",
+	"verify.extra":        "S3になし    %s",
+	"verify.unverifiable": "サイズのみ  %s（サイズは一致、使えるチェックサムなし）",
+	"verify.summary":      "%d 件を確認: 一致 %d, 不一致 %d, サイズ違い %d, ローカルなし %d, S3になし %d, サイズのみ %d",
+	"verify.failed":       "%d 件のファイルが一致しません",
+
+	// バケットの作成・削除
+	"bucket.list_failed":        "バケット一覧を取得できないため、以前に開いたバケットを表示しています。%s: バケット名を入力",
+	"bucket.create_name":        "作成するバケットの名前",
+	"bucket.create_region":      "リージョン（空欄: %s）",
+	"bucket.create_object_lock": "オブジェクトロックを有効にしますか？ バージョニングも有効になり、無効にできません (y/N)",
+	"bucket.create_versioning":  "バージョニングを有効にしますか？ (y/N)",
+	"bucket.creating":           "バケット %s を作成しています…",
+	"bucket.created":            "バケット %s を作成しました",
+	"bucket.delete_confirm":     "削除するにはバケット名 %s を入力してください",
+	"bucket.name_mismatch":      "%s と一致しません",
+	"bucket.delete_empty":       "先に空にしますか？ すべてのオブジェクト・バージョン・削除マーカーを削除します (y/N)",
+	"bucket.deleting":           "バケット %s を削除しています…",
+	"bucket.emptied_partially":  "エラーの前に %d 件のバージョンを削除しました",
+	"bucket.deleted":            "バケット %s を削除しました（%d 件のバージョンを削除）",
+
+	// バケットの設定
+	"settings.title":              "バケットの設定: %s",
+	"settings.hint":               "（%s: 編集・バージョニングの切り替え, %s: 閉じる）",
+	"settings.loading":            "%s の設定を取得しています…",
+	"settings.name_versioning":    "バージョニング",
+	"settings.name_lifecycle":     "ライフサイクルルール",
+	"settings.name_cors":          "CORS",
+	"settings.name_policy":        "バケットポリシー",
+	"settings.name_tags":          "タグ",
+	"settings.versioning_enabled": "有効",
+	"settings.versioning_suspend": "停止中",
+	"settings.versioning_off":     "未設定",
+	"settings.not_set":            "未設定",
+	"settings.rules":              "%d 件のルール",
+	"settings.statements":         "%d 件のステートメント",
+	"settings.tags":               "%d 件のタグ",
+	"settings.enable_versioning":  "%s のバージョニングを有効にしますか？ (y/N)",
+	"settings.suspend_versioning": "%s のバージョニングを停止しますか？ 既存のバージョンは残ります (y/N)",
+	"settings.versioning_done":    "%s のバージョニング: %s",
+	"settings.edit_again":         "編集した%sが不正です。もう一度編集しますか？ (y/N)",
+	"settings.confirm_apply":      "この%sの変更をバケット %s に反映しますか？ (y/N)",
+	"settings.confirm_remove":     "%sをバケット %s から削除しますか？ (y/N)",
+	"settings.diff_title":         "%sの変更",
+	"settings.diff_more":          "… ほか %d 行",
+	"settings.diff_unchanged":     "⋯ 変更のない %d 行",
+	"settings.no_changes":         "%sは変更されていません",
+	"settings.discarded":          "%sの変更を破棄しました",
+	"settings.applying":           "%sを反映しています…",
+	"settings.applied":            "%sを更新しました",
+	"settings.removed":            "%sを削除しました",
+
+	// オブジェクトの編集
+	"edit.fetching":   "%s を編集のためにダウンロードしています…",
+	"edit.saving":     "編集した %s をアップロードしています…",
+	"edit.saved":      "%s を保存しました",
+	"edit.no_changes": "%s は変更されていません",
+	"edit.kept":       "編集内容は %s に残してあります",
+
+	// ブックマーク
+	"bookmark.title":     "ブックマークと最近開いた場所",
+	"bookmark.bookmarks": "ブックマーク",
+	"bookmark.recent":    "最近開いた場所",
+	"bookmark.empty":     "ブックマークも最近開いた場所もまだありません（オブジェクト一覧で %s を押すと登録します）",
+	"bookmark.hint":      "（%s: 開く, %s: ブックマークの登録・削除, %s: 閉じる）",
+	"bookmark.added":     "%s をブックマークしました",
+	"bookmark.removed":   "%s のブックマークを削除しました",
+
+	// 一覧のキャッシュ
+	"listing.refreshing": "%s前に取得した一覧を表示しています。取り直しています…",
+	"listing.stale":      "%s前に取得した一覧を表示しています（%s: 取り直す）",
+
+	// タブ
+	"tab.new":        "新しいタブで開く場所（[プロファイル@]バケット/プレフィックス/、空の場合はバケット一覧）",
+	"tab.buckets":    "バケット一覧",
+	"tab.uploads":    "アップロード: %s",
+	"tab.usage":      "使用量: %s",
+	"tab.error_hint": "%sでこのタブを閉じるか、Ctrl+Cで終了してください。",
+
+	// 場所の指定
+	"location.goto": "移動先（バケット、バケット/プレフィックス/ または s3://バケット/キー）",
+
+	// 外部のプログラムで開く
+	"open.prompt":   "%s を開くコマンド（{} は一時ファイルに置き換え、なければ標準入力に流します。先頭の | で出力をページャーに表示します）",
+	"open.fetching": "%s を開いています…",
+	"open.failed":   "%s: %w",
+
+	// オブジェクトの詳細
+	"details.title":              "オブジェクトの詳細",
+	"details.hint":               "（%s: 閉じる）",
+	"details.loading":            "%s の詳細を取得しています…",
+	"details.key":                "キー",
+	"details.size":               "サイズ",
+	"details.modified":           "更新日時",
+	"details.class":              "ストレージクラス",
+	"details.content_type":       "コンテンツタイプ",
+	"details.etag":               "ETag",
+	"details.encryption":         "暗号化",
+	"details.sse_none":           "なし",
+	"details.sse_s3":             "SSE-S3（AES256, S3が管理する鍵）",
+	"details.sse_kms":            "SSE-KMS（鍵: %s）",
+	"details.sse_kms_bucket_key": "SSE-KMS（鍵: %s, バケットキー）",
+	"details.sse_c":              "SSE-C（利用者が指定した鍵）",
+
+	// 転送パネル
+	"transfer.title":     "転送（帯域制限: %s, %s/%s: 変更）",
+	"transfer.unlimited": "無制限",
+
+	// サーバー側検索
+	"search.prefix_prompt":  "検索するプレフィックス (s3://%s/...)",
+	"search.pattern_prompt": "キーの検索パターン [%s]（空欄ですべて）",
+	"search.running":        "🔎 検索中",
+	"search.canceled":       "🔎 検索をキャンセルしました",
+	"search.failed":         "🔎 検索エラー: %v",
+	"search.done":           "🔎 検索完了",
+	"search.counts":         "スキャン %s 件 / 一致 %s 件",
+	"search.cancel_hint":    "(%s: キャンセル)",
+}
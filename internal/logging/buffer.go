@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"strings"
+	"sync"
+)
+
+// Buffer は最近書き込まれた行を決まった数だけ保持する io.Writer です
+type Buffer struct {
+	mu    sync.Mutex
+	lines []string
+	next  int // 次に書き込む位置（満杯になってからの循環用）
+	full  bool
+}
+
+// NewBuffer は最大size行を保持するBufferを作成します
+func NewBuffer(size int) *Buffer {
+	return &Buffer{lines: make([]string, size)}
+}
+
+// Write は書き込まれた内容を行に分けて保持します
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		b.lines[b.next] = line
+		b.next = (b.next + 1) % len(b.lines)
+		if b.next == 0 {
+			b.full = true
+		}
+	}
+	return len(p), nil
+}
+
+// Lines は保持している行を古い順に返します
+func (b *Buffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.full {
+		return append([]string(nil), b.lines[:b.next]...)
+	}
+	return append(append([]string(nil), b.lines[b.next:]...), b.lines[:b.next]...)
+}
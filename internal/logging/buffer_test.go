@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestBuffer(t *testing.T) {
+	testCases := []struct {
+		name     string
+		writes   []string
+		expected []string
+	}{
+		{name: "空", writes: nil, expected: nil},
+		{name: "容量未満", writes: []string{"a\n", "b\n"}, expected: []string{"a", "b"}},
+		{name: "複数行の書き込み", writes: []string{"a\nb\n"}, expected: []string{"a", "b"}},
+		{name: "古い行から捨てる", writes: []string{"a\n", "b\n", "c\n", "d\n", "e\n"}, expected: []string{"c", "d", "e"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBuffer(3)
+			for _, w := range tc.writes {
+				fmt.Fprint(b, w)
+			}
+			actual := b.Lines()
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Package logging は --debug / --log-file で有効になる構造化ログを設定します。
+//
+// ログは log/slog のテキスト形式でファイルに書き出し、同時にTUIのログ表示用に
+// 最近のレコードをメモリに保持します。どちらのフラグもなければ何も出力しません。
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// Options はログの設定です
+type Options struct {
+	// Debug はデバッグレベルのログ（SDKのリクエスト単位のトレースを含む）を有効にします
+	Debug bool
+	// File はログの出力先です。空でDebugが有効な場合は DefaultPath を使います
+	File string
+}
+
+// Logger はアプリケーションのロガーです。無効な場合はすべてのレコードを捨てます。
+type Logger struct {
+	*slog.Logger
+	// Path はログファイルのパスです（無効な場合は空）
+	Path string
+	// Recent はTUIで表示する最近のログです（無効な場合はnil）
+	Recent *Buffer
+	file   *os.File
+}
+
+// recentLines はTUIのログ表示用に保持する行数です
+const recentLines = 1000
+
+// New は設定に従ってロガーを作成します
+func New(opts Options) (*Logger, error) {
+	if !opts.Debug && opts.File == "" {
+		return Disabled(), nil
+	}
+
+	path := opts.File
+	flag := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return nil, err
+		}
+		// 既定のファイルは起動ごとに作り直す
+		flag = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	// 他のユーザーから読めないよう、所有者だけが読み書きできるファイルにする
+	file, err := os.OpenFile(path, flag, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	level := slog.LevelInfo
+	if opts.Debug {
+		level = slog.LevelDebug
+	}
+	recent := NewBuffer(recentLines)
+	handler := slog.NewTextHandler(io.MultiWriter(file, recent), &slog.HandlerOptions{Level: level})
+	return &Logger{
+		Logger: slog.New(handler),
+		Path:   path,
+		Recent: recent,
+		file:   file,
+	}, nil
+}
+
+// Disabled は何も出力しないロガーを返します
+func Disabled() *Logger {
+	return &Logger{Logger: slog.New(discardHandler{})}
+}
+
+// DefaultPath は --debug だけが指定された場合のログファイルのパスを返します。
+// ユーザーごとのキャッシュディレクトリの下に置きます（例: ~/.cache/s3-cli/debug.log）。
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "s3-cli", "debug.log"), nil
+}
+
+// Enabled はログが有効かを返します
+func (l *Logger) Enabled() bool {
+	return l.file != nil
+}
+
+// Close はログファイルを閉じます
+func (l *Logger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// discardHandler はすべてのレコードを捨てるハンドラーです
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
@@ -1,18 +1,47 @@
 package model
 
+import (
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/usage"
+)
+
 // BucketListModel represents the model for the bucket list view
 type BucketListModel struct {
 	Buckets         []string
 	FilteredBuckets []string
+	MatchPositions  [][]int // FilteredBucketsと同じ順序で、フィルターに一致した文字の位置
 	Cursor          int
+	Offset          int // 表示している先頭の項目の位置
 	Filter          string
 }
 
 // ObjectListModel represents the model for the object list view
 type ObjectListModel struct {
 	BucketName      string
+	Prefix          string // 一覧しているプレフィックス（空の場合はバケット全体）
 	Objects         []string
+	Details         map[string]aws.ObjectInfo // キーごとのオブジェクト属性
 	FilteredObjects []string
+	MatchPositions  [][]int // FilteredObjectsと同じ順序で、フィルターに一致した文字の位置
 	Cursor          int
+	Offset          int // 表示している先頭の項目の位置
 	Filter          string
 }
+
+// UploadListModel represents the model for the incomplete multipart upload view
+type UploadListModel struct {
+	BucketName string
+	Uploads    []aws.MultipartUpload
+	Selected   map[string]bool // 選択中のアップロードID
+	Cursor     int
+}
+
+// UsageListModel represents the model for the usage analyzer view
+type UsageListModel struct {
+	BucketName string
+	Tree       *usage.Tree // 集計（走査中は途中までの集計）
+	Path       []string    // 根から開いている階層までのプレフィックス。最後が表示中の階層
+	Cursors    []int       // 子の階層を開いたときの、それぞれの親の階層でのカーソル位置
+	Cursor     int
+	Offset     int // 表示している先頭の項目の位置
+}
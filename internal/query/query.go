@@ -0,0 +1,444 @@
+// Package query はオブジェクト一覧のフィルター欄で使う属性条件式を解析・評価します。
+//
+// 式は空白区切りの語の並びで、次の属性条件を書けます。
+//
+//	size>100MB        サイズ（B, KB, MB, GB, TB。KiBなどの表記も可。1KB=1024B）
+//	modified<7d       更新からの経過時間（s, m, h, d, w, y）
+//	modified>=2026-10-01  更新日時（YYYY-MM-DD または RFC3339）
+//	class=GLACIER     ストレージクラス（カンマ区切りで複数指定可）
+//	ext:csv           拡張子（カンマ区切りで複数指定可）
+//
+// 属性条件以外の語はテキスト条件としてまとめられ、キー名の一致判定に使われます。
+// 正規表現やglobのパターンと組み合わせる場合は、既知の属性条件だけを取り出す Extract を使います。
+package query
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// ParseError は式の解析エラーです
+type ParseError struct {
+	Term string // エラーになった語
+	Msg  string
+}
+
+// Error はエラーメッセージを返します
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%q: %s", e.Term, e.Msg)
+}
+
+// Expr は解析済みの式です
+type Expr struct {
+	// Text は属性条件以外の語を空白で連結したものです
+	Text  string
+	conds []condition
+}
+
+// HasConditions は属性条件が1つ以上あるかを返します
+func (e *Expr) HasConditions() bool {
+	return len(e.conds) > 0
+}
+
+// Match はオブジェクトがすべての属性条件を満たすかを返します（テキスト条件は判定しません）
+func (e *Expr) Match(obj aws.ObjectInfo, now time.Time) bool {
+	for _, c := range e.conds {
+		if !c.match(obj, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// operator は比較演算子です
+type operator string
+
+const (
+	opLess         operator = "<"
+	opLessEqual    operator = "<="
+	opGreater      operator = ">"
+	opGreaterEqual operator = ">="
+	opEqual        operator = "="
+	opNotEqual     operator = "!="
+	opColon        operator = ":"
+)
+
+// operators は解析時に試す順序で並べた演算子です（長いものを先に試す）
+var operators = []operator{opLessEqual, opGreaterEqual, opNotEqual, opLess, opGreater, opEqual, opColon}
+
+// condition は1つの属性条件です
+type condition interface {
+	match(obj aws.ObjectInfo, now time.Time) bool
+}
+
+// Parse は式を解析します
+func Parse(input string) (*Expr, error) {
+	terms, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	expr := &Expr{}
+	var text []string
+	for _, term := range terms {
+		if term.quoted {
+			text = append(text, term.value)
+			continue
+		}
+		cond, ok, err := parseCondition(term.value)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			expr.conds = append(expr.conds, cond)
+		} else {
+			text = append(text, term.value)
+		}
+	}
+	expr.Text = strings.Join(text, " ")
+	return expr, nil
+}
+
+// Extract は入力から既知の属性名の属性条件だけを取り出し、それ以外は空白やクォートも含めてそのまま Text に残します。
+// 正規表現やglobのパターンは空白や '"'、"a<b" のような語を含むことがあるため、Parse の代わりに使います。
+func Extract(input string) (*Expr, error) {
+	expr := &Expr{}
+	var text strings.Builder
+	rest := input
+	removed := false // 直前の語を属性条件として取り除いたか
+	for rest != "" {
+		i := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsSpace(r) })
+		if i < 0 {
+			// 末尾の空白は、直前の語を残した場合だけ残す
+			if !removed {
+				text.WriteString(rest)
+			}
+			break
+		}
+		sep := rest[:i]
+		rest = rest[i:]
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+		word := rest[:end]
+		rest = rest[end:]
+
+		if isField(word) {
+			cond, _, err := parseCondition(word)
+			if err != nil {
+				return nil, err
+			}
+			expr.conds = append(expr.conds, cond)
+			removed = true
+			continue
+		}
+		if text.Len() == 0 && len(expr.conds) > 0 {
+			// 取り除いた属性条件の後ろの空白は、パターンの先頭に残さない
+			sep = ""
+		}
+		text.WriteString(sep)
+		text.WriteString(word)
+		removed = false
+	}
+	expr.Text = text.String()
+	return expr, nil
+}
+
+// token は字句解析した1語です
+type token struct {
+	value  string
+	quoted bool
+}
+
+// tokenize は入力を空白で区切ります。ダブルクォートで囲んだ部分は1語として扱います。
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	var sb strings.Builder
+	inQuote, quoted := false, false
+	flush := func() {
+		if sb.Len() > 0 || quoted {
+			tokens = append(tokens, token{value: sb.String(), quoted: quoted})
+		}
+		sb.Reset()
+		quoted = false
+	}
+	for _, r := range input {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			quoted = true
+		case unicode.IsSpace(r) && !inQuote:
+			flush()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, &ParseError{Term: input, Msg: i18n.T("query.unclosed_quote")}
+	}
+	flush()
+	return tokens, nil
+}
+
+// parseCondition は1語を属性条件として解析します。
+// 属性条件でない語の場合は ok=false を返します。
+func parseCondition(term string) (condition, bool, error) {
+	field, op, value, found := splitTerm(term)
+	if !found {
+		return nil, false, nil
+	}
+
+	switch strings.ToLower(field) {
+	case "size":
+		return parseSizeCondition(term, op, value)
+	case "modified", "mtime":
+		return parseModifiedCondition(term, op, value)
+	case "class":
+		return parseSetCondition(term, op, value, func(obj aws.ObjectInfo) string {
+			return obj.StorageClass
+		})
+	case "ext":
+		return parseSetCondition(term, op, strings.TrimPrefix(value, "."), func(obj aws.ObjectInfo) string {
+			return strings.TrimPrefix(path.Ext(obj.Key), ".")
+		})
+	}
+
+	// キー名には "date=2026-10-01" のような "=" や ":" がよく含まれるため、
+	// 未知の属性名は大小比較の演算子の場合だけエラーにする
+	if op == opEqual || op == opColon {
+		return nil, false, nil
+	}
+	return nil, false, &ParseError{Term: term, Msg: i18n.T("query.unknown_field", field)}
+}
+
+// isField は語が既知の属性名の属性条件の形をしているかを返します
+func isField(term string) bool {
+	field, _, _, found := splitTerm(term)
+	if !found {
+		return false
+	}
+	switch strings.ToLower(field) {
+	case "size", "modified", "mtime", "class", "ext":
+		return true
+	}
+	return false
+}
+
+// splitTerm は "field op value" の形式の語を分割します
+func splitTerm(term string) (string, operator, string, bool) {
+	i := strings.IndexFunc(term, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if i <= 0 {
+		return "", "", "", false
+	}
+	rest := term[i:]
+	for _, op := range operators {
+		if strings.HasPrefix(rest, string(op)) {
+			return term[:i], op, rest[len(op):], true
+		}
+	}
+	return "", "", "", false
+}
+
+// sizeCondition はサイズの条件です
+type sizeCondition struct {
+	op    operator
+	bytes int64
+}
+
+func (c sizeCondition) match(obj aws.ObjectInfo, _ time.Time) bool {
+	return compare(c.op, obj.Size, c.bytes)
+}
+
+func parseSizeCondition(term string, op operator, value string) (condition, bool, error) {
+	if op == opColon {
+		op = opEqual
+	}
+	if value == "" {
+		return nil, false, &ParseError{Term: term, Msg: i18n.T("query.missing_size")}
+	}
+	bytes, err := ParseSize(value)
+	if err != nil {
+		return nil, false, &ParseError{Term: term, Msg: err.Error()}
+	}
+	return sizeCondition{op: op, bytes: bytes}, true, nil
+}
+
+// sizeUnits は単位ごとのバイト数です
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1 << 10,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1 << 20,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1 << 30,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1 << 40,
+	"tib": 1 << 40,
+}
+
+// ParseSize は "100MB" や "1.5GiB" のようなサイズ表記をバイト数に変換します
+func ParseSize(value string) (int64, error) {
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if i < 0 {
+		i = len(value)
+	}
+	num, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil || i == 0 {
+		return 0, i18n.Errorf("query.invalid_size", value)
+	}
+	unit, ok := sizeUnits[strings.ToLower(value[i:])]
+	if !ok {
+		return 0, i18n.Errorf("query.invalid_size_unit", value[i:])
+	}
+	return int64(math.Round(num * unit)), nil
+}
+
+// ageCondition は更新からの経過時間の条件です
+type ageCondition struct {
+	op  operator
+	age time.Duration
+}
+
+func (c ageCondition) match(obj aws.ObjectInfo, now time.Time) bool {
+	return compare(c.op, now.Sub(obj.LastModified), c.age)
+}
+
+// timeCondition は更新日時の条件です
+type timeCondition struct {
+	op      operator
+	t       time.Time
+	dateEnd time.Time // 日付のみ指定された場合のその日の終わり（"=" 用）
+}
+
+func (c timeCondition) match(obj aws.ObjectInfo, _ time.Time) bool {
+	if !c.dateEnd.IsZero() {
+		// 日付のみの指定では、その日全体を1つの値として比較する
+		switch c.op {
+		case opEqual:
+			return !obj.LastModified.Before(c.t) && obj.LastModified.Before(c.dateEnd)
+		case opNotEqual:
+			return obj.LastModified.Before(c.t) || !obj.LastModified.Before(c.dateEnd)
+		case opGreater:
+			return !obj.LastModified.Before(c.dateEnd)
+		case opLessEqual:
+			return obj.LastModified.Before(c.dateEnd)
+		}
+	}
+	return compare(c.op, obj.LastModified.UnixNano(), c.t.UnixNano())
+}
+
+func parseModifiedCondition(term string, op operator, value string) (condition, bool, error) {
+	if op == opColon {
+		op = opEqual
+	}
+	if value == "" {
+		return nil, false, &ParseError{Term: term, Msg: i18n.T("query.missing_age")}
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return timeCondition{op: op, t: t}, true, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return timeCondition{op: op, t: t, dateEnd: t.AddDate(0, 0, 1)}, true, nil
+	}
+
+	age, err := ParseAge(value)
+	if err != nil {
+		return nil, false, &ParseError{Term: term, Msg: err.Error()}
+	}
+	if op == opEqual || op == opNotEqual {
+		return nil, false, &ParseError{Term: term, Msg: i18n.T("query.age_operator")}
+	}
+	return ageCondition{op: op, age: age}, true, nil
+}
+
+// ageUnits は経過時間の単位です
+var ageUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+	"y": 365 * 24 * time.Hour,
+}
+
+// ParseAge は "7d" や "12h" のような経過時間の表記を変換します
+func ParseAge(value string) (time.Duration, error) {
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if i <= 0 {
+		return 0, i18n.Errorf("query.invalid_age", value)
+	}
+	num, err := strconv.ParseFloat(value[:i], 64)
+	unit, ok := ageUnits[strings.ToLower(value[i:])]
+	if err != nil || !ok {
+		return 0, i18n.Errorf("query.invalid_age", value)
+	}
+	return time.Duration(num * float64(unit)), nil
+}
+
+// setCondition は文字列の集合に含まれるかどうかの条件です（大文字小文字無視）
+type setCondition struct {
+	negate bool
+	values []string
+	get    func(obj aws.ObjectInfo) string
+}
+
+func (c setCondition) match(obj aws.ObjectInfo, _ time.Time) bool {
+	actual := c.get(obj)
+	for _, v := range c.values {
+		if strings.EqualFold(actual, v) {
+			return !c.negate
+		}
+	}
+	return c.negate
+}
+
+func parseSetCondition(term string, op operator, value string, get func(obj aws.ObjectInfo) string) (condition, bool, error) {
+	switch op {
+	case opEqual, opColon, opNotEqual:
+	default:
+		return nil, false, &ParseError{Term: term, Msg: i18n.T("query.set_operator", op)}
+	}
+	if value == "" {
+		return nil, false, &ParseError{Term: term, Msg: i18n.T("query.missing_value")}
+	}
+	return setCondition{negate: op == opNotEqual, values: strings.Split(value, ","), get: get}, true, nil
+}
+
+// compare は演算子に従って2つの値を比較します
+func compare[T int64 | time.Duration](op operator, actual, expected T) bool {
+	switch op {
+	case opLess:
+		return actual < expected
+	case opLessEqual:
+		return actual <= expected
+	case opGreater:
+		return actual > expected
+	case opGreaterEqual:
+		return actual >= expected
+	case opNotEqual:
+		return actual != expected
+	default:
+		return actual == expected
+	}
+}
@@ -0,0 +1,181 @@
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+)
+
+func TestParseSize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected int64
+		wantErr  bool
+	}{
+		{name: "単位なし", value: "512", expected: 512},
+		{name: "KB", value: "2KB", expected: 2048},
+		{name: "MB（小文字）", value: "100mb", expected: 100 << 20},
+		{name: "小数", value: "1.5GiB", expected: 3 << 29},
+		{name: "不明な単位", value: "10XB", wantErr: true},
+		{name: "数値なし", value: "MB", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseSize(tc.value)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("エラーが返されませんでした（結果 %d）", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("期待結果 %d, 実際の結果 %d", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		text     string
+		hasConds bool
+	}{
+		{name: "テキストのみ", input: "report", text: "report"},
+		{name: "属性条件とテキスト", input: "size>1MB logs ext:csv", text: "logs", hasConds: true},
+		{name: "パーティションキーはテキスト扱い", input: "date=2026-10-01", text: "date=2026-10-01"},
+		{name: "クォートした語はテキスト扱い", input: `"size>1MB" x`, text: "size>1MB x"},
+		{name: "空の入力", input: "", text: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := Parse(tc.input)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if expr.Text != tc.text {
+				t.Errorf("テキスト条件: 期待結果 %q, 実際の結果 %q", tc.text, expr.Text)
+			}
+			if expr.HasConditions() != tc.hasConds {
+				t.Errorf("属性条件の有無: 期待結果 %v, 実際の結果 %v", tc.hasConds, expr.HasConditions())
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		text     string
+		hasConds bool
+		wantErr  bool
+	}{
+		{name: "パターンのみ", input: `^logs/a  b"c`, text: `^logs/a  b"c`},
+		{name: "属性条件を取り出す", input: `size>1MB ^logs/.*\.csv$ ext:csv`, text: `^logs/.*\.csv$`, hasConds: true},
+		{name: "間の属性条件", input: "a  size>1MB b", text: "a b", hasConds: true},
+		{name: "未知の属性名はパターン扱い", input: "a<b x=y", text: "a<b x=y"},
+		{name: "既知の属性名の不正な値", input: "size>10XB", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := Extract(tc.input)
+			if tc.wantErr {
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Errorf("ParseErrorが返されませんでした: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if expr.Text != tc.text {
+				t.Errorf("テキスト条件: 期待結果 %q, 実際の結果 %q", tc.text, expr.Text)
+			}
+			if expr.HasConditions() != tc.hasConds {
+				t.Errorf("属性条件の有無: 期待結果 %v, 実際の結果 %v", tc.hasConds, expr.HasConditions())
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	inputs := []string{
+		"size>",
+		"size>10XB",
+		"modified<soon",
+		"modified=7d",
+		"class>GLACIER",
+		"ext<csv",
+		"sise>1MB",
+		`"unterminated`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			_, err := Parse(input)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Errorf("ParseErrorが返されませんでした: %v", err)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local)
+	objects := []aws.ObjectInfo{
+		{Key: "logs/a.csv", Size: 200 << 20, LastModified: now.Add(-2 * 24 * time.Hour), StorageClass: "STANDARD"},
+		{Key: "logs/b.csv", Size: 10 << 20, LastModified: now.Add(-30 * 24 * time.Hour), StorageClass: "GLACIER"},
+		{Key: "data/c.parquet", Size: 500 << 20, LastModified: now.Add(-1 * time.Hour), StorageClass: "GLACIER"},
+	}
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{name: "サイズ", input: "size>100MB", expected: []string{"logs/a.csv", "data/c.parquet"}},
+		{name: "経過時間", input: "modified<7d", expected: []string{"logs/a.csv", "data/c.parquet"}},
+		{name: "日付", input: "modified<2026-10-01", expected: []string{"logs/b.csv"}},
+		{name: "日付の一致", input: "modified=2026-10-19", expected: []string{"data/c.parquet"}},
+		{name: "ストレージクラス", input: "class=glacier", expected: []string{"logs/b.csv", "data/c.parquet"}},
+		{name: "ストレージクラスの否定", input: "class!=GLACIER", expected: []string{"logs/a.csv"}},
+		{name: "拡張子の複数指定", input: "ext:csv,tsv", expected: []string{"logs/a.csv", "logs/b.csv"}},
+		{name: "組み合わせ", input: "size>100MB modified<7d class=GLACIER ext:parquet", expected: []string{"data/c.parquet"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := Parse(tc.input)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			var actual []string
+			for _, obj := range objects {
+				if expr.Match(obj, now) {
+					actual = append(actual, obj.Key)
+				}
+			}
+			if len(actual) != len(tc.expected) {
+				t.Fatalf("期待結果 %v, 実際の結果 %v", tc.expected, actual)
+			}
+			for i := range actual {
+				if actual[i] != tc.expected[i] {
+					t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+// Package state は s3-cli の使用状況（ブックマーク・最近開いた場所・開けたバケット）を保存します。
+// 設定ファイルと違い、利用者が手で編集することは想定していません。
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// MaxRecent は最近開いた場所として記録する数の上限です
+const MaxRecent = 20
+
+// uriScheme は場所を表すURIのスキームです
+const uriScheme = "s3://"
+
+// Location はバケットとプレフィックスの組で表す場所です
+type Location struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// String は場所を s3://bucket/prefix の形式で返します
+func (l Location) String() string {
+	return uriScheme + l.Bucket + "/" + l.Prefix
+}
+
+// ParseLocation は s3://bucket/prefix の形式の文字列を場所に変換します。プレフィックスは省略できます
+func ParseLocation(uri string) (Location, error) {
+	rest, ok := strings.CutPrefix(uri, uriScheme)
+	if !ok {
+		return Location{}, i18n.Errorf("state.invalid_location", uri)
+	}
+	bucket, prefix, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return Location{}, i18n.Errorf("state.invalid_location", uri)
+	}
+	return Location{Bucket: bucket, Prefix: prefix}, nil
+}
+
+// scopeData はプロファイルとエンドポイントの組ごとに保存する内容です
+type scopeData struct {
+	Bookmarks []Location `json:"bookmarks,omitempty"`
+	Recent    []Location `json:"recent,omitempty"`
+	// Buckets は開けたことのあるバケットです。ListBuckets が許可されていない場合に一覧の代わりに使います
+	Buckets []string `json:"buckets,omitempty"`
+}
+
+// stateFile は状態ファイルの内容です
+type stateFile struct {
+	Scopes map[string]scopeData `json:"scopes"`
+}
+
+// Store はプロファイルとエンドポイントの組ひとつ分の状態を読み書きします。
+// 他の s3-cli が保存した内容を上書きしにくいよう、保存のたびにファイルを読み直して自分の分だけを更新します。
+// ファイルはロックしないため、複数の s3-cli がまったく同時に保存した場合は片方の変更が失われることがあります。
+type Store struct {
+	path  string
+	scope string
+
+	mu   sync.Mutex
+	data scopeData
+}
+
+// DefaultPath は状態ファイルの既定のパスを返します。
+// $XDG_STATE_HOME が設定されていればその下、なければ ~/.local/state の下を使います。
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", i18n.Errorf("config.home_dir", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "s3-cli", "state.json"), nil
+}
+
+// scopeKey はプロファイルとエンドポイントの組を表すキーを返します
+func scopeKey(profile, endpoint string) string {
+	if profile == "" {
+		profile = "default"
+	}
+	return profile + "|" + endpoint
+}
+
+// Open は状態ファイルからプロファイルとエンドポイントの組の状態を読み込みます。ファイルがない場合は空の状態です
+func Open(path, profile, endpoint string) (*Store, error) {
+	s := &Store{path: path, scope: scopeKey(profile, endpoint)}
+	file, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	s.data = file.Scopes[s.scope]
+	return s, nil
+}
+
+// read は状態ファイルを読み込みます
+func (s *Store) read() (stateFile, error) {
+	var file stateFile
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return file, i18n.Errorf("state.read_failed", s.path, err)
+	}
+	return file, nil
+}
+
+// update はファイルを読み直して自分の分の状態を update で変更し、書き戻します
+func (s *Store) update(update func(d *scopeData)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := s.read()
+	if err != nil {
+		return err
+	}
+	if file.Scopes == nil {
+		file.Scopes = make(map[string]scopeData)
+	}
+	d := file.Scopes[s.scope]
+	update(&d)
+	file.Scopes[s.scope] = d
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	// 書き込み中に終了しても壊れないよう、一時ファイルに書いてから置き換える。
+	// 他の s3-cli と一時ファイルを取り合わないよう、名前は毎回変える
+	if err := writeFile(s.path, append(data, '\n')); err != nil {
+		return err
+	}
+	s.data = d
+	return nil
+}
+
+// writeFile は path と同じディレクトリの一時ファイルに data を書き込み、path に名前を変えます
+func writeFile(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "state-*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Bookmarks はブックマークを登録した順に返します
+func (s *Store) Bookmarks() []Location {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Location(nil), s.data.Bookmarks...)
+}
+
+// Recent は最近開いた場所を新しい順に返します
+func (s *Store) Recent() []Location {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Location(nil), s.data.Recent...)
+}
+
+// IsBookmarked は場所がブックマークに登録されているかを返します
+func (s *Store) IsBookmarked(l Location) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return indexOf(s.data.Bookmarks, l) >= 0
+}
+
+// ToggleBookmark は場所をブックマークに登録し、既に登録されている場合は削除します。登録したかどうかを返します
+func (s *Store) ToggleBookmark(l Location) (bool, error) {
+	added := false
+	err := s.update(func(d *scopeData) {
+		if i := indexOf(d.Bookmarks, l); i >= 0 {
+			d.Bookmarks = append(d.Bookmarks[:i:i], d.Bookmarks[i+1:]...)
+			return
+		}
+		d.Bookmarks = append(d.Bookmarks, l)
+		added = true
+	})
+	return added, err
+}
+
+// Visit は場所を最近開いた場所の先頭に記録します。古いものから MaxRecent を超えた分を捨てます
+func (s *Store) Visit(l Location) error {
+	return s.update(func(d *scopeData) {
+		recent := []Location{l}
+		for _, r := range d.Recent {
+			if r != l && len(recent) < MaxRecent {
+				recent = append(recent, r)
+			}
+		}
+		d.Recent = recent
+	})
+}
+
+// KnownBuckets は開けたことのあるバケットを名前の順に返します
+func (s *Store) KnownBuckets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.data.Buckets...)
+}
+
+// RememberBucket はバケットを開けたことを記録します。記録済みの場合はファイルを書き換えません
+func (s *Store) RememberBucket(name string) error {
+	s.mu.Lock()
+	known := containsString(s.data.Buckets, name)
+	s.mu.Unlock()
+	if known {
+		return nil
+	}
+	return s.update(func(d *scopeData) {
+		if !containsString(d.Buckets, name) {
+			d.Buckets = append(d.Buckets, name)
+			sort.Strings(d.Buckets)
+		}
+	})
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func indexOf(locations []Location, l Location) int {
+	for i, loc := range locations {
+		if loc == l {
+			return i
+		}
+	}
+	return -1
+}
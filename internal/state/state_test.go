@@ -0,0 +1,180 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLocation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		uri      string
+		expected Location
+		wantErr  bool
+	}{
+		{name: "バケットのみ", uri: "s3://logs", expected: Location{Bucket: "logs"}},
+		{name: "末尾のスラッシュ", uri: "s3://logs/", expected: Location{Bucket: "logs"}},
+		{name: "プレフィックス", uri: "s3://logs/2026/10/", expected: Location{Bucket: "logs", Prefix: "2026/10/"}},
+		{name: "スキームなし", uri: "logs/2026/", wantErr: true},
+		{name: "バケットなし", uri: "s3:///2026/", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseLocation(tc.uri)
+			if tc.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tc.uri) {
+					t.Errorf("期待結果 %q を含むエラー, 実際の結果 %v", tc.uri, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+			// 文字列に戻すと同じ場所を表す
+			if again, _ := ParseLocation(got.String()); again != got {
+				t.Errorf("期待結果 %v, 実際の結果 %v", got, again)
+			}
+		})
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s3-cli", "state.json")
+	store, err := Open(path, "", "")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	logs := Location{Bucket: "logs", Prefix: "2026/"}
+	data := Location{Bucket: "data"}
+
+	for _, l := range []Location{logs, data} {
+		if added, err := store.ToggleBookmark(l); err != nil || !added {
+			t.Fatalf("ブックマークの登録に失敗しました: %v %v", added, err)
+		}
+	}
+	if added, err := store.ToggleBookmark(logs); err != nil || added {
+		t.Fatalf("ブックマークの削除に失敗しました: %v %v", added, err)
+	}
+	if store.IsBookmarked(logs) || !store.IsBookmarked(data) {
+		t.Errorf("ブックマークの状態が期待と異なります: %v", store.Bookmarks())
+	}
+
+	// 同じプロファイルとエンドポイントで開き直すと読み込まれ、別の組には影響しない
+	reopened, err := Open(path, "default", "")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if got := reopened.Bookmarks(); !reflect.DeepEqual(got, []Location{data}) {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []Location{data}, got)
+	}
+	other, err := Open(path, "default", "http://localhost:4566")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if got := other.Bookmarks(); len(got) != 0 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []Location{}, got)
+	}
+	// 別の組の保存で、最初の組の内容が失われない
+	if _, err := other.ToggleBookmark(logs); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if reopened, _ := Open(path, "", ""); !reflect.DeepEqual(reopened.Bookmarks(), []Location{data}) {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []Location{data}, reopened.Bookmarks())
+	}
+	// 一時ファイルは残らない
+	if tmps, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp")); len(tmps) != 0 {
+		t.Errorf("一時ファイルが残っています: %v", tmps)
+	}
+}
+
+func TestVisit(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "state.json"), "dev", "")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	for i := 0; i < MaxRecent+5; i++ {
+		if err := store.Visit(Location{Bucket: fmt.Sprintf("bucket-%d", i)}); err != nil {
+			t.Fatalf("予期しないエラー: %v", err)
+		}
+	}
+	// 既に記録されている場所は先頭に移動する
+	if err := store.Visit(Location{Bucket: "bucket-10"}); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	recent := store.Recent()
+	if len(recent) != MaxRecent {
+		t.Fatalf("期待結果 %d, 実際の結果 %d", MaxRecent, len(recent))
+	}
+	expected := []string{"bucket-10", "bucket-24", "bucket-23"}
+	for i, bucket := range expected {
+		if recent[i].Bucket != bucket {
+			t.Errorf("期待結果 %v, 実際の結果 %v", bucket, recent[i].Bucket)
+		}
+	}
+	if last := recent[len(recent)-1].Bucket; last != "bucket-5" {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "bucket-5", last)
+	}
+}
+
+func TestRememberBucket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := Open(path, "restricted", "")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	for _, bucket := range []string{"logs", "data", "logs"} {
+		if err := store.RememberBucket(bucket); err != nil {
+			t.Fatalf("予期しないエラー: %v", err)
+		}
+	}
+	expected := []string{"data", "logs"}
+	if got := store.KnownBuckets(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("期待結果 %v, 実際の結果 %v", expected, got)
+	}
+
+	// 開けたバケットはプロファイルごとに記録される
+	other, err := Open(path, "default", "")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if got := other.KnownBuckets(); len(got) != 0 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []string{}, got)
+	}
+	again, err := Open(path, "restricted", "")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if got := again.KnownBuckets(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("期待結果 %v, 実際の結果 %v", expected, got)
+	}
+}
+
+func TestOpenBrokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path, "", ""); err == nil || !strings.Contains(err.Error(), "状態ファイル") {
+		t.Errorf("壊れた状態ファイルでエラーが返されませんでした: %v", err)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/tmp/xdg")
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if path != "/tmp/xdg/s3-cli/state.json" {
+		t.Errorf("期待結果 %q, 実際の結果 %q", "/tmp/xdg/s3-cli/state.json", path)
+	}
+}
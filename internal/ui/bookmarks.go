@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/state"
+)
+
+// bookmarksState はブックマークと最近開いた場所の画面の状態です
+type bookmarksState struct {
+	cursor  int
+	entries []bookmarkEntry
+	err     error
+}
+
+// bookmarkEntry はブックマークの画面の1行です
+type bookmarkEntry struct {
+	location   state.Location
+	bookmarked bool // ブックマークの行か、最近開いた場所の行か
+}
+
+// bookmarkEntries はブックマークを登録順に、続けて最近開いた場所を新しい順に並べます
+func bookmarkEntries(store *state.Store) []bookmarkEntry {
+	if store == nil {
+		return nil
+	}
+	var entries []bookmarkEntry
+	for _, l := range store.Bookmarks() {
+		entries = append(entries, bookmarkEntry{location: l, bookmarked: true})
+	}
+	for _, l := range store.Recent() {
+		entries = append(entries, bookmarkEntry{location: l})
+	}
+	return entries
+}
+
+// toggleBookmark は表示している場所をブックマークに登録し、登録済みの場合は削除します
+func (m UIModel) toggleBookmark() UIModel {
+	if m.store == nil {
+		return m
+	}
+	loc := m.currentLocation()
+	added, err := m.store.ToggleBookmark(loc)
+	switch {
+	case err != nil:
+		m.objectOp = objectOpState{err: err}
+	case added:
+		m.objectOp = objectOpState{status: i18n.T("bookmark.added", loc.String())}
+	default:
+		m.objectOp = objectOpState{status: i18n.T("bookmark.removed", loc.String())}
+	}
+	return m
+}
+
+// openBookmarks はブックマークと最近開いた場所の画面を開きます
+func (m UIModel) openBookmarks() UIModel {
+	m.bookmarks = &bookmarksState{entries: bookmarkEntries(m.store)}
+	return m
+}
+
+// handleBookmarksKey はブックマークの画面の表示中のキー入力を処理します
+func (m UIModel) handleBookmarksKey(msg tea.KeyMsg) (UIModel, tea.Cmd) {
+	km := m.keys
+	b := *m.bookmarks
+	switch {
+	case msg.Type == tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case key.Matches(msg, km.Bookmarks, km.Back, km.Quit):
+		m.bookmarks = nil
+		return m, nil
+
+	case key.Matches(msg, km.Up):
+		b.cursor = max(b.cursor-1, 0)
+
+	case key.Matches(msg, km.Down):
+		b.cursor = max(min(b.cursor+1, len(b.entries)-1), 0)
+
+	case key.Matches(msg, km.Select):
+		if len(b.entries) == 0 {
+			return m, nil
+		}
+		m.bookmarks = nil
+		return m.navigateTo(b.entries[b.cursor].location)
+
+	case key.Matches(msg, km.Bookmark):
+		// ブックマークの行では削除し、最近開いた場所の行では登録する
+		if len(b.entries) == 0 || m.store == nil {
+			return m, nil
+		}
+		loc := b.entries[b.cursor].location
+		if _, err := m.store.ToggleBookmark(loc); err != nil {
+			b.err = err
+			break
+		}
+		b.entries, b.err = bookmarkEntries(m.store), nil
+		b.cursor = min(b.cursor, max(len(b.entries)-1, 0))
+	}
+	m.bookmarks = &b
+	return m, nil
+}
+
+// renderBookmarks はブックマークと最近開いた場所の画面を描画します
+func (m UIModel) renderBookmarks() string {
+	b := m.bookmarks
+	width := m.viewWidth() - 2
+	title := m.theme.HeaderTitle.Render(" " + i18n.T("bookmark.title") + " ")
+
+	var lines []string
+	if len(b.entries) == 0 {
+		lines = append(lines, m.theme.Dim.Render(i18n.T("bookmark.empty", m.keys.Bookmark.Help().Key)))
+	}
+	// 見出し・枠・説明の行を除いた高さに収まる範囲だけを表示する
+	maxRows := len(b.entries)
+	if m.height > 0 {
+		maxRows = max(m.height-10, 3)
+	}
+	start, end := calculateVisibleRange(len(b.entries), b.cursor, 0, maxRows)
+	for i := start; i < end; i++ {
+		entry := b.entries[i]
+		if i == start || entry.bookmarked != b.entries[i-1].bookmarked {
+			heading := i18n.T("bookmark.recent")
+			if entry.bookmarked {
+				heading = i18n.T("bookmark.bookmarks")
+			}
+			if i > start {
+				lines = append(lines, "")
+			}
+			lines = append(lines, m.theme.Dim.Render(heading))
+		}
+		mark := "  "
+		if entry.bookmarked {
+			mark = "★ "
+		}
+		text := runewidth.Truncate(mark+entry.location.String(), width-2, "…")
+		if i == b.cursor {
+			lines = append(lines, m.theme.Selected.Render(runewidth.FillRight(text, width-2)))
+		} else {
+			lines = append(lines, m.theme.Item.Render(text))
+		}
+	}
+
+	view := title + "\n\n" + m.theme.Panel.Copy().Width(width).Render(strings.Join(lines, "\n")) + "\n"
+	if b.err != nil {
+		view += m.theme.Error.Render(runewidth.Truncate(i18n.T("ui.error", b.err), width, "…")) + "\n"
+	}
+	return view + m.theme.Dim.Render(i18n.T("bookmark.hint",
+		m.keys.Select.Help().Key, m.keys.Bookmark.Help().Key, m.keys.Back.Help().Key))
+}
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/state"
+)
+
+// TestBookmarks はブックマークの登録・一覧からの移動・削除をテストします
+func TestBookmarks(t *testing.T) {
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.json"), "", "")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	bookmarkKey := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")}
+	logs := state.Location{Bucket: "alpha", Prefix: "logs/"}
+
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m.store = store
+	m, cmd := m.openLocation(logs)
+	if m.state != ObjectsView || m.objectModel.BucketName != "alpha" || m.objectModel.Prefix != "logs/" || cmd == nil {
+		t.Fatalf("場所を開けませんでした: %v %+v", m.state, m.objectModel)
+	}
+
+	// オブジェクト一覧で表示している場所をブックマークする
+	model, _ := m.Update(bookmarkKey)
+	m = model.(UIModel)
+	if !store.IsBookmarked(logs) {
+		t.Errorf("ブックマークが登録されませんでした: %v", store.Bookmarks())
+	}
+
+	// 一覧にはブックマークの後に最近開いた場所が並ぶ
+	m = m.openBookmarks()
+	expected := []bookmarkEntry{{location: logs, bookmarked: true}, {location: logs}}
+	if !reflect.DeepEqual(m.bookmarks.entries, expected) {
+		t.Errorf("期待結果 %v, 実際の結果 %v", expected, m.bookmarks.entries)
+	}
+
+	// ブックマークの行で押すと削除される
+	m, _ = m.handleBookmarksKey(bookmarkKey)
+	if store.IsBookmarked(logs) || len(m.bookmarks.entries) != 1 {
+		t.Errorf("ブックマークが削除されませんでした: %v", m.bookmarks.entries)
+	}
+
+	// 最近開いた場所を選ぶと、その場所を開いて画面を閉じる
+	m.objectModel.Prefix = ""
+	m, cmd = m.handleBookmarksKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.bookmarks != nil || m.objectModel.Prefix != "logs/" || cmd == nil {
+		t.Errorf("場所を開けませんでした: %+v", m.objectModel)
+	}
+}
+
+// TestCurrentLocation は検索中の場所が検索したプレフィックスになることをテストします
+func TestCurrentLocation(t *testing.T) {
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m.objectModel.BucketName = "alpha"
+	m.objectModel.Prefix = "logs/"
+	if got := m.currentLocation(); got != (state.Location{Bucket: "alpha", Prefix: "logs/"}) {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "s3://alpha/logs/", got)
+	}
+
+	m.search = searchState{prefix: "logs/2026/", progress: make(chan aws.SearchProgress)}
+	if got := m.currentLocation(); got != (state.Location{Bucket: "alpha", Prefix: "logs/2026/"}) {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "s3://alpha/logs/2026/", got)
+	}
+}
@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// bucketOpState はバケットの作成・削除の状態です
+type bucketOpState struct {
+	busy   bool
+	status string // 実行中の操作か、直前の操作の結果
+	err    error
+}
+
+// openCreateBucket はバケットの作成ダイアログを開きます。名前・リージョン・オブジェクトロック・バージョニングの順に尋ねます
+func (m UIModel) openCreateBucket(value string, err error) UIModel {
+	m.prompt = m.newInputPrompt(i18n.T("bucket.create_name"), "my-bucket", value,
+		func(m UIModel, value string) (UIModel, tea.Cmd) {
+			name := strings.TrimSpace(value)
+			if err := aws.ValidateBucketName(name); err != nil {
+				return m.openCreateBucket(value, err), nil
+			}
+			return m.openBucketRegion(name), nil
+		})
+	m.prompt.err = err
+	return m
+}
+
+// openBucketRegion は作成するバケットのリージョンを尋ねます。空の場合はクライアントのリージョンです
+func (m UIModel) openBucketRegion(name string) UIModel {
+	region := m.s3Client.GetRegion()
+	m.prompt = m.newInputPrompt(i18n.T("bucket.create_region", region), region, "",
+		func(m UIModel, value string) (UIModel, tea.Cmd) {
+			opts := aws.CreateBucketOptions{Region: strings.TrimSpace(value)}
+			return m.openBucketObjectLock(name, opts), nil
+		})
+	return m
+}
+
+// openBucketObjectLock はオブジェクトロックを有効にするかを尋ねます。有効にする場合はバージョニングも有効になります
+func (m UIModel) openBucketObjectLock(name string, opts aws.CreateBucketOptions) UIModel {
+	m.prompt = m.newInputPrompt(i18n.T("bucket.create_object_lock"), "y/N", "",
+		func(m UIModel, value string) (UIModel, tea.Cmd) {
+			if isYes(value) {
+				opts.ObjectLock = true
+				return m.createBucket(name, opts)
+			}
+			return m.openBucketVersioning(name, opts), nil
+		})
+	return m
+}
+
+// openBucketVersioning はバージョニングを有効にするかを尋ねます
+func (m UIModel) openBucketVersioning(name string, opts aws.CreateBucketOptions) UIModel {
+	m.prompt = m.newInputPrompt(i18n.T("bucket.create_versioning"), "y/N", "",
+		func(m UIModel, value string) (UIModel, tea.Cmd) {
+			opts.Versioning = isYes(value)
+			return m.createBucket(name, opts)
+		})
+	return m
+}
+
+// createBucket はバックグラウンドでバケットを作成します
+func (m UIModel) createBucket(name string, opts aws.CreateBucketOptions) (UIModel, tea.Cmd) {
+	m.bucketOp = bucketOpState{busy: true, status: i18n.T("bucket.creating", name)}
+	client := m.s3Client
+	return m, func() tea.Msg {
+		if err := client.CreateBucket(context.Background(), name, opts); err != nil {
+			return bucketOpMsg{err: err}
+		}
+		return bucketOpMsg{status: i18n.T("bucket.created", name)}
+	}
+}
+
+// openDeleteBucket はカーソル位置のバケットの削除ダイアログを開きます。
+// 誤操作を防ぐため、バケット名を入力させてから、先に空にするかを尋ねます。
+func (m UIModel) openDeleteBucket(value string, err error) UIModel {
+	if len(m.bucketModel.FilteredBuckets) == 0 {
+		return m
+	}
+	name := m.bucketModel.FilteredBuckets[m.bucketModel.Cursor]
+	m.prompt = m.newInputPrompt(i18n.T("bucket.delete_confirm", name), name, value,
+		func(m UIModel, value string) (UIModel, tea.Cmd) {
+			if value != name {
+				return m.openDeleteBucket(value, i18n.Errorf("bucket.name_mismatch", name)), nil
+			}
+			m.prompt = m.newInputPrompt(i18n.T("bucket.delete_empty"), "y/N", "",
+				func(m UIModel, value string) (UIModel, tea.Cmd) {
+					return m.deleteBucket(name, isYes(value))
+				})
+			return m, nil
+		})
+	m.prompt.err = err
+	return m
+}
+
+// deleteBucket はバックグラウンドでバケットを削除します。empty が true の場合は先にすべてのバージョンを削除します
+func (m UIModel) deleteBucket(name string, empty bool) (UIModel, tea.Cmd) {
+	m.bucketOp = bucketOpState{busy: true, status: i18n.T("bucket.deleting", name)}
+	m.invalidateListings(name)
+	client := m.s3Client
+	return m, func() tea.Msg {
+		ctx := context.Background()
+		deleted := 0
+		if empty {
+			var err error
+			if deleted, err = client.EmptyBucket(ctx, name, nil); err != nil {
+				return bucketOpMsg{err: err, status: i18n.T("bucket.emptied_partially", deleted)}
+			}
+		}
+		if err := client.DeleteBucket(ctx, name); err != nil {
+			return bucketOpMsg{err: err}
+		}
+		return bucketOpMsg{status: i18n.T("bucket.deleted", name, deleted)}
+	}
+}
+
+// handleBucketOp はバケットの作成・削除の結果を表示し、バケット一覧を取り直します
+func (m UIModel) handleBucketOp(msg bucketOpMsg) (UIModel, tea.Cmd) {
+	m.bucketOp = bucketOpState{status: msg.status, err: msg.err}
+	return m, m.fetchBuckets
+}
+
+// renderBucketStatus はバケット一覧の上に表示する、作成・削除の状態の行を返します
+func (m UIModel) renderBucketStatus() string {
+	op := m.bucketOp
+	switch {
+	case op.err != nil:
+		status := i18n.T("ui.error", op.err)
+		if op.status != "" {
+			status = op.status + "  " + status
+		}
+		return m.theme.Error.Render(status)
+	case op.status != "":
+		return m.theme.Status.Render(op.status)
+	}
+	return ""
+}
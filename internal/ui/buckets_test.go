@@ -0,0 +1,72 @@
+package ui
+
+import "testing"
+
+// TestDeleteBucketConfirm はバケット名を正しく入力した場合だけ削除に進むことをテストします
+func TestDeleteBucketConfirm(t *testing.T) {
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m.s3Client = newTestClient(t)
+	m.bucketModel.Cursor = 1 // beta を削除する
+	m = m.openDeleteBucket("", nil)
+	if m.prompt == nil {
+		t.Fatal("確認ダイアログが開いていません")
+	}
+
+	// 別のバケット名では同じダイアログがエラー付きで開き直される
+	m, _ = submitPrompt(m, "alpha")
+	if m.prompt == nil || m.prompt.err == nil || m.bucketOp.busy {
+		t.Fatalf("名前が一致しない場合にエラーが表示されていません: %+v", m.prompt)
+	}
+
+	m, _ = submitPrompt(m, "beta")
+	if m.prompt == nil || m.prompt.err != nil {
+		t.Fatal("空にするかの確認が開いていません")
+	}
+	m, cmd := submitPrompt(m, "y")
+	if !m.bucketOp.busy || cmd == nil {
+		t.Errorf("削除が始まっていません: %+v", m.bucketOp)
+	}
+}
+
+// TestCreateBucketPrompt はバケット名の検証と、オブジェクトロックを選んだ場合にバージョニングを尋ねないことをテストします
+func TestCreateBucketPrompt(t *testing.T) {
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m.s3Client = newTestClient(t)
+	m = m.openCreateBucket("", nil)
+	m, _ = submitPrompt(m, "Invalid_Name")
+	if m.prompt == nil || m.prompt.err == nil {
+		t.Fatal("不正な名前でエラーが表示されていません")
+	}
+
+	m, _ = submitPrompt(m, "new-bucket")
+	m, _ = submitPrompt(m, "eu-west-1")
+	m, cmd := submitPrompt(m, "y")
+	if m.prompt != nil || !m.bucketOp.busy || cmd == nil {
+		t.Errorf("オブジェクトロックを選んだ後に作成が始まっていません: %+v", m.bucketOp)
+	}
+}
+
+// TestBucketOpsWithoutClient はS3クライアントの初期化に失敗した場合に、バケットの作成・削除を始められないことをテストします
+func TestBucketOpsWithoutClient(t *testing.T) {
+	testCases := []struct {
+		name string
+		key  string
+	}{
+		{name: "作成", key: "N"},
+		{name: "削除", key: "X"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestModel(t, []string{"alpha", "beta"})
+			m = press(m, tc.key)
+			if m.prompt != nil {
+				t.Errorf("期待結果 %v, 実際の結果 %v", nil, m.prompt.title)
+			}
+			for _, b := range m.helpKeys().FullHelp()[2] {
+				if b.Help().Key == tc.key && b.Enabled() {
+					t.Errorf("ヘルプに %s が表示されています", tc.key)
+				}
+			}
+		})
+	}
+}
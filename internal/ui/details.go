@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// detailsState はオブジェクトの詳細画面の状態です
+type detailsState struct {
+	bucket  string
+	key     string
+	loading bool
+	info    aws.ObjectDetails
+	err     error
+}
+
+// openDetails はカーソル位置のオブジェクトの詳細画面を開き、HeadObject で詳細を取得します
+func (m UIModel) openDetails() (UIModel, tea.Cmd) {
+	if m.state != ObjectsView || len(m.objectModel.FilteredObjects) == 0 {
+		return m, nil
+	}
+	bucket := m.objectModel.BucketName
+	objectKey := m.objectModel.FilteredObjects[m.objectModel.Cursor]
+	m.details = &detailsState{bucket: bucket, key: objectKey, loading: true}
+	client := m.s3Client
+	return m, func() tea.Msg {
+		info, err := client.DescribeObject(context.Background(), bucket, objectKey)
+		return detailsMsg{bucket: bucket, key: objectKey, info: info, err: err}
+	}
+}
+
+// handleDetails は取得したオブジェクトの詳細を反映します。既に閉じた画面の結果は無視します
+func (m UIModel) handleDetails(msg detailsMsg) UIModel {
+	if m.details == nil || m.details.bucket != msg.bucket || m.details.key != msg.key {
+		return m
+	}
+	details := *m.details
+	details.loading, details.info, details.err = false, msg.info, msg.err
+	m.details = &details
+	return m
+}
+
+// handleDetailsKey は詳細画面の表示中のキー入力を処理します
+func (m UIModel) handleDetailsKey(msg tea.KeyMsg) (UIModel, tea.Cmd) {
+	km := m.keys
+	switch {
+	case msg.Type == tea.KeyCtrlC:
+		return m, tea.Quit
+	case key.Matches(msg, km.Details, km.Back, km.Quit):
+		m.details = nil
+	}
+	return m, nil
+}
+
+// formatEncryption はオブジェクトの暗号化の設定を表示用の文字列にします
+func formatEncryption(e aws.Encryption) string {
+	switch e.Mode {
+	case aws.EncryptionS3:
+		return i18n.T("details.sse_s3")
+	case aws.EncryptionKMS:
+		keyID := e.KMSKeyID
+		if keyID == "" {
+			keyID = "aws/s3"
+		}
+		if e.BucketKey {
+			return i18n.T("details.sse_kms_bucket_key", keyID)
+		}
+		return i18n.T("details.sse_kms", keyID)
+	case aws.EncryptionCustomer:
+		return i18n.T("details.sse_c")
+	}
+	return i18n.T("details.sse_none")
+}
+
+// detailRows は詳細画面に表示する項目名と値の組を返します
+func detailRows(info aws.ObjectDetails) [][2]string {
+	rows := [][2]string{
+		{i18n.T("details.key"), info.Key},
+		{i18n.T("details.size"), fmt.Sprintf("%s (%d bytes)", formatSize(info.Size), info.Size)},
+		{i18n.T("details.modified"), info.LastModified.Local().Format("2006-01-02 15:04:05")},
+		{i18n.T("details.class"), info.StorageClass},
+		{i18n.T("details.content_type"), info.ContentType},
+		{i18n.T("details.etag"), info.ETag},
+		{i18n.T("details.encryption"), formatEncryption(info.Encryption)},
+	}
+	algorithms := make([]string, 0, len(info.Checksums))
+	for algorithm := range info.Checksums {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+	for _, algorithm := range algorithms {
+		rows = append(rows, [2]string{algorithm, info.Checksums[algorithm]})
+	}
+	return rows
+}
+
+// renderDetails はオブジェクトの詳細画面を描画します
+func (m UIModel) renderDetails() string {
+	d := m.details
+	width := m.viewWidth() - 2
+	title := m.theme.HeaderTitle.Render(" " + i18n.T("details.title") + " ")
+	hint := m.theme.Dim.Render(i18n.T("details.hint", m.keys.Details.Help().Key))
+
+	var body string
+	switch {
+	case d.loading:
+		body = m.theme.Dim.Render(i18n.T("details.loading", d.key))
+	case d.err != nil:
+		body = m.theme.Error.Render(runewidth.Truncate(fmt.Sprintf("⚠ %v", d.err), width, "…"))
+	default:
+		rows := detailRows(d.info)
+		labelWidth := 0
+		for _, row := range rows {
+			labelWidth = max(labelWidth, runewidth.StringWidth(row[0]))
+		}
+		lines := make([]string, len(rows))
+		for i, row := range rows {
+			label := m.theme.Dim.Render(runewidth.FillRight(row[0], labelWidth))
+			value := runewidth.Truncate(row[1], max(width-labelWidth-2, 1), "…")
+			lines[i] = label + "  " + m.theme.Item.Render(value)
+		}
+		body = strings.Join(lines, "\n")
+	}
+	return title + "\n\n" + m.theme.Panel.Copy().Width(width).Render(body) + "\n" + hint
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+)
+
+// TestFormatEncryption はオブジェクトの暗号化の表示をテストします
+func TestFormatEncryption(t *testing.T) {
+	testCases := []struct {
+		name       string
+		encryption aws.Encryption
+		expected   string
+	}{
+		{name: "暗号化なし", encryption: aws.Encryption{}, expected: "なし"},
+		{name: "SSE-S3", encryption: aws.Encryption{Mode: aws.EncryptionS3}, expected: "SSE-S3（AES256, S3が管理する鍵）"},
+		{name: "SSE-KMS（マネージドキー）", encryption: aws.Encryption{Mode: aws.EncryptionKMS}, expected: "SSE-KMS（鍵: aws/s3）"},
+		{
+			name:       "SSE-KMS（バケットキー）",
+			encryption: aws.Encryption{Mode: aws.EncryptionKMS, KMSKeyID: "arn:aws:kms:us-east-1:111122223333:key/abcd", BucketKey: true},
+			expected:   "SSE-KMS（鍵: arn:aws:kms:us-east-1:111122223333:key/abcd, バケットキー）",
+		},
+		{name: "SSE-C", encryption: aws.Encryption{Mode: aws.EncryptionCustomer}, expected: "SSE-C（利用者が指定した鍵）"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := formatEncryption(tc.encryption); got != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestHandleDetails は閉じた後や別のオブジェクトの詳細の取得結果を無視することをテストします
+func TestHandleDetails(t *testing.T) {
+	info := aws.ObjectDetails{Key: "a.txt", Encryption: aws.Encryption{Mode: aws.EncryptionS3}}
+
+	m := UIModel{session: session{details: &detailsState{bucket: "bucket", key: "a.txt", loading: true}}}
+	m = m.handleDetails(detailsMsg{bucket: "bucket", key: "b.txt", info: aws.ObjectDetails{Key: "b.txt"}})
+	if !m.details.loading {
+		t.Error("別のオブジェクトの結果が反映されました")
+	}
+	m = m.handleDetails(detailsMsg{bucket: "bucket", key: "a.txt", info: info})
+	if m.details.loading || m.details.info.Encryption != info.Encryption {
+		t.Errorf("期待結果 %+v, 実際の結果 %+v", info, m.details.info)
+	}
+
+	closed := UIModel{}.handleDetails(detailsMsg{bucket: "bucket", key: "a.txt", info: info})
+	if closed.details != nil {
+		t.Error("閉じた詳細画面が開きました")
+	}
+}
@@ -0,0 +1,108 @@
+package ui
+
+import "strings"
+
+// diffOp は差分の行の種類です
+type diffOp byte
+
+const (
+	diffSame    diffOp = ' '
+	diffRemoved diffOp = '-'
+	diffAdded   diffOp = '+'
+	diffSkipped diffOp = '~' // 省略した変更のない行
+)
+
+// diffLine は差分の1行です。diffSkipped の場合、count に省略した行数が入ります
+type diffLine struct {
+	op    diffOp
+	text  string
+	count int
+}
+
+// maxDiffCells は最長共通部分列の表の大きさの上限です。これを超える場合は全行を削除・追加として扱います
+const maxDiffCells = 4_000_000
+
+// diffLines は before から after への行単位の差分を、最長共通部分列で求めます
+func diffLines(before, after string) []diffLine {
+	a, b := splitLines(before), splitLines(after)
+	if len(a)*len(b) > maxDiffCells {
+		lines := make([]diffLine, 0, len(a)+len(b))
+		for _, text := range a {
+			lines = append(lines, diffLine{op: diffRemoved, text: text})
+		}
+		for _, text := range b {
+			lines = append(lines, diffLine{op: diffAdded, text: text})
+		}
+		return lines
+	}
+
+	// lcs[i][j] は a[i:] と b[j:] の最長共通部分列の長さ
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{op: diffSame, text: a[i]})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			lines = append(lines, diffLine{op: diffAdded, text: b[j]})
+			j++
+		default:
+			lines = append(lines, diffLine{op: diffRemoved, text: a[i]})
+			i++
+		}
+	}
+	return lines
+}
+
+// splitLines は末尾の改行を除いて行に分けます。空の文字列は0行です
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// compactDiff は変更のある行の前後 context 行だけを残し、それ以外の変更のない行をまとめて省略します
+func compactDiff(lines []diffLine, context int) []diffLine {
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if line.op == diffSame {
+			continue
+		}
+		for k := max(i-context, 0); k <= min(i+context, len(lines)-1); k++ {
+			keep[k] = true
+		}
+	}
+
+	var result []diffLine
+	for i := 0; i < len(lines); {
+		if keep[i] {
+			result = append(result, lines[i])
+			i++
+			continue
+		}
+		start := i
+		for i < len(lines) && !keep[i] {
+			i++
+		}
+		result = append(result, diffLine{op: diffSkipped, count: i - start})
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDiffLines は行単位の差分をテストします
+func TestDiffLines(t *testing.T) {
+	testCases := []struct {
+		name     string
+		before   string
+		after    string
+		expected []diffLine
+	}{
+		{name: "変更なし", before: "a\nb\n", after: "a\nb\n", expected: []diffLine{{op: diffSame, text: "a"}, {op: diffSame, text: "b"}}},
+		{
+			name:   "行の変更",
+			before: "{\n  \"Days\": 30\n}\n",
+			after:  "{\n  \"Days\": 60\n}\n",
+			expected: []diffLine{
+				{op: diffSame, text: "{"},
+				{op: diffRemoved, text: "  \"Days\": 30"},
+				{op: diffAdded, text: "  \"Days\": 60"},
+				{op: diffSame, text: "}"},
+			},
+		},
+		{name: "空から追加", before: "", after: "a\n", expected: []diffLine{{op: diffAdded, text: "a"}}},
+		{name: "すべて削除", before: "a\nb", after: "", expected: []diffLine{{op: diffRemoved, text: "a"}, {op: diffRemoved, text: "b"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := diffLines(tc.before, tc.after); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestCompactDiff は変更から離れた行が省略されることをテストします
+func TestCompactDiff(t *testing.T) {
+	lines := diffLines("1\n2\n3\n4\n5\n6\n7\n8\n", "1\n2\n3\n4\n5\n6\n7\nx\n")
+	expected := []diffLine{
+		{op: diffSkipped, count: 5},
+		{op: diffSame, text: "6"},
+		{op: diffSame, text: "7"},
+		{op: diffRemoved, text: "8"},
+		{op: diffAdded, text: "x"},
+	}
+	if got := compactDiff(lines, 2); !reflect.DeepEqual(got, expected) {
+		t.Errorf("期待結果 %v, 実際の結果 %v", expected, got)
+	}
+}
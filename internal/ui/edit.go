@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// objectOpState はオブジェクトの編集や外部のプログラムで開く操作の状態です
+type objectOpState struct {
+	busy   bool
+	status string // 実行中の操作か、直前の操作の結果
+	err    error
+}
+
+// startEdit はオブジェクトを一時ファイルにダウンロードし、終わったらエディタで開きます
+func (m UIModel) startEdit(bucket, key string) (UIModel, tea.Cmd) {
+	m.objectOp = objectOpState{busy: true, status: i18n.T("edit.fetching", key)}
+	client := m.s3Client
+	m.logger.Info("edit started", "bucket", bucket, "key", key)
+	return m, func() tea.Msg {
+		obj, err := client.FetchForEdit(context.Background(), bucket, key)
+		return editFetchedMsg{key: key, obj: obj, err: err}
+	}
+}
+
+// handleEditFetched はダウンロードした一時ファイルを $EDITOR で開きます。エディタの実行中はUIを中断します
+func (m UIModel) handleEditFetched(msg editFetchedMsg) (UIModel, tea.Cmd) {
+	if msg.err != nil {
+		m.logger.Error("fetch for editing failed", "key", msg.key, "error", msg.err)
+		m.objectOp = objectOpState{err: msg.err}
+		return m, nil
+	}
+	obj := msg.obj
+	m.objectOp = objectOpState{busy: true}
+	return m, m.execProcess(EditorProcess(obj.Path), func(err error) tea.Msg {
+		if err != nil {
+			err = i18n.Errorf("cmd.editor_failed", err)
+		}
+		return editDoneMsg{obj: obj, err: err}
+	})
+}
+
+// handleEditDone はエディタの終了後、一時ファイルが変更されていればバックグラウンドで書き戻します
+func (m UIModel) handleEditDone(msg editDoneMsg) (UIModel, tea.Cmd) {
+	obj := msg.obj
+	changed, err := obj.Changed()
+	if msg.err != nil {
+		err = msg.err
+	}
+	if err != nil {
+		obj.Cleanup()
+		m.objectOp = objectOpState{err: err}
+		return m, nil
+	}
+	if !changed {
+		obj.Cleanup()
+		m.objectOp = objectOpState{status: i18n.T("edit.no_changes", obj.Key)}
+		return m, nil
+	}
+
+	m.objectOp = objectOpState{busy: true, status: i18n.T("edit.saving", obj.Key)}
+	client := m.s3Client
+	return m, func() tea.Msg {
+		return editSavedMsg{obj: obj, err: client.SaveEdited(context.Background(), obj)}
+	}
+}
+
+// handleEditSaved は書き戻しの結果を表示します。
+// 失敗した場合は編集内容を失わないよう、一時ファイルを残してそのパスを示します。
+func (m UIModel) handleEditSaved(msg editSavedMsg) UIModel {
+	obj := msg.obj
+	if msg.err != nil {
+		m.logger.Error("saving edited object failed", "bucket", obj.Bucket, "key", obj.Key, "path", obj.Path, "error", msg.err)
+		m.objectOp = objectOpState{status: i18n.T("edit.kept", obj.Path), err: msg.err}
+		return m
+	}
+	obj.Cleanup()
+	m.objectOp = objectOpState{status: i18n.T("edit.saved", obj.Key)}
+	m.invalidateListings(obj.Bucket)
+
+	// 一覧を取り直すとカーソルが先頭に戻るため、表示中の属性だけを更新する
+	if info, ok := m.objectModel.Details[obj.Key]; ok && m.objectModel.BucketName == obj.Bucket {
+		details := make(map[string]aws.ObjectInfo, len(m.objectModel.Details))
+		for k, v := range m.objectModel.Details {
+			details[k] = v
+		}
+		info.Size, info.ETag, info.LastModified = obj.Size, obj.ETag, time.Now()
+		details[obj.Key] = info
+		m.objectModel.Details = details
+	}
+	return m
+}
+
+// renderObjectStatus はオブジェクト一覧の上に表示する、編集などの操作の状態の行を返します
+func (m UIModel) renderObjectStatus() string {
+	op := m.objectOp
+	switch {
+	case op.err != nil:
+		status := i18n.T("ui.error", op.err)
+		if op.status != "" {
+			status += "  " + op.status
+		}
+		return m.theme.Error.Render(status)
+	case op.status != "":
+		return m.theme.Status.Render(op.status)
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+)
+
+// TestHandleEditSaved は書き戻しの結果の表示と、一時ファイルの扱いをテストします
+func TestHandleEditSaved(t *testing.T) {
+	modified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		err      error
+		status   string // 状態の行に含まれる文字列
+		keepFile bool
+		size     int64 // 一覧に表示するサイズ
+	}{
+		{name: "保存した", status: "notes.txt を保存しました", size: 12},
+		{name: "競合した", err: &aws.EditConflictError{Bucket: "beta", Key: "notes.txt"}, status: "編集内容は", keepFile: true, size: 5},
+		{name: "通信エラー", err: errors.New("connection reset"), status: "編集内容は", keepFile: true, size: 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "edit", "notes.txt")
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if err := os.WriteFile(path, []byte("edited notes"), 0600); err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+
+			m := newTestModel(t, []string{"alpha", "beta"})
+			m.state = ObjectsView
+			m.objectModel.BucketName = "beta"
+			m.objectModel.Details = map[string]aws.ObjectInfo{
+				"notes.txt": {Key: "notes.txt", Size: 5, LastModified: modified},
+			}
+			m.objectOp = objectOpState{busy: true}
+			obj := &aws.EditableObject{Bucket: "beta", Key: "notes.txt", Path: path, Size: 12, ETag: `"new"`}
+
+			m = m.handleEditSaved(editSavedMsg{obj: obj, err: tc.err})
+			if m.objectOp.busy {
+				t.Error("書き戻しの後も実行中のままです")
+			}
+			if got := m.objectOp.status; !strings.Contains(got, tc.status) {
+				t.Errorf("期待結果 %q を含む, 実際の結果 %q", tc.status, got)
+			}
+			if tc.keepFile && !strings.Contains(m.objectOp.status, path) {
+				t.Errorf("期待結果 %q を含む, 実際の結果 %q", path, m.objectOp.status)
+			}
+			if _, err := os.Stat(path); (err == nil) != tc.keepFile {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.keepFile, err == nil)
+			}
+			if got := m.objectModel.Details["notes.txt"].Size; got != tc.size {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.size, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// EditorCommand は $VISUAL、$EDITOR の順に使用するエディタを返します。空白だけの値は設定されていないものとみなします
+func EditorCommand() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(env); strings.TrimSpace(editor) != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// EditorProcess は path を開くエディタのコマンドを作成します。$EDITOR には "code -w" のように引数を含められます
+func EditorProcess(path string) *exec.Cmd {
+	fields := strings.Fields(EditorCommand())
+	return exec.Command(fields[0], append(fields[1:], path)...)
+}
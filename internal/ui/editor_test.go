@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditorProcess(t *testing.T) {
+	testCases := []struct {
+		name     string
+		visual   string
+		editor   string
+		expected []string
+	}{
+		{name: "VISUALを優先", visual: "nano", editor: "emacs", expected: []string{"nano", "a.json"}},
+		{name: "引数を含むエディタ", editor: "code -w", expected: []string{"code", "-w", "a.json"}},
+		{name: "空白だけのVISUALは使わない", visual: "  ", editor: "emacs", expected: []string{"emacs", "a.json"}},
+		{name: "どちらも空白だけ", visual: " ", editor: "\t", expected: []string{"vi", "a.json"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tc.visual)
+			t.Setenv("EDITOR", tc.editor)
+			if got := EditorProcess("a.json").Args; !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+}
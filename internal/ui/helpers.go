@@ -1,7 +1,12 @@
 package ui
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
 // filterItems は文字列のスライスをフィルタリングします（部分一致・大文字小文字無視）
@@ -10,11 +15,10 @@ func filterItems(items []string, filter string) []string {
 		return items
 	}
 
-	filtered := make([]string, 0)
-	for _, item := range items {
-		if strings.Contains(strings.ToLower(item), strings.ToLower(filter)) {
-			filtered = append(filtered, item)
-		}
+	results, _ := matchItems(items, filter, MatchSubstring)
+	filtered := make([]string, 0, len(results))
+	for _, r := range results {
+		filtered = append(filtered, r.item)
 	}
 	return filtered
 }
@@ -26,3 +30,78 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// highlightPositions は指定した位置（ルーン単位）の文字にmatchのスタイルを、それ以外にbaseのスタイルを適用します
+func highlightPositions(s string, positions []int, base, match lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(s)
+	}
+
+	marked := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		marked[p] = true
+	}
+
+	var sb strings.Builder
+	var run []rune
+	inMatch := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if inMatch {
+			sb.WriteString(match.Render(string(run)))
+		} else {
+			sb.WriteString(base.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(s) {
+		if marked[i] != inMatch {
+			flush()
+			inMatch = marked[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return sb.String()
+}
+
+// formatCount は整数を3桁区切りの文字列にします
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// formatSize はバイト数を "12.3 MB" のような読みやすい表記にします（1KB=1024B）
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatAge は経過時間を "45s"・"12m"・"3h"・"2d" のように最も大きい単位だけで表します
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
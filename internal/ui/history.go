@@ -0,0 +1,165 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/state"
+)
+
+// maxHistory は戻る履歴として覚える数の上限です
+const maxHistory = 100
+
+// viewPosition は一覧の表示位置です。
+// 一覧を取り直して項目が増減しても同じ項目に戻れるよう、カーソル位置の項目も覚えます。
+type viewPosition struct {
+	filter string // フィルターの入力
+	key    string // カーソル位置の項目
+	cursor int
+	offset int // 表示している先頭の項目の位置
+}
+
+// locate は items の中で表示位置を探し、カーソルと先頭の位置を返します。
+// 項目がなくなっていれば、元のカーソル位置の近くにします。
+func (p viewPosition) locate(items []string) (cursor, offset int) {
+	cursor = p.cursor
+	for i, item := range items {
+		if item == p.key {
+			cursor = i
+			break
+		}
+	}
+	return max(min(cursor, len(items)-1), 0), p.offset
+}
+
+// listPosition は一覧の今の表示位置を返します
+func listPosition(filter string, items []string, cursor, offset int) viewPosition {
+	p := viewPosition{filter: filter, cursor: cursor, offset: offset}
+	if cursor < len(items) {
+		p.key = items[cursor]
+	}
+	return p
+}
+
+// navEntry は移動の履歴の1件です
+type navEntry struct {
+	state    ViewState
+	location state.Location // ObjectsView の場合の場所
+	position viewPosition
+}
+
+// navHistory は戻る・進むの履歴です。どちらも最後の要素が次に戻る（進む）先です
+type navHistory struct {
+	back    []navEntry
+	forward []navEntry
+}
+
+// currentEntry は今表示している一覧を履歴の1件として返します
+func (m UIModel) currentEntry() navEntry {
+	filter := m.filterInput.Value()
+	if m.state == ObjectsView {
+		o := m.objectModel
+		return navEntry{
+			state:    ObjectsView,
+			location: state.Location{Bucket: o.BucketName, Prefix: o.Prefix},
+			position: listPosition(filter, o.FilteredObjects, o.Cursor, o.Offset),
+		}
+	}
+	b := m.bucketModel
+	return navEntry{state: BucketsView, position: listPosition(filter, b.FilteredBuckets, b.Cursor, b.Offset)}
+}
+
+// pushHistory は今の表示位置を戻る履歴に積み、進む履歴を捨てます。別の場所へ移動する前に呼びます
+func (m UIModel) pushHistory() UIModel {
+	back := append(append([]navEntry(nil), m.history.back...), m.currentEntry())
+	if len(back) > maxHistory {
+		back = back[len(back)-maxHistory:]
+	}
+	m.history = navHistory{back: back}
+	return m
+}
+
+// navigateTo は今の表示位置を履歴に積んでから場所を開きます
+func (m UIModel) navigateTo(loc state.Location) (UIModel, tea.Cmd) {
+	return m.pushHistory().openLocation(loc)
+}
+
+// goBack は戻る履歴の場所に戻ります。オブジェクト一覧で履歴がない場合はバケット一覧に戻ります
+func (m UIModel) goBack() (UIModel, tea.Cmd) {
+	back := m.history.back
+	var entry navEntry
+	switch {
+	case len(back) > 0:
+		entry, back = back[len(back)-1], back[:len(back)-1:len(back)-1]
+	case m.state == ObjectsView:
+		// 場所を指定して起動した場合など
+		entry = navEntry{state: BucketsView}
+	default:
+		return m, nil
+	}
+	m.history = navHistory{
+		back:    back,
+		forward: append(append([]navEntry(nil), m.history.forward...), m.currentEntry()),
+	}
+	return m.restoreEntry(entry)
+}
+
+// goForward は戻る前の場所に進みます
+func (m UIModel) goForward() (UIModel, tea.Cmd) {
+	forward := m.history.forward
+	if len(forward) == 0 {
+		return m, nil
+	}
+	entry := forward[len(forward)-1]
+	m.history = navHistory{
+		back:    append(append([]navEntry(nil), m.history.back...), m.currentEntry()),
+		forward: forward[: len(forward)-1 : len(forward)-1],
+	}
+	return m.restoreEntry(entry)
+}
+
+// restoreEntry は履歴の一覧を、フィルター・カーソル・表示位置とともに表示します
+func (m UIModel) restoreEntry(e navEntry) (UIModel, tea.Cmd) {
+	if e.state == ObjectsView {
+		return m.showLocation(e.location, e.position)
+	}
+
+	m.resetSearch()
+	m.state = BucketsView
+	m.setFilter(e.position.filter, i18n.T("ui.filter_buckets"))
+	if m.bucketModel.Buckets == nil {
+		// 場所を指定して起動した場合は、まだバケット一覧を取得していない
+		m.pending = &e.position
+		return m, m.fetchBuckets
+	}
+	m.applyFilter()
+	m.bucketModel.Cursor, m.bucketModel.Offset = e.position.locate(m.bucketModel.FilteredBuckets)
+	return m, nil
+}
+
+// setFilter はフィルターの入力を置き換えます
+func (m *UIModel) setFilter(value, placeholder string) {
+	m.filterInput.Reset()
+	m.filterInput.SetValue(value)
+	m.filterInput.Placeholder = placeholder
+}
+
+// syncScroll はカーソルが表示範囲に収まるよう、表示している一覧の先頭の位置を調整します
+func (m UIModel) syncScroll() UIModel {
+	if m.height == 0 {
+		// ウィンドウの大きさが分かるまでは描画しない
+		return m
+	}
+	height := m.listHeight()
+	switch m.state {
+	case BucketsView:
+		b := &m.bucketModel
+		b.Offset = visibleStart(b.Offset, b.Cursor, height, len(b.FilteredBuckets))
+	case ObjectsView:
+		o := &m.objectModel
+		o.Offset = visibleStart(o.Offset, o.Cursor, height, len(o.FilteredObjects))
+	case UsageView:
+		u := &m.usageModel
+		u.Offset = visibleStart(u.Offset, u.Cursor, height, len(m.usageRows()))
+	}
+	return m
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/state"
+)
+
+// TestHistory は戻る・進むで、場所とフィルター・カーソル・表示位置が復元されることをテストします
+func TestHistory(t *testing.T) {
+	objects := []aws.ObjectInfo{{Key: "logs/a.log"}, {Key: "logs/b.log"}, {Key: "logs/c.log"}}
+	m := newTestModel(t, []string{"alpha", "beta"})
+
+	// バケット一覧から alpha を開き、フィルターとカーソルを動かしてから別の場所へ移動する
+	m.bucketModel.Cursor = 0
+	m, _ = m.navigateTo(state.Location{Bucket: "alpha", Prefix: "logs/"})
+	m = m.handleObjects(objectsMsg{bucket: "alpha", prefix: "logs/", objects: objects})
+	m.filterInput.SetValue(".log")
+	m.applyFilter()
+	m.objectModel.Cursor, m.objectModel.Offset = 2, 1
+	m, _ = m.navigateTo(state.Location{Bucket: "beta"})
+	m = m.handleObjects(objectsMsg{bucket: "beta", objects: []aws.ObjectInfo{{Key: "x"}}})
+
+	// 戻ると、一覧を取り直してもフィルター・カーソル・表示位置が元に戻る
+	m, _ = m.goBack()
+	m = m.handleObjects(objectsMsg{bucket: "alpha", prefix: "logs/", objects: objects})
+	if got := m.currentLocation(); got != (state.Location{Bucket: "alpha", Prefix: "logs/"}) {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", "alpha/logs/", got)
+	}
+	if m.filterInput.Value() != ".log" || m.objectModel.Cursor != 2 || m.objectModel.Offset != 1 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []any{".log", 2, 1}, []any{m.filterInput.Value(), m.objectModel.Cursor, m.objectModel.Offset})
+	}
+
+	// バケット一覧まで戻る
+	m, _ = m.goBack()
+	if m.state != BucketsView || m.bucketModel.Cursor != 0 || m.filterInput.Value() != "" {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []any{BucketsView, 0, ""}, []any{m.state, m.bucketModel.Cursor, m.filterInput.Value()})
+	}
+	if m, _ = m.goBack(); m.state != BucketsView {
+		t.Errorf("履歴の先頭から戻れてしまいました: %v", m.state)
+	}
+
+	// 進むと、戻る前の場所に順に進む
+	m, _ = m.goForward()
+	m = m.handleObjects(objectsMsg{bucket: "alpha", prefix: "logs/", objects: objects})
+	if m.currentLocation().Bucket != "alpha" || m.objectModel.Cursor != 2 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []any{"alpha", 2}, []any{m.currentLocation().Bucket, m.objectModel.Cursor})
+	}
+	m, _ = m.goForward()
+	if m.currentLocation().Bucket != "beta" || len(m.history.forward) != 0 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "beta", m.currentLocation())
+	}
+
+	// 戻ってから別の場所へ移動すると、進む履歴は捨てる
+	m, _ = m.goBack()
+	m, _ = m.navigateTo(state.Location{Bucket: "gamma"})
+	if len(m.history.forward) != 0 || len(m.history.back) != 2 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []int{0, 2}, []int{len(m.history.forward), len(m.history.back)})
+	}
+}
+
+// TestHistoryKeys は [ と ] のキーで履歴を戻る・進むことをテストします
+func TestHistoryKeys(t *testing.T) {
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m, _ = m.navigateTo(state.Location{Bucket: "alpha"})
+	m, _ = m.navigateTo(state.Location{Bucket: "beta"})
+
+	m = press(m, "[")
+	if got := m.currentLocation().Bucket; got != "alpha" {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", "alpha", got)
+	}
+	m = press(m, "[")
+	if m.state != BucketsView || len(m.history.forward) != 2 {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", []any{BucketsView, 2}, []any{m.state, len(m.history.forward)})
+	}
+	m = press(m, "]", "]")
+	if got := m.currentLocation().Bucket; got != "beta" {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "beta", got)
+	}
+}
+
+// TestHistoryWithoutBuckets は場所を指定して起動した場合に、戻るとバケット一覧を取得することをテストします
+func TestHistoryWithoutBuckets(t *testing.T) {
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m.bucketModel.Buckets, m.bucketModel.FilteredBuckets = nil, nil
+	m, _ = m.openLocation(state.Location{Bucket: "beta"})
+
+	m, cmd := m.goBack()
+	if m.state != BucketsView || cmd == nil {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", BucketsView, m.state)
+	}
+	model, _ := m.Update(bucketsMsg{buckets: []string{"alpha", "beta"}})
+	m = model.(UIModel)
+	if m.pending != nil || len(m.history.forward) != 1 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", 1, len(m.history.forward))
+	}
+}
+
+// TestVisibleStart は表示範囲の先頭がカーソルに合わせて最小限だけ動くことをテストします
+func TestVisibleStart(t *testing.T) {
+	tests := []struct {
+		name     string
+		offset   int
+		cursor   int
+		expected int
+	}{
+		{name: "範囲内ならそのまま", offset: 3, cursor: 5, expected: 3},
+		{name: "上に出たらカーソルを先頭にする", offset: 3, cursor: 1, expected: 1},
+		{name: "下に出たらカーソルを末尾にする", offset: 0, cursor: 7, expected: 3},
+		{name: "末尾より後ろは空けない", offset: 9, cursor: 9, expected: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 10件の一覧を5行で表示する
+			if got := visibleStart(tt.offset, tt.cursor, 5, 10); got != tt.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tt.expected, got)
+			}
+		})
+	}
+}
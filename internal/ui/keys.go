@@ -0,0 +1,291 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// keyMap はキー操作の定義です。フッターとヘルプはここから生成されます。
+type keyMap struct {
+	Up            key.Binding
+	Down          key.Binding
+	PageUp        key.Binding
+	PageDown      key.Binding
+	Top           key.Binding
+	Bottom        key.Binding
+	Select        key.Binding
+	Back          key.Binding
+	Forward       key.Binding
+	Filter        key.Binding
+	MatchMode     key.Binding
+	Search        key.Binding
+	CancelSearch  key.Binding
+	Help          key.Binding
+	Logs          key.Binding
+	Details       key.Binding
+	Settings      key.Binding
+	Edit          key.Binding
+	OpenWith      key.Binding
+	Bookmark      key.Binding
+	Bookmarks     key.Binding
+	GoTo          key.Binding
+	Refresh       key.Binding
+	CreateBucket  key.Binding
+	DeleteBucket  key.Binding
+	Uploads       key.Binding
+	Usage         key.Binding
+	Export        key.Binding
+	ToggleSelect  key.Binding
+	Abort         key.Binding
+	AbortOlder    key.Binding
+	BandwidthUp   key.Binding
+	BandwidthDown key.Binding
+	NewTab        key.Binding
+	CloseTab      key.Binding
+	NextTab       key.Binding
+	PrevTab       key.Binding
+	Quit          key.Binding
+}
+
+// keyAction は設定ファイルで使う操作名と、キー操作の初期値です
+type keyAction struct {
+	name    string
+	keys    []string
+	desc    string // ヘルプに表示する説明のカタログキー
+	binding func(km *keyMap) *key.Binding
+}
+
+// keyActions は設定ファイルで上書きできる操作の一覧です
+var keyActions = []keyAction{
+	{"up", []string{"up", "k"}, "key.up", func(km *keyMap) *key.Binding { return &km.Up }},
+	{"down", []string{"down", "j"}, "key.down", func(km *keyMap) *key.Binding { return &km.Down }},
+	{"page_up", []string{"pgup", "ctrl+u"}, "key.page_up", func(km *keyMap) *key.Binding { return &km.PageUp }},
+	{"page_down", []string{"pgdown", "ctrl+d"}, "key.page_down", func(km *keyMap) *key.Binding { return &km.PageDown }},
+	{"top", []string{"home", "g"}, "key.top", func(km *keyMap) *key.Binding { return &km.Top }},
+	{"bottom", []string{"end", "G"}, "key.bottom", func(km *keyMap) *key.Binding { return &km.Bottom }},
+	{"select", []string{"enter"}, "key.select", func(km *keyMap) *key.Binding { return &km.Select }},
+	{"back", []string{"[", "esc"}, "key.back", func(km *keyMap) *key.Binding { return &km.Back }},
+	{"forward", []string{"]"}, "key.forward", func(km *keyMap) *key.Binding { return &km.Forward }},
+	{"filter", []string{"/"}, "key.filter", func(km *keyMap) *key.Binding { return &km.Filter }},
+	{"match_mode", []string{"ctrl+f"}, "key.match_mode", func(km *keyMap) *key.Binding { return &km.MatchMode }},
+	{"search", []string{"ctrl+s"}, "key.search", func(km *keyMap) *key.Binding { return &km.Search }},
+	{"cancel_search", []string{"ctrl+x"}, "key.cancel_search", func(km *keyMap) *key.Binding { return &km.CancelSearch }},
+	{"help", []string{"?"}, "key.help", func(km *keyMap) *key.Binding { return &km.Help }},
+	{"bandwidth_up", []string{"+"}, "key.bandwidth_up", func(km *keyMap) *key.Binding { return &km.BandwidthUp }},
+	{"bandwidth_down", []string{"-"}, "key.bandwidth_down", func(km *keyMap) *key.Binding { return &km.BandwidthDown }},
+	{"create_bucket", []string{"N"}, "key.create_bucket", func(km *keyMap) *key.Binding { return &km.CreateBucket }},
+	{"delete_bucket", []string{"X"}, "key.delete_bucket", func(km *keyMap) *key.Binding { return &km.DeleteBucket }},
+	{"uploads", []string{"U"}, "key.uploads", func(km *keyMap) *key.Binding { return &km.Uploads }},
+	{"usage", []string{"z"}, "key.usage", func(km *keyMap) *key.Binding { return &km.Usage }},
+	{"export", []string{"E"}, "key.export", func(km *keyMap) *key.Binding { return &km.Export }},
+	{"toggle_select", []string{" "}, "key.toggle_select", func(km *keyMap) *key.Binding { return &km.ToggleSelect }},
+	{"abort", []string{"D"}, "key.abort", func(km *keyMap) *key.Binding { return &km.Abort }},
+	{"abort_older", []string{"O"}, "key.abort_older", func(km *keyMap) *key.Binding { return &km.AbortOlder }},
+	{"settings", []string{"S"}, "key.settings", func(km *keyMap) *key.Binding { return &km.Settings }},
+	{"details", []string{"i"}, "key.details", func(km *keyMap) *key.Binding { return &km.Details }},
+	{"edit", []string{"e"}, "key.edit", func(km *keyMap) *key.Binding { return &km.Edit }},
+	{"open_with", []string{"o"}, "key.open_with", func(km *keyMap) *key.Binding { return &km.OpenWith }},
+	{"bookmark", []string{"b"}, "key.bookmark", func(km *keyMap) *key.Binding { return &km.Bookmark }},
+	{"bookmarks", []string{"B"}, "key.bookmarks", func(km *keyMap) *key.Binding { return &km.Bookmarks }},
+	{"goto", []string{":"}, "key.goto", func(km *keyMap) *key.Binding { return &km.GoTo }},
+	{"refresh", []string{"ctrl+r"}, "key.refresh", func(km *keyMap) *key.Binding { return &km.Refresh }},
+	{"new_tab", []string{"t"}, "key.new_tab", func(km *keyMap) *key.Binding { return &km.NewTab }},
+	{"close_tab", []string{"ctrl+w"}, "key.close_tab", func(km *keyMap) *key.Binding { return &km.CloseTab }},
+	{"next_tab", []string{"tab"}, "key.next_tab", func(km *keyMap) *key.Binding { return &km.NextTab }},
+	{"prev_tab", []string{"shift+tab"}, "key.prev_tab", func(km *keyMap) *key.Binding { return &km.PrevTab }},
+	{"logs", []string{"L"}, "key.logs", func(km *keyMap) *key.Binding { return &km.Logs }},
+	{"quit", []string{"ctrl+c", "q"}, "key.quit", func(km *keyMap) *key.Binding { return &km.Quit }},
+}
+
+// newKeyMap は初期値に設定ファイルの上書きを適用したキー操作を作成します
+func newKeyMap(overrides map[string][]string) (keyMap, error) {
+	var km keyMap
+	for _, action := range keyActions {
+		*action.binding(&km) = newBinding(action.keys, i18n.T(action.desc))
+	}
+
+	// エラーメッセージを安定させるため、操作名の順に適用する
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		action, ok := findKeyAction(name)
+		if !ok {
+			return keyMap{}, i18n.Errorf("config.unknown_keybinding", name, strings.Join(keyActionNames(), ", "))
+		}
+		keys := overrides[name]
+		if len(keys) == 0 {
+			return keyMap{}, i18n.Errorf("config.keybinding_without_keys", name)
+		}
+		*action.binding(&km) = newBinding(keys, i18n.T(action.desc))
+	}
+	return km, nil
+}
+
+// ValidateKeybindings は設定ファイルのkeybindingsを検証します
+func ValidateKeybindings(overrides map[string][]string) error {
+	_, err := newKeyMap(overrides)
+	return err
+}
+
+// newBinding はキーの一覧からヘルプ表示付きのBindingを作成します
+func newBinding(keys []string, desc string) key.Binding {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = keyLabel(k)
+	}
+	return key.NewBinding(
+		key.WithKeys(keys...),
+		key.WithHelp(strings.Join(labels, "/"), desc),
+	)
+}
+
+// keyLabel はヘルプに表示するキーの名前を返します
+func keyLabel(k string) string {
+	switch k {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	case " ":
+		return "space"
+	}
+	return k
+}
+
+func findKeyAction(name string) (keyAction, bool) {
+	for _, action := range keyActions {
+		if action.name == name {
+			return action, true
+		}
+	}
+	return keyAction{}, false
+}
+
+func keyActionNames() []string {
+	names := make([]string, len(keyActions))
+	for i, action := range keyActions {
+		names[i] = action.name
+	}
+	return names
+}
+
+// isTextInput は文字入力のキーかどうかを返します（フィルター入力中はフィルターに渡す）
+func isTextInput(msg tea.KeyMsg) bool {
+	return msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace
+}
+
+// helpKeyMap は表示状態に応じたヘルプの内容です（help.KeyMapを実装します）
+type helpKeyMap struct {
+	short []key.Binding
+	full  [][]key.Binding
+}
+
+// ShortHelp はフッターに表示するキー操作を返します
+func (h helpKeyMap) ShortHelp() []key.Binding {
+	return h.short
+}
+
+// FullHelp はヘルプ画面に表示するキー操作を返します
+func (h helpKeyMap) FullHelp() [][]key.Binding {
+	return h.full
+}
+
+// helpKeys は現在の表示状態で使えるキー操作を返します
+func (m UIModel) helpKeys() helpKeyMap {
+	km := m.keys
+	if m.filterInput.Focused() {
+		confirm := newBinding([]string{"enter", "esc"}, i18n.T("key.confirm_filter"))
+		return helpKeyMap{
+			short: []key.Binding{confirm, km.MatchMode, km.Quit},
+			full:  [][]key.Binding{{confirm, km.MatchMode, km.Quit}},
+		}
+	}
+
+	navigation := []key.Binding{km.Up, km.Down, km.PageUp, km.PageDown, km.Top, km.Bottom}
+	// 戻る・進む先がある場合だけ表示する
+	back := km.Back
+	back.SetEnabled(len(m.history.back) > 0)
+	forward := km.Forward
+	forward.SetEnabled(len(m.history.forward) > 0)
+	// タブの切り替えと閉じる操作は、タブが2つ以上ある場合だけ表示する
+	general := []key.Binding{km.Help, km.Logs, km.Quit, km.NewTab}
+	for _, b := range []key.Binding{km.NextTab, km.PrevTab, km.CloseTab} {
+		b.SetEnabled(len(m.tabs) > 1)
+		general = append(general, b)
+	}
+	if m.state == BucketsView {
+		// S3クライアントがない間は作成・削除できない
+		create, remove := km.CreateBucket, km.DeleteBucket
+		create.SetEnabled(m.s3Client != nil)
+		remove.SetEnabled(m.s3Client != nil)
+		return helpKeyMap{
+			short: []key.Binding{km.Up, km.Down, km.Select, km.Filter, km.Help, km.Quit},
+			full: [][]key.Binding{
+				navigation,
+				{km.Select, km.Filter, km.MatchMode, km.Refresh, km.Uploads, km.Usage, km.Settings, km.Bookmarks},
+				{km.GoTo, back, forward, create, remove},
+				general,
+			},
+		}
+	}
+	if m.state == UploadsView {
+		return helpKeyMap{
+			short: []key.Binding{km.Up, km.Down, km.ToggleSelect, km.Abort, km.AbortOlder, km.Back, km.Help, km.Quit},
+			full: [][]key.Binding{
+				navigation,
+				{km.ToggleSelect, km.Abort, km.AbortOlder, km.Back},
+				general,
+			},
+		}
+	}
+
+	if m.state == UsageView {
+		open := km.Select
+		open.SetHelp(open.Help().Key, i18n.T("key.open_prefix"))
+		cancel := km.CancelSearch
+		cancel.SetHelp(cancel.Help().Key, i18n.T("key.cancel_scan"))
+		cancel.SetEnabled(m.usage.running)
+		rescan := km.Refresh
+		rescan.SetHelp(rescan.Help().Key, i18n.T("key.rescan"))
+		rescan.SetEnabled(!m.usage.running)
+		return helpKeyMap{
+			short: []key.Binding{km.Up, km.Down, open, km.Back, cancel, km.Export, km.Help, km.Quit},
+			full: [][]key.Binding{
+				navigation,
+				{open, km.Back, cancel, rescan, km.Export},
+				general,
+			},
+		}
+	}
+
+	download := km.Select
+	download.SetHelp(download.Help().Key, i18n.T("key.download"))
+	if len(m.history.back) == 0 {
+		// 履歴がなければバケット一覧に戻る
+		back = km.Back
+		back.SetHelp(back.Help().Key, i18n.T("key.back_to_buckets"))
+	}
+	cancel := km.CancelSearch
+	cancel.SetEnabled(m.search.running)
+	return helpKeyMap{
+		short: []key.Binding{km.Up, km.Down, download, km.Help, km.Filter, km.Search, cancel, back, km.Quit},
+		full: [][]key.Binding{
+			navigation,
+			{download, km.Details, km.Edit, km.OpenWith, km.Settings, km.Bookmark, km.Bookmarks, km.GoTo},
+			{km.Filter, km.MatchMode, km.Search, cancel, km.Refresh, back, forward, km.Uploads, km.Usage, km.BandwidthUp, km.BandwidthDown},
+			general,
+		},
+	}
+}
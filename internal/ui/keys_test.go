@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// TestNewKeyMapOverrides は設定ファイルによるキー割り当ての上書きをテストします
+func TestNewKeyMapOverrides(t *testing.T) {
+	km, err := newKeyMap(map[string][]string{"down": {"n", "down"}})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}, km.Down) {
+		t.Error("上書きしたキーが一致しませんでした")
+	}
+	if key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}, km.Down) {
+		t.Error("上書き前のキーが残っています")
+	}
+	// ヘルプの表示もキー割り当てから生成される
+	if got := km.Down.Help().Key; got != "n/↓" {
+		t.Errorf("ヘルプのキー表示: 期待結果 %q, 実際の結果 %q", "n/↓", got)
+	}
+}
+
+// TestNewKeyMapErrors は不正なキー割り当てがエラーになることをテストします
+func TestNewKeyMapErrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides map[string][]string
+		message   string
+	}{
+		{name: "不明な操作", overrides: map[string][]string{"jump": {"x"}}, message: `不明な操作 "jump"`},
+		{name: "キーなし", overrides: map[string][]string{"quit": {}}, message: "keybindings.quit"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateKeybindings(tc.overrides)
+			if err == nil || !strings.Contains(err.Error(), tc.message) {
+				t.Errorf("エラーメッセージに %q が含まれていません: %v", tc.message, err)
+			}
+		})
+	}
+}
+
+// TestKeyActionDescriptions はすべての操作の説明がカタログにあることをテストします
+func TestKeyActionDescriptions(t *testing.T) {
+	for _, action := range keyActions {
+		if !i18n.Has(action.desc) {
+			t.Errorf("操作 %s の説明 %q がカタログにありません", action.name, action.desc)
+		}
+	}
+}
+
+// TestFilterFocusMode はフィルター入力中とそれ以外で文字キーの扱いが変わることをテストします
+func TestFilterFocusMode(t *testing.T) {
+	m := newTestModel(t, []string{"alpha", "beta", "gamma", "jazz"})
+
+	// フィルター外では j/k/G/g がカーソル移動になる
+	m = press(m, "j", "j")
+	if m.bucketModel.Cursor != 2 {
+		t.Errorf("カーソル位置: 期待結果 %d, 実際の結果 %d", 2, m.bucketModel.Cursor)
+	}
+	m = press(m, "G")
+	if m.bucketModel.Cursor != 3 {
+		t.Errorf("カーソル位置: 期待結果 %d, 実際の結果 %d", 3, m.bucketModel.Cursor)
+	}
+	m = press(m, "g")
+	if m.bucketModel.Cursor != 0 {
+		t.Errorf("カーソル位置: 期待結果 %d, 実際の結果 %d", 0, m.bucketModel.Cursor)
+	}
+
+	// "/" でフィルターに入ると、文字はフィルターに入力される
+	m = press(m, "/", "j", "a")
+	if !m.filterInput.Focused() {
+		t.Fatal("フィルターにフォーカスされていません")
+	}
+	if m.filterInput.Value() != "ja" {
+		t.Errorf("フィルターの値: 期待結果 %q, 実際の結果 %q", "ja", m.filterInput.Value())
+	}
+	if len(m.bucketModel.FilteredBuckets) != 1 || m.bucketModel.FilteredBuckets[0] != "jazz" {
+		t.Errorf("絞り込み結果が期待と異なります: %v", m.bucketModel.FilteredBuckets)
+	}
+
+	// Escでフィルター入力を終えても、絞り込みは維持される
+	m = press(m, "esc")
+	if m.filterInput.Focused() {
+		t.Error("フィルターのフォーカスが外れていません")
+	}
+	if m.filterInput.Value() != "ja" {
+		t.Errorf("フィルターの値が失われました: %q", m.filterInput.Value())
+	}
+
+	// "?" でヘルプを開閉する
+	m = press(m, "?")
+	if !m.showHelp {
+		t.Error("ヘルプが表示されていません")
+	}
+	m = press(m, "?")
+	if m.showHelp {
+		t.Error("ヘルプが閉じられていません")
+	}
+}
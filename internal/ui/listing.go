@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/cache"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// listingState は表示しているオブジェクト一覧の取得の状態です
+type listingState struct {
+	fetchedAt  time.Time // 一覧を取得した日時
+	cached     bool      // キャッシュから表示した一覧か
+	stale      bool      // キャッシュの有効期限を過ぎているか
+	refreshing bool      // バックグラウンドで取り直しているか
+}
+
+// fetchObjects はバケット内のプレフィックス配下のオブジェクト一覧を取得し、キャッシュに記録します。
+// 取得中にオブジェクトを書き換えてバケットのキャッシュが捨てられた場合は、取得した古い一覧を記録しません。
+func (m UIModel) fetchObjects(bucketName, prefix string) tea.Cmd {
+	client, listings, logger := m.s3Client, m.listings, m.logger
+	var gen uint64
+	if listings != nil {
+		gen = listings.Generation(bucketName)
+	}
+	return func() tea.Msg {
+		objects, err := client.ListObjects(context.Background(), bucketName, prefix)
+		msg := objectsMsg{bucket: bucketName, prefix: prefix, objects: objects, fetchedAt: time.Now(), err: err}
+		if err == nil && listings != nil {
+			l := cache.Listing{Objects: objects, FetchedAt: msg.fetchedAt}
+			if ok, err := listings.PutIfCurrent(bucketName, prefix, gen, l); err != nil {
+				logger.Warn("failed to write listing cache", "bucket", bucketName, "prefix", prefix, "error", err)
+			} else if !ok {
+				logger.Debug("discarded listing fetched before invalidation", "bucket", bucketName, "prefix", prefix)
+			}
+		}
+		return msg
+	}
+}
+
+// loadListing はキャッシュした一覧を読み込みます。キャッシュを使わない場合はすぐに取得します。
+// ディスクからの読み込みは時間がかかることがあるため、バックグラウンドで行います。
+func (m UIModel) loadListing(bucket, prefix string) (UIModel, tea.Cmd) {
+	m.listing = listingState{refreshing: true}
+	if m.listings == nil {
+		return m, m.fetchObjects(bucket, prefix)
+	}
+	listings := m.listings
+	return m, func() tea.Msg {
+		l, ok := listings.Get(bucket, prefix)
+		return listingLoadedMsg{bucket: bucket, prefix: prefix, listing: l, ok: ok}
+	}
+}
+
+// handleListingLoaded はキャッシュした一覧があればすぐに表示し、なければ取得します。
+// キャッシュが有効期限を過ぎている場合は、古いことを示しながらバックグラウンドで取り直します。
+func (m UIModel) handleListingLoaded(msg listingLoadedMsg) (UIModel, tea.Cmd) {
+	if !m.showingListing(msg.bucket, msg.prefix) {
+		return m, nil
+	}
+	if msg.ok {
+		m = m.setObjects(msg.listing.Objects)
+		stale := m.listings.Stale(msg.listing)
+		m.listing = listingState{fetchedAt: msg.listing.FetchedAt, cached: true, stale: stale, refreshing: stale}
+		if !stale {
+			return m, nil
+		}
+	}
+	return m, m.fetchObjects(msg.bucket, msg.prefix)
+}
+
+// showingListing はバケットのプレフィックス配下の一覧を表示しているかを返します。検索結果の表示中は含みません
+func (m UIModel) showingListing(bucket, prefix string) bool {
+	return m.state == ObjectsView && m.search.progress == nil &&
+		bucket == m.objectModel.BucketName && prefix == m.objectModel.Prefix
+}
+
+// refreshListing は表示しているオブジェクト一覧をキャッシュを使わずに取り直します
+func (m UIModel) refreshListing() (UIModel, tea.Cmd) {
+	m.listing.refreshing = true
+	return m, m.fetchObjects(m.objectModel.BucketName, m.objectModel.Prefix)
+}
+
+// handleObjects は取得したオブジェクト一覧を表示します。
+// 既に別の場所を開いている場合や検索結果を表示している場合は、キャッシュへの記録だけで終わります。
+func (m UIModel) handleObjects(msg objectsMsg) UIModel {
+	if !m.showingListing(msg.bucket, msg.prefix) {
+		return m
+	}
+	m.listing.refreshing = false
+	if msg.err != nil {
+		// 一覧を取得できなくても、画面は離れずにエラーを表示する
+		m.logger.Error("listing objects failed", "bucket", msg.bucket, "prefix", msg.prefix, "error", msg.err)
+		m.objectOp = objectOpState{err: msg.err}
+		m.pending = nil
+		return m
+	}
+	m.rememberBucket(msg.bucket)
+	m.listing = listingState{fetchedAt: msg.fetchedAt}
+	return m.setObjects(msg.objects)
+}
+
+// setObjects はオブジェクト一覧を置き換えます。
+// 場所を開いたときは復元する表示位置に、取り直したときはカーソルが動かないよう今の表示位置に合わせます。
+func (m UIModel) setObjects(objects []aws.ObjectInfo) UIModel {
+	o := m.objectModel
+	pos := listPosition(m.filterInput.Value(), o.FilteredObjects, o.Cursor, o.Offset)
+	if m.pending != nil {
+		pos = *m.pending
+		m.pending = nil
+	}
+
+	keys := make([]string, len(objects))
+	details := make(map[string]aws.ObjectInfo, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+		details[obj.Key] = obj
+	}
+	m.objectModel.Objects = keys
+	m.objectModel.Details = details
+	m.objectModel.FilteredObjects = keys
+	m.objectModel.Cursor = 0
+	m.applyFilter()
+	m.objectModel.Cursor, m.objectModel.Offset = pos.locate(m.objectModel.FilteredObjects)
+	return m
+}
+
+// invalidateListings はオブジェクトを書き換えたバケットのキャッシュを捨てます
+func (m UIModel) invalidateListings(bucket string) {
+	if m.listings == nil {
+		return
+	}
+	if err := m.listings.InvalidateBucket(bucket); err != nil {
+		m.logger.Warn("failed to invalidate listing cache", "bucket", bucket, "error", err)
+	}
+}
+
+// renderListingStatus はキャッシュから表示した一覧が古いことや、取り直していることを1行で返します
+func (m UIModel) renderListingStatus() string {
+	l := m.listing
+	if !l.cached || !l.stale {
+		return ""
+	}
+	age := formatAge(time.Since(l.fetchedAt))
+	if l.refreshing {
+		return i18n.T("listing.refreshing", age)
+	}
+	return i18n.T("listing.stale", age, m.keys.Refresh.Help().Key)
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/cache"
+	"github.com/tsuna-can/s3-cli/internal/state"
+)
+
+// TestLoadListing はキャッシュした一覧をバックグラウンドで読み込んで表示し、古い場合だけ取り直すことをテストします
+func TestLoadListing(t *testing.T) {
+	objects := []aws.ObjectInfo{{Key: "logs/a.log"}, {Key: "logs/b.log"}}
+	tests := []struct {
+		name    string
+		age     time.Duration
+		cached  bool
+		refresh bool
+	}{
+		{name: "キャッシュなし", refresh: true},
+		{name: "有効期限内のキャッシュ", age: time.Minute, cached: true},
+		{name: "有効期限を過ぎたキャッシュ", age: time.Hour, cached: true, refresh: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, []string{"alpha", "beta"})
+			m.listings = cache.New("", 5*time.Minute, "", "")
+			if tt.cached {
+				if err := m.listings.Put("alpha", "logs/", cache.Listing{Objects: objects, FetchedAt: time.Now().Add(-tt.age)}); err != nil {
+					t.Fatalf("予期しないエラー: %v", err)
+				}
+			}
+
+			m, cmd := m.openLocation(state.Location{Bucket: "alpha", Prefix: "logs/"})
+			loaded, ok := cmd().(listingLoadedMsg)
+			if !ok || loaded.ok != tt.cached {
+				t.Fatalf("期待結果 %v, 実際の結果 %v", tt.cached, loaded.ok)
+			}
+			m, cmd = m.handleListingLoaded(loaded)
+			if (cmd != nil) != tt.refresh || m.listing.refreshing != tt.refresh {
+				t.Errorf("取り直し: 期待結果 %v, 実際の結果 %v", tt.refresh, cmd != nil)
+			}
+			expected := 0
+			if tt.cached {
+				expected = len(objects)
+			}
+			if len(m.objectModel.FilteredObjects) != expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", expected, m.objectModel.FilteredObjects)
+			}
+			// 古いキャッシュを表示している間だけ、そのことを示す
+			if stale := m.renderListingStatus() != ""; stale != (tt.cached && tt.refresh) {
+				t.Errorf("古い一覧の表示: 期待結果 %v, 実際の結果 %v", tt.cached && tt.refresh, stale)
+			}
+		})
+	}
+}
+
+// TestListingLoadedElsewhere は読み込み中に別の場所を開いた場合に、読み込んだ一覧を表示しないことをテストします
+func TestListingLoadedElsewhere(t *testing.T) {
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m.listings = cache.New("", 5*time.Minute, "", "")
+	if err := m.listings.Put("alpha", "logs/", cache.Listing{Objects: []aws.ObjectInfo{{Key: "logs/a.log"}}, FetchedAt: time.Now()}); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	m, cmd := m.openLocation(state.Location{Bucket: "alpha", Prefix: "logs/"})
+	loaded := cmd()
+	m, _ = m.openLocation(state.Location{Bucket: "alpha", Prefix: "tmp/"})
+
+	m, cmd = m.handleListingLoaded(loaded.(listingLoadedMsg))
+	if cmd != nil || len(m.objectModel.Objects) != 0 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []string{}, m.objectModel.Objects)
+	}
+}
+
+// TestRefreshKeepsCursor は取り直した一覧でもカーソルが同じオブジェクトに残ることをテストします
+func TestRefreshKeepsCursor(t *testing.T) {
+	m, _ := newTestModel(t, []string{"alpha", "beta"}).openLocation(state.Location{Bucket: "alpha", Prefix: "logs/"})
+	m = m.handleObjects(objectsMsg{bucket: "alpha", prefix: "logs/", objects: []aws.ObjectInfo{{Key: "logs/b.log"}, {Key: "logs/c.log"}}})
+	m.objectModel.Cursor = 1
+
+	m, _ = m.refreshListing()
+	m = m.handleObjects(objectsMsg{bucket: "alpha", prefix: "logs/", objects: []aws.ObjectInfo{{Key: "logs/a.log"}, {Key: "logs/b.log"}, {Key: "logs/c.log"}}})
+	if got := m.objectModel.FilteredObjects[m.objectModel.Cursor]; got != "logs/c.log" {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "logs/c.log", got)
+	}
+	if m.listing.refreshing {
+		t.Error("取り直しが終わっていません")
+	}
+
+	// 別の場所の一覧は表示しない
+	m = m.handleObjects(objectsMsg{bucket: "alpha", prefix: "tmp/", objects: []aws.ObjectInfo{{Key: "tmp/x"}}})
+	if len(m.objectModel.Objects) != 3 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", 3, m.objectModel.Objects)
+	}
+}
+
+// TestRefreshBucketsWithoutClient はS3クライアントの初期化に失敗した後の取り直しで、初期化からやり直すことをテストします
+func TestRefreshBucketsWithoutClient(t *testing.T) {
+	_, cmd := newTestModel(t, []string{"alpha", "beta"}).handleKeyMsg(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if cmd == nil {
+		t.Fatal("初期化が始まっていません")
+	}
+	msg := cmd()
+	if _, ok := msg.(s3ClientInitMsg); !ok {
+		t.Errorf("期待結果 %T, 実際の結果 %T", s3ClientInitMsg{}, msg)
+	}
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/model"
+	"github.com/tsuna-can/s3-cli/internal/state"
+)
+
+// currentLocation はオブジェクト一覧で表示している場所を返します。
+// サーバー側検索の結果を表示している場合は、検索したプレフィックスを場所とします。
+func (s session) currentLocation() state.Location {
+	prefix := s.objectModel.Prefix
+	if s.search.progress != nil {
+		prefix = s.search.prefix
+	}
+	return state.Location{Bucket: s.objectModel.BucketName, Prefix: prefix}
+}
+
+// openLocation はオブジェクト一覧でバケットのプレフィックス配下を開き、最近開いた場所に記録します
+func (m UIModel) openLocation(loc state.Location) (UIModel, tea.Cmd) {
+	return m.showLocation(loc, viewPosition{})
+}
+
+// showLocation は場所を開き、一覧を取得したら pos の表示位置に合わせます
+func (m UIModel) showLocation(loc state.Location, pos viewPosition) (UIModel, tea.Cmd) {
+	m.resetSearch()
+	m.state = ObjectsView
+	m.objectModel = model.ObjectListModel{BucketName: loc.Bucket, Prefix: loc.Prefix}
+	m.objectOp = objectOpState{}
+	m.setFilter(pos.filter, i18n.T("ui.filter_objects"))
+	m.applyFilter()
+	m.pending = &pos
+
+	if m.store != nil {
+		if err := m.store.Visit(loc); err != nil {
+			m.logger.Warn("failed to record recent location", "location", loc.String(), "error", err)
+		}
+	}
+	return m.loadListing(loc.Bucket, loc.Prefix)
+}
+
+// goToLocation は起動時や移動先の入力で指定された場所を開きます。
+// プレフィックスが "/" で終わらない場合はオブジェクトのキーかもしれないため、先に HeadObject で確かめます。
+func (m UIModel) goToLocation(loc state.Location) (UIModel, tea.Cmd) {
+	if loc.Prefix == "" || strings.HasSuffix(loc.Prefix, "/") {
+		return m.openLocation(loc)
+	}
+	client := m.s3Client
+	return m, func() tea.Msg {
+		info, err := client.DescribeObject(context.Background(), loc.Bucket, loc.Prefix)
+		return locationProbeMsg{location: loc, info: info, err: err}
+	}
+}
+
+// handleLocationProbe は、指定された場所がオブジェクトならその階層を開いて詳細画面を表示し、
+// そうでなければプレフィックスとして開きます
+func (m UIModel) handleLocationProbe(msg locationProbeMsg) (UIModel, tea.Cmd) {
+	loc := msg.location
+	if msg.err != nil {
+		m.logger.Debug("location is not an object, opening it as a prefix", "location", loc.String(), "error", msg.err)
+		return m.openLocation(loc)
+	}
+
+	key := loc.Prefix
+	parent := state.Location{Bucket: loc.Bucket}
+	if dir := path.Dir(key); dir != "." {
+		parent.Prefix = dir + "/"
+	}
+	// オブジェクトにカーソルを合わせる
+	m, cmd := m.showLocation(parent, viewPosition{key: key})
+	m.details = &detailsState{bucket: loc.Bucket, key: key, info: msg.info}
+	return m, cmd
+}
+
+// openGoTo は移動先のバケットやプレフィックスを尋ねます。
+// ListBuckets が許可されていない場合も、名前を入力すればバケットを開けます。
+func (m UIModel) openGoTo(value string, err error) UIModel {
+	m.prompt = m.newInputPrompt(i18n.T("location.goto"), "bucket/prefix/", value,
+		func(m UIModel, value string) (UIModel, tea.Cmd) {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return m, nil
+			}
+			loc, err := parseGoTo(value)
+			if err != nil {
+				return m.openGoTo(value, err), nil
+			}
+			return m.pushHistory().goToLocation(loc)
+		})
+	m.prompt.err = err
+	return m
+}
+
+// parseGoTo は "bucket/prefix/" または "s3://bucket/prefix/" の形式の移動先を場所に変換します
+func parseGoTo(value string) (state.Location, error) {
+	if !strings.HasPrefix(value, "s3://") {
+		value = "s3://" + value
+	}
+	return state.ParseLocation(value)
+}
+
+// knownBuckets は開けたことのあるバケットを返します。ListBuckets が失敗したときに一覧の代わりに使います
+func knownBuckets(store *state.Store) []string {
+	if store == nil {
+		return []string{}
+	}
+	return append([]string{}, store.KnownBuckets()...)
+}
+
+// rememberBucket はオブジェクト一覧を取得できたバケットを記録します
+func (m UIModel) rememberBucket(bucket string) {
+	if m.store == nil || bucket == "" {
+		return
+	}
+	if err := m.store.RememberBucket(bucket); err != nil {
+		m.logger.Warn("failed to remember bucket", "bucket", bucket, "error", err)
+	}
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/state"
+)
+
+// TestHandleLocationProbe は指定された場所がオブジェクトかどうかで開く場所が変わることをテストします
+func TestHandleLocationProbe(t *testing.T) {
+	tests := []struct {
+		name        string
+		msg         locationProbeMsg
+		prefix      string
+		showDetails bool
+	}{
+		{
+			name:        "階層の中のオブジェクト",
+			msg:         locationProbeMsg{location: state.Location{Bucket: "alpha", Prefix: "logs/2026/app.log"}, info: aws.ObjectDetails{ContentType: "text/plain"}},
+			prefix:      "logs/2026/",
+			showDetails: true,
+		},
+		{
+			name:        "バケット直下のオブジェクト",
+			msg:         locationProbeMsg{location: state.Location{Bucket: "alpha", Prefix: "README.md"}},
+			prefix:      "",
+			showDetails: true,
+		},
+		{
+			name:   "オブジェクトがない場合はプレフィックスとして開く",
+			msg:    locationProbeMsg{location: state.Location{Bucket: "alpha", Prefix: "logs"}, err: errors.New("not found")},
+			prefix: "logs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, cmd := newTestModel(t, []string{"alpha", "beta"}).handleLocationProbe(tt.msg)
+			if m.state != ObjectsView || m.objectModel.BucketName != "alpha" || cmd == nil {
+				t.Fatalf("場所を開けませんでした: %v %+v", m.state, m.objectModel)
+			}
+			if m.objectModel.Prefix != tt.prefix {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tt.prefix, m.objectModel.Prefix)
+			}
+			if (m.details != nil) != tt.showDetails {
+				t.Fatalf("期待結果 %v, 実際の結果 %v", tt.showDetails, m.details != nil)
+			}
+			if tt.showDetails && (m.details.key != tt.msg.location.Prefix || m.details.info.ContentType != tt.msg.info.ContentType || m.details.loading) {
+				t.Errorf("期待結果 %v, 実際の結果 %+v", tt.msg, m.details)
+			}
+		})
+	}
+}
+
+// TestFocusKey はオブジェクト一覧の取得後に、起動時に指定されたオブジェクトにカーソルが合うことをテストします
+func TestFocusKey(t *testing.T) {
+	m, _ := newTestModel(t, []string{"alpha", "beta"}).handleLocationProbe(locationProbeMsg{location: state.Location{Bucket: "alpha", Prefix: "logs/b.log"}})
+	model, _ := m.Update(objectsMsg{bucket: "alpha", prefix: "logs/", objects: []aws.ObjectInfo{{Key: "logs/a.log"}, {Key: "logs/b.log"}, {Key: "logs/c.log"}}})
+	m = model.(UIModel)
+	if m.objectModel.Cursor != 1 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", 1, m.objectModel.Cursor)
+	}
+	if m.pending != nil {
+		t.Errorf("カーソルを合わせた後も表示位置が残っています: %v", *m.pending)
+	}
+}
+
+// TestListBucketsFallback は ListBuckets が拒否された場合に、開けたことのあるバケットを表示することをテストします
+func TestListBucketsFallback(t *testing.T) {
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.json"), "restricted", "")
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m.store = store
+	m, _ = m.openLocation(state.Location{Bucket: "logs"})
+
+	// オブジェクト一覧を取得できたバケットを記録する
+	model, _ := m.Update(objectsMsg{bucket: "logs", objects: []aws.ObjectInfo{{Key: "app.log"}}})
+	m = model.(UIModel)
+	if got := store.KnownBuckets(); !reflect.DeepEqual(got, []string{"logs"}) {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []string{"logs"}, got)
+	}
+
+	denied := &aws.AccessDeniedError{Action: "s3:ListAllMyBuckets", Resource: "arn:aws:s3:::*"}
+	m.state = BucketsView
+	model, _ = m.Update(bucketsMsg{err: denied})
+	m = model.(UIModel)
+	if !reflect.DeepEqual(m.bucketModel.FilteredBuckets, []string{"logs"}) {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []string{"logs"}, m.bucketModel.FilteredBuckets)
+	}
+	if m.msg != "" || m.bucketOp.err != denied {
+		t.Errorf("エラーが一覧の上に表示されていません: %q %v", m.msg, m.bucketOp.err)
+	}
+}
+
+// TestObjectsError はオブジェクト一覧を取得できない場合も画面を離れずにエラーを表示することをテストします
+func TestObjectsError(t *testing.T) {
+	m, _ := newTestModel(t, []string{"alpha", "beta"}).openLocation(state.Location{Bucket: "secret"})
+	denied := &aws.AccessDeniedError{Action: "s3:ListBucket", Resource: "arn:aws:s3:::secret"}
+	model, _ := m.Update(objectsMsg{bucket: "secret", err: denied})
+	m = model.(UIModel)
+	if m.state != ObjectsView || m.msg != "" || m.objectOp.err != denied {
+		t.Errorf("エラーがオブジェクト一覧の上に表示されていません: %v %q %v", m.state, m.msg, m.objectOp.err)
+	}
+}
+
+// TestParseGoTo は移動先の入力を場所に変換することをテストします
+func TestParseGoTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected state.Location
+		wantErr  bool
+	}{
+		{name: "バケット名のみ", value: "logs", expected: state.Location{Bucket: "logs"}},
+		{name: "プレフィックス", value: "logs/2026/", expected: state.Location{Bucket: "logs", Prefix: "2026/"}},
+		{name: "URI", value: "s3://logs/2026/app.log", expected: state.Location{Bucket: "logs", Prefix: "2026/app.log"}},
+		{name: "バケット名なし", value: "/2026/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGoTo(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("エラーが返されませんでした: %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tt.expected, got)
+			}
+		})
+	}
+}
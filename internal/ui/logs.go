@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// logRefreshInterval はログ画面を再描画する間隔です
+const logRefreshInterval = 500 * time.Millisecond
+
+// openLogs はログ画面を開き、定期的な再描画を始めます
+func (m UIModel) openLogs() (UIModel, tea.Cmd) {
+	m.showLogs = true
+	m.logScroll = 0
+	m.logTick++
+	return m, tickLogs(m.logTick)
+}
+
+// tickLogs は一定時間後にログ画面の再描画メッセージを送るコマンドを返します
+func tickLogs(id int) tea.Cmd {
+	return tea.Tick(logRefreshInterval, func(time.Time) tea.Msg {
+		return logTickMsg{id: id}
+	})
+}
+
+// handleLogTick はログ画面を開いている間だけ再描画を続けます
+func (m UIModel) handleLogTick(msg logTickMsg) tea.Cmd {
+	if !m.showLogs || msg.id != m.logTick {
+		return nil
+	}
+	return tickLogs(msg.id)
+}
+
+// handleLogsKey はログ画面表示中のキー入力を処理します。
+// スクロールはカーソル移動と同じキーで行います。
+func (m UIModel) handleLogsKey(msg tea.KeyMsg) UIModel {
+	km := m.keys
+	page := m.logHeight()
+	switch {
+	case key.Matches(msg, km.Logs, km.Back, km.Quit):
+		m.showLogs = false
+	case key.Matches(msg, km.Up):
+		m.logScroll++
+	case key.Matches(msg, km.Down):
+		m.logScroll--
+	case key.Matches(msg, km.PageUp):
+		m.logScroll += page
+	case key.Matches(msg, km.PageDown):
+		m.logScroll -= page
+	case key.Matches(msg, km.Top):
+		m.logScroll = len(m.logLines())
+	case key.Matches(msg, km.Bottom):
+		m.logScroll = 0
+	}
+	m.logScroll = m.clampLogScroll(m.logScroll)
+	return m
+}
+
+// logLines は表示するログの行を返します
+func (m UIModel) logLines() []string {
+	if m.logger == nil || m.logger.Recent == nil {
+		return nil
+	}
+	return m.logger.Recent.Lines()
+}
+
+// logHeight はログ画面に表示できる行数を返します
+func (m UIModel) logHeight() int {
+	height := m.height - 7 // タイトル・ファイル名・枠線・操作説明のスペースを考慮
+	if height < 1 {
+		height = 1
+	}
+	return height
+}
+
+// clampLogScroll はスクロール位置をログの範囲内に収めます
+func (m UIModel) clampLogScroll(scroll int) int {
+	maxScroll := len(m.logLines()) - m.logHeight()
+	if scroll > maxScroll {
+		scroll = maxScroll
+	}
+	if scroll < 0 {
+		scroll = 0
+	}
+	return scroll
+}
+
+// renderLogs はログ画面を描画します。新しいログが追加されても末尾を表示し続けます
+func (m UIModel) renderLogs() string {
+	width := m.viewWidth() - 2
+	title := m.theme.HeaderTitle.Render(" " + i18n.T("ui.logs_title") + " ")
+	hint := m.theme.Dim.Render(i18n.T("ui.logs_hint",
+		m.keys.Logs.Help().Key, m.keys.Up.Help().Key, m.keys.Down.Help().Key))
+
+	if m.logger == nil || !m.logger.Enabled() {
+		body := m.theme.Dim.Render(i18n.T("ui.logs_disabled"))
+		return title + "\n\n" + m.theme.Panel.Copy().Width(width).Render(body) + "\n" + hint
+	}
+
+	lines := m.logLines()
+	end := len(lines) - m.clampLogScroll(m.logScroll)
+	start := end - m.logHeight()
+	if start < 0 {
+		start = 0
+	}
+
+	rendered := make([]string, 0, end-start)
+	for _, line := range lines[start:end] {
+		line = runewidth.Truncate(line, width, "…")
+		// 警告とエラーのレコードは目立たせる
+		if strings.Contains(line, "level=WARN") || strings.Contains(line, "level=ERROR") {
+			rendered = append(rendered, m.theme.Error.Render(line))
+		} else {
+			rendered = append(rendered, m.theme.Item.Render(line))
+		}
+	}
+	body := strings.Join(rendered, "\n")
+	if len(rendered) == 0 {
+		body = m.theme.Dim.Render(i18n.T("ui.logs_empty"))
+	}
+
+	file := m.theme.Dim.Render(runewidth.Truncate(i18n.T("ui.logs_file", m.logger.Path), m.viewWidth(), "…"))
+	return title + "\n" + file + "\n" + m.theme.Panel.Copy().Width(width).Render(body) + "\n" + hint
+}
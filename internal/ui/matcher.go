@@ -0,0 +1,324 @@
+package ui
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// MatchMode はフィルターの一致方式を表す型です
+type MatchMode int
+
+const (
+	// MatchSubstring は部分一致（大文字小文字無視）
+	MatchSubstring MatchMode = iota
+	// MatchFuzzy はfzf風のあいまい一致
+	MatchFuzzy
+	// MatchGlob はグロブパターン一致（*, **, ?, [...]）
+	MatchGlob
+	// MatchRegex は正規表現一致
+	MatchRegex
+)
+
+// String はMatchModeを文字列で返します
+func (mm MatchMode) String() string {
+	switch mm {
+	case MatchSubstring:
+		return "substr"
+	case MatchFuzzy:
+		return "fuzzy"
+	case MatchGlob:
+		return "glob"
+	case MatchRegex:
+		return "regex"
+	default:
+		return "unknown"
+	}
+}
+
+// Next は切り替え順で次の一致方式を返します
+func (mm MatchMode) Next() MatchMode {
+	return (mm + 1) % (MatchRegex + 1)
+}
+
+// matchResult は1項目分のフィルター結果です
+type matchResult struct {
+	item      string
+	positions []int // 一致した文字の位置（ルーン単位）
+	score     int   // あいまい一致のスコア（大きいほど良い）
+}
+
+// matcherFunc は1項目を判定し、一致した文字の位置を返します
+type matcherFunc func(item string) (positions []int, score int, ok bool)
+
+// compileMatcher はパターンと一致方式から判定関数を作ります
+func compileMatcher(pattern string, mode MatchMode) (matcherFunc, error) {
+	switch mode {
+	case MatchFuzzy:
+		return func(item string) ([]int, int, bool) {
+			return fuzzyMatch(item, pattern)
+		}, nil
+	case MatchGlob:
+		return compileGlob(pattern)
+	case MatchRegex:
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, i18n.Errorf("match.invalid_regex", err)
+		}
+		return func(item string) ([]int, int, bool) {
+			locs := re.FindAllStringIndex(item, -1)
+			if locs == nil {
+				return nil, 0, false
+			}
+			var positions []int
+			for _, loc := range locs {
+				positions = append(positions, runeRange(item, loc[0], loc[1])...)
+			}
+			return positions, 0, true
+		}, nil
+	default:
+		lowerPattern := strings.ToLower(pattern)
+		return func(item string) ([]int, int, bool) {
+			lowerItem := strings.ToLower(item)
+			idx := strings.Index(lowerItem, lowerPattern)
+			if idx < 0 {
+				return nil, 0, false
+			}
+			return runeRange(lowerItem, idx, idx+len(lowerPattern)), 0, true
+		}, nil
+	}
+}
+
+// matchItems は一致方式に従って項目を絞り込みます。
+// あいまい一致の場合はスコアの高い順に並べ替えます。
+func matchItems(items []string, pattern string, mode MatchMode) ([]matchResult, error) {
+	results := make([]matchResult, 0, len(items))
+	if pattern == "" {
+		for _, item := range items {
+			results = append(results, matchResult{item: item})
+		}
+		return results, nil
+	}
+
+	match, err := compileMatcher(pattern, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if positions, score, ok := match(item); ok {
+			results = append(results, matchResult{item: item, positions: positions, score: score})
+		}
+	}
+
+	if mode == MatchFuzzy {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].score > results[j].score
+		})
+	}
+	return results, nil
+}
+
+// runeRange はバイト範囲[start, end)をルーン位置の一覧に変換します
+func runeRange(s string, start, end int) []int {
+	first := utf8.RuneCountInString(s[:start])
+	count := utf8.RuneCountInString(s[start:end])
+	positions := make([]int, count)
+	for i := range positions {
+		positions[i] = first + i
+	}
+	return positions
+}
+
+// あいまい一致のスコア定数（fzfのアルゴリズムを簡略化したもの）
+const (
+	fuzzyScoreMatch        = 16
+	fuzzyBonusBoundary     = 8
+	fuzzyBonusCamel        = 7
+	fuzzyBonusConsecutive  = 4
+	fuzzyBonusFirstChar    = 2 // 先頭文字のボーナス倍率
+	fuzzyPenaltyGapStart   = 3
+	fuzzyPenaltyGapExtends = 1
+)
+
+// fuzzyMatch はpatternの各文字がitem中に順番に現れるかを判定し、位置とスコアを返します。
+// パターンに大文字が含まれる場合のみ大文字小文字を区別します（スマートケース）。
+func fuzzyMatch(item, pattern string) ([]int, int, bool) {
+	caseSensitive := strings.IndexFunc(pattern, unicode.IsUpper) >= 0
+	text := []rune(item)
+	pat := []rune(pattern)
+	if len(pat) == 0 {
+		return nil, 0, true
+	}
+
+	eq := func(a, b rune) bool {
+		if caseSensitive {
+			return a == b
+		}
+		return unicode.ToLower(a) == unicode.ToLower(b)
+	}
+
+	// 前方走査でパターン末尾が一致する位置を探す
+	pi, end := 0, -1
+	for ti := 0; ti < len(text); ti++ {
+		if eq(text[ti], pat[pi]) {
+			pi++
+			if pi == len(pat) {
+				end = ti
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return nil, 0, false
+	}
+
+	// 後方走査で最も短い一致範囲の開始位置を探す
+	pi = len(pat) - 1
+	start := end
+	for ti := end; ti >= 0; ti-- {
+		if eq(text[ti], pat[pi]) {
+			pi--
+			if pi < 0 {
+				start = ti
+				break
+			}
+		}
+	}
+
+	// 範囲内で改めて前方から位置を確定し、スコアを計算する
+	positions := make([]int, 0, len(pat))
+	score := 0
+	pi = 0
+	prev := -1
+	for ti := start; ti <= end && pi < len(pat); ti++ {
+		if !eq(text[ti], pat[pi]) {
+			continue
+		}
+		bonus := fuzzyBonusAt(text, ti)
+		if prev >= 0 {
+			if ti == prev+1 {
+				bonus += fuzzyBonusConsecutive
+			} else {
+				score -= fuzzyPenaltyGapStart + fuzzyPenaltyGapExtends*(ti-prev-2)
+			}
+		} else {
+			bonus *= fuzzyBonusFirstChar
+		}
+		score += fuzzyScoreMatch + bonus
+		positions = append(positions, ti)
+		prev = ti
+		pi++
+	}
+	return positions, score, true
+}
+
+// fuzzyBonusAt は単語の境界やキャメルケースの切れ目に当たる文字へのボーナスを返します
+func fuzzyBonusAt(text []rune, i int) int {
+	if i == 0 {
+		return fuzzyBonusBoundary
+	}
+	prev, cur := text[i-1], text[i]
+	switch {
+	case strings.ContainsRune("/-_. ", prev):
+		return fuzzyBonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return fuzzyBonusCamel
+	case !unicode.IsDigit(prev) && unicode.IsDigit(cur):
+		return fuzzyBonusCamel
+	}
+	return 0
+}
+
+// compileGlob はグロブパターンを正規表現に変換した判定関数を返します。
+// '*' と '?' は '/' をまたがず、'**' は任意の階層に一致します。
+// パターンに '/' が含まれない場合はキーの末尾の名前部分だけを対象にします。
+func compileGlob(pattern string) (matcherFunc, error) {
+	var sb strings.Builder
+	sb.WriteString("(?i)^")
+	runes := []rune(pattern)
+	var literal strings.Builder
+	flushLiteral := func() {
+		if literal.Len() > 0 {
+			// 固定文字列部分はハイライト用にグループで囲む
+			sb.WriteString("(" + regexp.QuoteMeta(literal.String()) + ")")
+			literal.Reset()
+		}
+	}
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch r {
+		case '*':
+			flushLiteral()
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				if i+1 < len(runes) && runes[i+1] == '/' {
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			flushLiteral()
+			sb.WriteString("[^/]")
+		case '[':
+			flushLiteral()
+			j := i + 1
+			if j < len(runes) && (runes[j] == '!' || runes[j] == '^') {
+				j++
+			}
+			if j < len(runes) && runes[j] == ']' {
+				j++
+			}
+			for j < len(runes) && runes[j] != ']' {
+				j++
+			}
+			if j >= len(runes) {
+				return nil, i18n.Errorf("match.glob_unclosed_bracket")
+			}
+			class := string(runes[i+1 : j])
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i = j
+		default:
+			literal.WriteRune(r)
+		}
+	}
+	flushLiteral()
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, i18n.Errorf("match.invalid_glob", err)
+	}
+
+	baseOnly := !strings.Contains(pattern, "/")
+	return func(item string) ([]int, int, bool) {
+		target, offset := item, 0
+		if baseOnly {
+			if idx := strings.LastIndex(strings.TrimSuffix(item, "/"), "/"); idx >= 0 {
+				target, offset = item[idx+1:], idx+1
+			}
+		}
+		loc := re.FindStringSubmatchIndex(target)
+		if loc == nil {
+			return nil, 0, false
+		}
+		var positions []int
+		for g := 2; g+1 < len(loc); g += 2 {
+			if loc[g] >= 0 {
+				positions = append(positions, runeRange(item, offset+loc[g], offset+loc[g+1])...)
+			}
+		}
+		return positions, 0, true
+	}, nil
+}
@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+)
+
+// TestMatchModeNext は一致方式の切り替え順をテストします
+func TestMatchModeNext(t *testing.T) {
+	expected := []MatchMode{MatchFuzzy, MatchGlob, MatchRegex, MatchSubstring}
+	mode := MatchSubstring
+	for _, want := range expected {
+		mode = mode.Next()
+		if mode != want {
+			t.Errorf("期待結果 %v, 実際の結果 %v", want, mode)
+		}
+	}
+}
+
+func TestMatchItems(t *testing.T) {
+	items := []string{
+		"logs/2026-10-01.json",
+		"logs/2026-10-02.parquet",
+		"data/part-0001.parquet",
+		"README.md",
+	}
+
+	testCases := []struct {
+		name     string
+		pattern  string
+		mode     MatchMode
+		expected []string
+	}{
+		{
+			name:     "部分一致",
+			pattern:  "PARQUET",
+			mode:     MatchSubstring,
+			expected: []string{"logs/2026-10-02.parquet", "data/part-0001.parquet"},
+		},
+		{
+			name:     "グロブ（名前部分のみ）",
+			pattern:  "*.parquet",
+			mode:     MatchGlob,
+			expected: []string{"logs/2026-10-02.parquet", "data/part-0001.parquet"},
+		},
+		{
+			name:     "グロブ（任意の階層）",
+			pattern:  "**/2026-10-*",
+			mode:     MatchGlob,
+			expected: []string{"logs/2026-10-01.json", "logs/2026-10-02.parquet"},
+		},
+		{
+			name:     "グロブ（文字クラス）",
+			pattern:  "logs/2026-10-0[!2].*",
+			mode:     MatchGlob,
+			expected: []string{"logs/2026-10-01.json"},
+		},
+		{
+			name:     "正規表現",
+			pattern:  `^data/.*\.parquet$`,
+			mode:     MatchRegex,
+			expected: []string{"data/part-0001.parquet"},
+		},
+		{
+			name:     "あいまい一致",
+			pattern:  "rdm",
+			mode:     MatchFuzzy,
+			expected: []string{"README.md"},
+		},
+		{
+			name:     "空のパターン",
+			pattern:  "",
+			mode:     MatchRegex,
+			expected: items,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := matchItems(items, tc.pattern, tc.mode)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			actual := make([]string, len(results))
+			for i, r := range results {
+				actual[i] = r.item
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// TestMatchItemsInvalidPattern は不正なパターンでエラーが返ることをテストします
+func TestMatchItemsInvalidPattern(t *testing.T) {
+	if _, err := matchItems([]string{"a"}, "(", MatchRegex); err == nil {
+		t.Error("不正な正規表現でエラーが返されませんでした")
+	}
+	if _, err := matchItems([]string{"a"}, "[abc", MatchGlob); err == nil {
+		t.Error("不正なグロブパターンでエラーが返されませんでした")
+	}
+}
+
+// TestFuzzyMatch はあいまい一致の位置とスコアの順序をテストします
+func TestFuzzyMatch(t *testing.T) {
+	positions, _, ok := fuzzyMatch("my-bucket", "mbk")
+	if !ok {
+		t.Fatal("一致するはずの項目が一致しませんでした")
+	}
+	if !reflect.DeepEqual(positions, []int{0, 3, 6}) {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []int{0, 3, 6}, positions)
+	}
+
+	if _, _, ok := fuzzyMatch("bucket", "xyz"); ok {
+		t.Error("一致しないはずの項目が一致しました")
+	}
+
+	// 単語の先頭で連続して一致する方が高いスコアになる
+	results, err := matchItems([]string{"abxcxdxexfxg", "data/cfg.yaml"}, "cfg", MatchFuzzy)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if len(results) != 2 || results[0].item != "data/cfg.yaml" {
+		t.Errorf("スコア順の並びが期待と異なります: %v", results)
+	}
+
+	// 大文字を含むパターンは大文字小文字を区別する
+	if _, _, ok := fuzzyMatch("readme", "RM"); ok {
+		t.Error("スマートケースで一致しないはずの項目が一致しました")
+	}
+}
+
+// TestMatchPositions はハイライト用の一致位置をテストします
+func TestMatchPositions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		item     string
+		pattern  string
+		mode     MatchMode
+		expected []int
+	}{
+		{name: "部分一致", item: "my-Bucket", pattern: "buck", mode: MatchSubstring, expected: []int{3, 4, 5, 6}},
+		{name: "正規表現", item: "a1b22", pattern: `\d+`, mode: MatchRegex, expected: []int{1, 3, 4}},
+		{name: "グロブの固定文字列", item: "dir/x.csv", pattern: "*.csv", mode: MatchGlob, expected: []int{5, 6, 7, 8}},
+		{name: "マルチバイト文字", item: "データ/表.csv", pattern: "表", mode: MatchSubstring, expected: []int{4}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := matchItems([]string{tc.item}, tc.pattern, tc.mode)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("一致件数が期待と異なります: %d", len(results))
+			}
+			if !reflect.DeepEqual(results[0].positions, tc.expected) {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, results[0].positions)
+			}
+		})
+	}
+}
+
+// TestApplyFilterConditions は属性条件がどの一致方式とも組み合わせられ、
+// 正規表現とglobではそれ以外の部分がパターンとしてそのまま使われることをテストします
+func TestApplyFilterConditions(t *testing.T) {
+	objects := []aws.ObjectInfo{
+		{Key: "logs/a  b.csv", Size: 10},
+		{Key: "logs/x=y.csv", Size: 2048},
+		{Key: "logs/big.csv", Size: 4096},
+		{Key: "tmp/a<b.txt", Size: 1},
+	}
+	testCases := []struct {
+		name     string
+		pattern  string
+		mode     MatchMode
+		expected []string
+	}{
+		{name: "部分一致と属性条件", pattern: "size>1KB csv", mode: MatchSubstring, expected: []string{"logs/x=y.csv", "logs/big.csv"}},
+		{name: "正規表現の比較演算子は属性条件ではない", pattern: "a<b", mode: MatchRegex, expected: []string{"tmp/a<b.txt"}},
+		{name: "正規表現の空白はそのまま", pattern: "a  b", mode: MatchRegex, expected: []string{"logs/a  b.csv"}},
+		{name: "正規表現と属性条件", pattern: `x=y\.csv$ size>1KB`, mode: MatchRegex, expected: []string{"logs/x=y.csv"}},
+		{name: "globと属性条件", pattern: "size>=4KB *.csv", mode: MatchGlob, expected: []string{"logs/big.csv"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestModel(t, nil)
+			m.state = ObjectsView
+			m = m.setObjects(objects)
+			m.matchMode = tc.mode
+			m.filterInput.SetValue(tc.pattern)
+			m.applyFilter()
+			if m.filterErr != nil {
+				t.Fatalf("予期しないエラー: %v", m.filterErr)
+			}
+			if !reflect.DeepEqual(m.objectModel.FilteredObjects, tc.expected) {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, m.objectModel.FilteredObjects)
+			}
+		})
+	}
+}
@@ -1,7 +1,12 @@
 package ui
 
 import (
+	"io"
+	"time"
+
 	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/cache"
+	"github.com/tsuna-can/s3-cli/internal/state"
 )
 
 // s3ClientInitMsg はS3クライアントの初期化メッセージです
@@ -12,11 +17,115 @@ type s3ClientInitMsg struct {
 // bucketsMsg はバケットリストのメッセージです
 type bucketsMsg struct {
 	buckets []string
+	err     error
 }
 
 // objectsMsg はオブジェクトリストのメッセージです
 type objectsMsg struct {
-	objects []string
+	bucket    string
+	prefix    string
+	objects   []aws.ObjectInfo
+	fetchedAt time.Time
+	err       error
+}
+
+// listingLoadedMsg はキャッシュから一覧を読み込んだ結果のメッセージです
+type listingLoadedMsg struct {
+	bucket  string
+	prefix  string
+	listing cache.Listing
+	ok      bool // キャッシュに一覧があったか
+}
+
+// uploadsMsg は完了していないマルチパートアップロード一覧のメッセージです
+type uploadsMsg struct {
+	bucket  string
+	uploads []aws.MultipartUpload
+	err     error
+}
+
+// uploadsAbortedMsg はマルチパートアップロードの中止の結果のメッセージです
+type uploadsAbortedMsg struct {
+	bucket  string
+	aborted int
+	err     error
+}
+
+// locationProbeMsg は指定された場所がオブジェクトかどうかを確かめた結果のメッセージです
+type locationProbeMsg struct {
+	location state.Location
+	info     aws.ObjectDetails
+	err      error
+}
+
+// detailsMsg はオブジェクトの詳細の取得結果のメッセージです
+type detailsMsg struct {
+	bucket string
+	key    string
+	info   aws.ObjectDetails
+	err    error
+}
+
+// settingsMsg はバケットの設定の取得結果のメッセージです
+type settingsMsg struct {
+	bucket string
+	config aws.BucketConfig
+}
+
+// settingEditedMsg はエディタで設定を編集し終えたときのメッセージです
+type settingEditedMsg struct {
+	bucket   string
+	setting  aws.BucketSetting
+	original string // 編集を始める前の設定
+	doc      string // 編集後の内容
+	err      error
+}
+
+// settingAppliedMsg はバケットの設定の反映結果のメッセージです
+type settingAppliedMsg struct {
+	bucket string
+	status string
+	err    error
+}
+
+// editFetchedMsg は編集するオブジェクトのダウンロード結果のメッセージです
+type editFetchedMsg struct {
+	key string
+	obj *aws.EditableObject
+	err error
+}
+
+// editDoneMsg はエディタの終了メッセージです
+type editDoneMsg struct {
+	obj *aws.EditableObject
+	err error
+}
+
+// editSavedMsg は編集したオブジェクトの書き戻し結果のメッセージです
+type editSavedMsg struct {
+	obj *aws.EditableObject
+	err error
+}
+
+// openReadyMsg は外部のプログラムで開くオブジェクトの準備ができたことのメッセージです
+type openReadyMsg struct {
+	key     string
+	command string
+	path    string        // 一時ファイル（{} を使うコマンドの場合）
+	body    io.ReadCloser // 標準入力に流す本文（{} を使わないコマンドの場合）
+	err     error
+}
+
+// openDoneMsg は外部のプログラムの終了メッセージです
+type openDoneMsg struct {
+	key string
+	err error
+}
+
+// bucketOpMsg はバケットの作成・削除の結果のメッセージです
+type bucketOpMsg struct {
+	status string
+	err    error
 }
 
 // errorMsg はエラーメッセージです
@@ -24,9 +133,56 @@ type errorMsg struct {
 	err error
 }
 
-// downloadedMsg はダウンロード完了メッセージです
+// downloadedMsg はダウンロード終了メッセージです
 type downloadedMsg struct {
-	bucket    string
-	key       string
-	outputDir string
+	id      int // 転送パネルの転送のID
+	bucket  string
+	key     string
+	path    string // 保存先のパス
+	skipped bool   // 同名ファイルが存在するためスキップした
+	err     error
+}
+
+// transferProgressMsg は転送の進捗メッセージです
+type transferProgressMsg struct {
+	event transferEvent
+}
+
+// searchProgressMsg はサーバー側検索の途中経過メッセージです
+type searchProgressMsg struct {
+	id       int
+	progress aws.SearchProgress
+}
+
+// searchDoneMsg はサーバー側検索の終了メッセージです
+type searchDoneMsg struct {
+	id  int
+	err error
+}
+
+// usageProgressMsg は使用量の集計の途中経過メッセージです
+type usageProgressMsg struct {
+	id       int
+	progress aws.SearchProgress
+}
+
+// usageDoneMsg は使用量の集計の終了メッセージです
+type usageDoneMsg struct {
+	id  int
+	err error
+}
+
+// logTickMsg はログ画面を定期的に再描画するためのメッセージです
+type logTickMsg struct {
+	id int
+}
+
+// retryMsg はS3呼び出しを再試行することを知らせるメッセージです
+type retryMsg struct {
+	event aws.RetryEvent
+}
+
+// retryClearMsg は再試行の表示を消すためのメッセージです
+type retryClearMsg struct {
+	seq int
 }
@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// tempFilePlaceholder はコマンド中で一時ファイルのパスに置き換える文字列です
+const tempFilePlaceholder = "{}"
+
+// pagerMarker はコマンドの先頭に付けると、コマンドの出力をページャーで表示する印です。
+// jq . や parquet-tools のように出力してすぐ終わるコマンドの出力が、UIの再描画で消えないようにします。
+const pagerMarker = "|"
+
+// defaultOpenCommand は open_with に対応する拡張子がない場合のコマンドです。$PAGER があればそれを使います
+func defaultOpenCommand() string {
+	if pager := os.Getenv("PAGER"); pager != "" {
+		return pager
+	}
+	return "less"
+}
+
+// openCommandFor はキーの拡張子に対応する open_with のコマンドを返します。
+// "tar.gz" のように複数の拡張子を登録できるよう、最も長く一致したものを使います。
+func openCommandFor(key string, commands map[string]string) string {
+	name := strings.ToLower(path.Base(key))
+	command, matched := "", 0
+	for ext, c := range commands {
+		suffix := "." + strings.TrimPrefix(strings.ToLower(ext), ".")
+		if strings.HasSuffix(name, suffix) && len(suffix) > matched {
+			command, matched = c, len(suffix)
+		}
+	}
+	if command != "" {
+		return command
+	}
+	if command, ok := commands["*"]; ok {
+		return command
+	}
+	return defaultOpenCommand()
+}
+
+// usesTempFile はコマンドがオブジェクトを標準入力ではなく一時ファイルで受け取るかを返します
+func usesTempFile(command string) bool {
+	return strings.Contains(command, tempFilePlaceholder)
+}
+
+// openProcess はシェルでコマンドを実行するプロセスを作成します。{} は一時ファイルのパスに置き換えます。
+// 先頭に | の付いたコマンドは、エラーも含めた出力を $PAGER（なければ less）に流します。
+func openProcess(command, path string) *exec.Cmd {
+	if rest, ok := strings.CutPrefix(strings.TrimSpace(command), pagerMarker); ok {
+		command = "(" + strings.TrimSpace(rest) + ") 2>&1 | " + defaultOpenCommand()
+	}
+	if path != "" {
+		command = strings.ReplaceAll(command, tempFilePlaceholder, shellQuote(path))
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// shellQuote は s をシェルの単一引用符で囲みます
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// openOpenWith はカーソル位置のオブジェクトを開くコマンドを尋ねます。初期値は拡張子に対応するコマンドです
+func (m UIModel) openOpenWith() UIModel {
+	if len(m.objectModel.FilteredObjects) == 0 {
+		return m
+	}
+	bucket := m.objectModel.BucketName
+	key := m.objectModel.FilteredObjects[m.objectModel.Cursor]
+	command := openCommandFor(key, m.openWith)
+	m.prompt = m.newInputPrompt(i18n.T("open.prompt", key), command, command,
+		func(m UIModel, value string) (UIModel, tea.Cmd) {
+			if strings.TrimSpace(value) == "" {
+				return m, nil
+			}
+			return m.startOpen(bucket, key, value)
+		})
+	return m
+}
+
+// startOpen はバックグラウンドでオブジェクトを読み出す準備をし、終わったらコマンドを実行します。
+// コマンドに {} がある場合は一時ファイルに保存し、ない場合は本文を標準入力に流します。
+func (m UIModel) startOpen(bucket, key, command string) (UIModel, tea.Cmd) {
+	m.objectOp = objectOpState{busy: true, status: i18n.T("open.fetching", key)}
+	client := m.s3Client
+	m.logger.Info("open with started", "bucket", bucket, "key", key, "command", command)
+	return m, func() tea.Msg {
+		msg := openReadyMsg{key: key, command: command}
+		if usesTempFile(command) {
+			msg.path, msg.err = client.DownloadTemp(context.Background(), bucket, key)
+		} else {
+			msg.body, msg.err = client.OpenObject(context.Background(), bucket, key)
+		}
+		return msg
+	}
+}
+
+// handleOpenReady はコマンドを実行します。実行中はUIを中断し、コマンドに端末を渡します
+func (m UIModel) handleOpenReady(msg openReadyMsg) (UIModel, tea.Cmd) {
+	if msg.err != nil {
+		m.logger.Error("open with failed", "key", msg.key, "error", msg.err)
+		m.objectOp = objectOpState{err: msg.err}
+		return m, nil
+	}
+	m.objectOp = objectOpState{busy: true}
+	if msg.path != "" {
+		// xdg-open のようにすぐ戻るコマンドもあるため、一時ファイルは終了時にまとめて削除する
+		m.openTemps = append(append([]string(nil), m.openTemps...), filepath.Dir(msg.path))
+	}
+
+	process := openProcess(msg.command, msg.path)
+	if msg.body != nil {
+		process.Stdin = msg.body
+	}
+	key, command, body := msg.key, msg.command, msg.body
+	return m, m.execProcess(process, func(err error) tea.Msg {
+		if body != nil {
+			body.Close()
+		}
+		if err != nil {
+			err = i18n.Errorf("open.failed", command, err)
+		}
+		return openDoneMsg{key: key, err: err}
+	})
+}
+
+// handleOpenDone はコマンドの終了を反映します
+func (m UIModel) handleOpenDone(msg openDoneMsg) UIModel {
+	if msg.err != nil {
+		m.logger.Error("open with command failed", "key", msg.key, "error", msg.err)
+	}
+	m.objectOp = objectOpState{err: msg.err}
+	return m
+}
+
+// removeOpenTemps は外部のプログラムで開くために作成した一時ファイルを削除します
+func (m UIModel) removeOpenTemps() {
+	for _, dir := range m.openTemps {
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestOpenCommandFor は拡張子に対応する open_with のコマンドの選択をテストします
+func TestOpenCommandFor(t *testing.T) {
+	t.Setenv("PAGER", "")
+	commands := map[string]string{
+		"json":    "jq -C . | less -R",
+		".gz":     "zcat | less",
+		"tar.gz":  "tar tzvf - | less",
+		"parquet": "parquet-tools show {}",
+	}
+	testCases := []struct {
+		name     string
+		key      string
+		commands map[string]string
+		expected string
+	}{
+		{name: "拡張子が一致", key: "logs/app.json", commands: commands, expected: "jq -C . | less -R"},
+		{name: "大文字の拡張子", key: "DATA.JSON", commands: commands, expected: "jq -C . | less -R"},
+		{name: "先頭のドットは省略できる", key: "a.log.gz", commands: commands, expected: "zcat | less"},
+		{name: "最も長い拡張子", key: "backup.tar.gz", commands: commands, expected: "tar tzvf - | less"},
+		{name: "ディレクトリ名は見ない", key: "x.json/readme", commands: commands, expected: "less"},
+		{name: "その他すべて", key: "notes.txt", commands: map[string]string{"*": "bat"}, expected: "bat"},
+		{name: "設定なし", key: "notes.txt", expected: "less"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := openCommandFor(tc.key, tc.commands); got != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+
+	t.Setenv("PAGER", "more")
+	if got := openCommandFor("notes.txt", nil); got != "more" {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "more", got)
+	}
+}
+
+// TestOpenProcess は一時ファイルのパスの置き換えと、出力をページャーに流す印をテストします
+func TestOpenProcess(t *testing.T) {
+	t.Setenv("PAGER", "more")
+	testCases := []struct {
+		name     string
+		command  string
+		path     string
+		expected []string
+	}{
+		{name: "標準入力に流す", command: "jq . | less", expected: []string{"sh", "-c", "jq . | less"}},
+		{name: "一時ファイル", command: "xdg-open {}", path: "/tmp/a b.pdf", expected: []string{"sh", "-c", "xdg-open '/tmp/a b.pdf'"}},
+		{name: "出力をページャーに流す", command: "| jq .", expected: []string{"sh", "-c", "(jq .) 2>&1 | more"}},
+		{name: "一時ファイルの出力をページャーに流す", command: "|parquet-tools show {}", path: "/tmp/a.parquet", expected: []string{"sh", "-c", "(parquet-tools show '/tmp/a.parquet') 2>&1 | more"}},
+		{name: "引用符を含むパス", command: "cat {}", path: "/tmp/it's.txt", expected: []string{"sh", "-c", `cat '/tmp/it'\''s.txt'`}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := openProcess(tc.command, tc.path).Args; !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// inputPrompt は画面下部に表示する1行入力のダイアログです
+type inputPrompt struct {
+	title    string
+	input    textinput.Model
+	err      error // 直前の入力に対するエラー
+	onSubmit func(m UIModel, value string) (UIModel, tea.Cmd)
+}
+
+// newInputPrompt は入力ダイアログを作成します
+func (m UIModel) newInputPrompt(title, placeholder, value string, onSubmit func(m UIModel, value string) (UIModel, tea.Cmd)) *inputPrompt {
+	input := textinput.New()
+	m.theme.applyToInput(&input)
+	input.Prompt = "> "
+	input.Placeholder = placeholder
+	input.SetValue(value)
+	input.Focus()
+	return &inputPrompt{
+		title:    title,
+		input:    input,
+		onSubmit: onSubmit,
+	}
+}
+
+// handlePromptKey は入力ダイアログ表示中のキー入力を処理します
+func (m UIModel) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.prompt = nil
+		return m, nil
+
+	case tea.KeyEnter:
+		p := m.prompt
+		m.prompt = nil
+		return p.onSubmit(m, p.input.Value())
+	}
+
+	// ポインタを共有しないよう、コピーを更新してから差し替える
+	p := *m.prompt
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	p.err = nil
+	m.prompt = &p
+	return m, cmd
+}
+
+// renderPrompt は入力ダイアログを描画します
+func (m UIModel) renderPrompt() string {
+	view := fmt.Sprintf("\n%s\n%s", m.theme.Status.Render(m.prompt.title), m.prompt.input.View())
+	if m.prompt.err != nil {
+		view += "\n" + m.theme.Error.Render(fmt.Sprintf("⚠ %v", m.prompt.err))
+	}
+	return view + "\n" + m.theme.Dim.Render(i18n.T("ui.prompt_hint"))
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// retryDisplayTime は再試行の待ち時間が過ぎてから表示を残しておく時間です
+const retryDisplayTime = 3 * time.Second
+
+// retryStatus は表示中の再試行の状態です
+type retryStatus struct {
+	event aws.RetryEvent
+	seq   int
+}
+
+// waitForRetry は次の再試行の通知を待つコマンドを返します
+func waitForRetry(events <-chan aws.RetryEvent) tea.Cmd {
+	return func() tea.Msg {
+		return retryMsg{event: <-events}
+	}
+}
+
+// handleRetry は再試行の状態を表示し、しばらくしたら消すタイマーを設定します
+func (m UIModel) handleRetry(msg retryMsg) (UIModel, tea.Cmd) {
+	m.retrySeq++
+	m.retry = &retryStatus{event: msg.event, seq: m.retrySeq}
+	m.logger.Warn("retrying S3 request",
+		"attempt", msg.event.Attempt, "max_attempts", msg.event.MaxAttempts,
+		"delay", msg.event.Delay, "throttled", msg.event.Throttled, "reason", msg.event.Reason)
+
+	seq := m.retrySeq
+	clear := tea.Tick(msg.event.Delay+retryDisplayTime, func(time.Time) tea.Msg {
+		return retryClearMsg{seq: seq}
+	})
+	return m, tea.Batch(waitForRetry(m.retryEvents), clear)
+}
+
+// handleRetryClear は最後の再試行から時間が経っていれば表示を消します
+func (m UIModel) handleRetryClear(msg retryClearMsg) UIModel {
+	if m.retry != nil && m.retry.seq == msg.seq {
+		m.retry = nil
+	}
+	return m
+}
+
+// renderRetryStatus は再試行中であることを表示する1行を返します（再試行中でなければ空文字）
+func (m UIModel) renderRetryStatus() string {
+	if m.retry == nil {
+		return ""
+	}
+	e := m.retry.event
+	delay := e.Delay.Round(100 * time.Millisecond)
+	if e.Throttled {
+		return m.theme.Error.Render(i18n.T("status.throttled", e.Reason, delay, e.Attempt, e.MaxAttempts))
+	}
+	return m.theme.Error.Render(i18n.T("status.retrying", e.Reason, delay, e.Attempt, e.MaxAttempts))
+}
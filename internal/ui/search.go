@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// searchState はサーバー側検索の状態です
+type searchState struct {
+	id       int // 検索ごとに増える番号。古い検索の結果を無視するために使う
+	prefix   string
+	pattern  string
+	mode     MatchMode
+	running  bool
+	scanned  int
+	matched  int
+	err      error
+	cancel   context.CancelFunc
+	progress <-chan aws.SearchProgress
+	done     <-chan error
+}
+
+// openSearchPrompt は検索するプレフィックスとパターンを順に入力するダイアログを開きます
+func (m UIModel) openSearchPrompt() UIModel {
+	m.prompt = m.newInputPrompt(
+		i18n.T("search.prefix_prompt", m.objectModel.BucketName),
+		"logs/2026/",
+		m.searchPrefix(),
+		func(m UIModel, prefix string) (UIModel, tea.Cmd) {
+			return m.openSearchPatternPrompt(prefix, m.search.pattern, nil), nil
+		},
+	)
+	return m
+}
+
+// searchPrefix は検索ダイアログの初期値のプレフィックスです。
+// 検索したことがあればそのプレフィックス、なければ一覧しているプレフィックスです。
+func (m UIModel) searchPrefix() string {
+	if m.search.progress != nil {
+		return m.search.prefix
+	}
+	return m.objectModel.Prefix
+}
+
+// openSearchPatternPrompt は検索パターンを入力するダイアログを開きます
+func (m UIModel) openSearchPatternPrompt(prefix, pattern string, err error) UIModel {
+	m.prompt = m.newInputPrompt(
+		i18n.T("search.pattern_prompt", m.matchMode),
+		"",
+		pattern,
+		func(m UIModel, pattern string) (UIModel, tea.Cmd) {
+			return m.startSearch(prefix, pattern)
+		},
+	)
+	m.prompt.err = err
+	return m
+}
+
+// startSearch はバックグラウンドでの検索を開始します。実行中の検索はキャンセルします。
+func (m UIModel) startSearch(prefix, pattern string) (UIModel, tea.Cmd) {
+	var match func(key string) bool
+	if pattern != "" {
+		matcher, err := compileMatcher(pattern, m.matchMode)
+		if err != nil {
+			return m.openSearchPatternPrompt(prefix, pattern, err), nil
+		}
+		match = func(key string) bool {
+			_, _, ok := matcher(key)
+			return ok
+		}
+	}
+
+	m.cancelSearch()
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := make(chan aws.SearchProgress, 16)
+	done := make(chan error, 1)
+	opts := aws.SearchOptions{
+		Bucket:      m.objectModel.BucketName,
+		Prefix:      prefix,
+		Match:       match,
+		Concurrency: m.concurrency,
+	}
+	client := m.s3Client
+	go func() {
+		done <- client.SearchObjects(ctx, opts, progress)
+		close(progress)
+	}()
+
+	m.search = searchState{
+		id:       m.search.id + 1,
+		prefix:   prefix,
+		pattern:  pattern,
+		mode:     m.matchMode,
+		running:  true,
+		cancel:   cancel,
+		progress: progress,
+		done:     done,
+	}
+
+	// 検索結果で一覧を置き換える
+	m.objectModel.Objects = nil
+	m.objectModel.Details = make(map[string]aws.ObjectInfo)
+	m.objectModel.Cursor = 0
+	m.applyFilter()
+
+	return m, m.waitForSearch()
+}
+
+// cancelSearch は実行中の検索をキャンセルします
+func (m *UIModel) cancelSearch() {
+	if m.search.running && m.search.cancel != nil {
+		m.search.cancel()
+	}
+}
+
+// resetSearch は実行中の検索をキャンセルし、検索結果の表示をやめます。
+// 番号を進めて、キャンセルした検索の残りの結果を無視します。
+func (m *UIModel) resetSearch() {
+	m.cancelSearch()
+	m.search = searchState{id: m.search.id + 1}
+}
+
+// waitForSearch は検索の次の途中経過を待つCmdを返します
+func (m UIModel) waitForSearch() tea.Cmd {
+	id, progress, done := m.search.id, m.search.progress, m.search.done
+	return func() tea.Msg {
+		p, ok := <-progress
+		if !ok {
+			return searchDoneMsg{id: id, err: <-done}
+		}
+		return searchProgressMsg{id: id, progress: p}
+	}
+}
+
+// handleSearchProgress は検索の途中経過を一覧に反映します
+func (m UIModel) handleSearchProgress(msg searchProgressMsg) (UIModel, tea.Cmd) {
+	if msg.id != m.search.id {
+		return m, nil
+	}
+	m.search.scanned += msg.progress.Scanned
+	m.search.matched += len(msg.progress.Matched)
+	for _, obj := range msg.progress.Matched {
+		m.objectModel.Objects = append(m.objectModel.Objects, obj.Key)
+		m.objectModel.Details[obj.Key] = obj
+	}
+	m.applyFilter()
+	return m, m.waitForSearch()
+}
+
+// handleSearchDone は検索の終了を反映します
+func (m UIModel) handleSearchDone(msg searchDoneMsg) UIModel {
+	if msg.id != m.search.id {
+		return m
+	}
+	m.search.running = false
+	m.search.err = msg.err
+	m.search.cancel()
+	return m
+}
+
+// renderSearchStatus は検索の状態を1行で返します。検索していない場合は空文字を返します
+func (m UIModel) renderSearchStatus() string {
+	if m.search.progress == nil {
+		return ""
+	}
+
+	var state string
+	switch {
+	case m.search.running:
+		state = i18n.T("search.running")
+	case errors.Is(m.search.err, context.Canceled):
+		state = i18n.T("search.canceled")
+	case m.search.err != nil:
+		state = i18n.T("search.failed", m.search.err)
+	default:
+		state = i18n.T("search.done")
+	}
+
+	status := fmt.Sprintf("%s  prefix=%q", state, m.search.prefix)
+	if m.search.pattern != "" {
+		status += fmt.Sprintf(" %s=%q", m.search.mode, m.search.pattern)
+	}
+	status += "  " + i18n.T("search.counts", formatCount(m.search.scanned), formatCount(m.search.matched))
+	if m.search.running {
+		status += "  " + i18n.T("search.cancel_hint", m.keys.CancelSearch.Help().Key)
+	}
+	return status + "\n"
+}
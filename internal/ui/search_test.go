@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/model"
+)
+
+// TestSearchStaleResults は古い検索の途中経過と終了を無視し、今の検索の結果だけを一覧に反映することをテストします
+func TestSearchStaleResults(t *testing.T) {
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m.state = ObjectsView
+	m.objectModel = model.ObjectListModel{BucketName: "alpha", Details: map[string]aws.ObjectInfo{}}
+	canceled := false
+	m.search = searchState{
+		id:       2,
+		prefix:   "logs/",
+		running:  true,
+		cancel:   func() { canceled = true },
+		progress: make(chan aws.SearchProgress),
+		done:     make(chan error, 1),
+	}
+
+	// キャンセルした検索の残りの途中経過は捨て、待ち続けない
+	m, cmd := m.handleSearchProgress(searchProgressMsg{id: 1, progress: aws.SearchProgress{Scanned: 3, Matched: []aws.ObjectInfo{{Key: "old.log"}}}})
+	if cmd != nil || len(m.objectModel.Objects) != 0 || m.search.scanned != 0 {
+		t.Errorf("古い検索の結果が反映されました: %v", m.objectModel.Objects)
+	}
+
+	m, cmd = m.handleSearchProgress(searchProgressMsg{id: 2, progress: aws.SearchProgress{Scanned: 5, Matched: []aws.ObjectInfo{{Key: "logs/a.log"}}}})
+	if cmd == nil {
+		t.Error("次の途中経過を待っていません")
+	}
+	if len(m.objectModel.FilteredObjects) != 1 || m.search.scanned != 5 || m.search.matched != 1 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []any{1, 5, 1}, []any{len(m.objectModel.FilteredObjects), m.search.scanned, m.search.matched})
+	}
+
+	// 古い検索の終了では、今の検索は終わらない
+	m = m.handleSearchDone(searchDoneMsg{id: 1, err: context.Canceled})
+	if !m.search.running || canceled {
+		t.Error("古い検索の終了で今の検索が終了しました")
+	}
+	m = m.handleSearchDone(searchDoneMsg{id: 2})
+	if m.search.running || m.search.err != nil || !canceled {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "done", []any{m.search.running, m.search.err, canceled})
+	}
+}
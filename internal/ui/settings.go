@@ -0,0 +1,376 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// diffContext は差分で変更のある行の前後に表示する行数です
+const diffContext = 3
+
+// settingRows は設定画面に表示する設定の順番です
+var settingRows = append([]aws.BucketSetting{aws.SettingVersioning}, aws.DocumentSettings...)
+
+// settingsState はバケットの設定画面の状態です
+type settingsState struct {
+	bucket  string
+	loading bool
+	busy    bool // 設定を反映している間は編集を受け付けない
+	cursor  int
+	config  aws.BucketConfig
+	status  string // 直前の操作の結果
+	err     error
+	pending *settingChange // 反映の確認中の変更
+}
+
+// settingChange は $EDITOR で編集し、反映の確認中の設定です
+type settingChange struct {
+	setting aws.BucketSetting
+	doc     string
+	diff    []diffLine
+}
+
+// openSettings はバケットの設定画面を開き、設定を取得します
+func (m UIModel) openSettings(bucket string) (UIModel, tea.Cmd) {
+	m.settings = &settingsState{bucket: bucket, loading: true}
+	return m, m.fetchSettings(bucket)
+}
+
+// fetchSettings はバケットの設定を取得します
+func (m UIModel) fetchSettings(bucket string) tea.Cmd {
+	client := m.s3Client
+	return func() tea.Msg {
+		return settingsMsg{bucket: bucket, config: client.GetBucketConfig(context.Background(), bucket)}
+	}
+}
+
+// updateSettings は設定画面の状態を、ポインタを共有しないようにコピーしてから更新します
+func (m UIModel) updateSettings(update func(s *settingsState)) UIModel {
+	s := *m.settings
+	update(&s)
+	m.settings = &s
+	return m
+}
+
+// handleSettings は取得した設定を反映します。既に閉じた画面の結果は無視します
+func (m UIModel) handleSettings(msg settingsMsg) UIModel {
+	if m.settings == nil || m.settings.bucket != msg.bucket {
+		return m
+	}
+	return m.updateSettings(func(s *settingsState) {
+		s.loading = false
+		s.config = msg.config
+	})
+}
+
+// handleSettingsKey は設定画面の表示中のキー入力を処理します
+func (m UIModel) handleSettingsKey(msg tea.KeyMsg) (UIModel, tea.Cmd) {
+	km := m.keys
+	s := m.settings
+	switch {
+	case msg.Type == tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case key.Matches(msg, km.Settings, km.Back, km.Quit):
+		m.settings = nil
+
+	case key.Matches(msg, km.Up):
+		return m.updateSettings(func(s *settingsState) { s.cursor = max(s.cursor-1, 0) }), nil
+
+	case key.Matches(msg, km.Down):
+		return m.updateSettings(func(s *settingsState) { s.cursor = min(s.cursor+1, len(settingRows)-1) }), nil
+
+	case key.Matches(msg, km.Select):
+		setting := settingRows[s.cursor]
+		// 現在の設定を取得できない場合は、差分を示せず上書きしてしまうため編集させない
+		if s.loading || s.busy || s.config.Errors[setting] != nil {
+			return m, nil
+		}
+		if setting == aws.SettingVersioning {
+			return m.openVersioningPrompt(), nil
+		}
+		doc := s.config.Documents[setting]
+		return m, m.editSetting(s.bucket, setting, doc, doc)
+	}
+	return m, nil
+}
+
+// openVersioningPrompt はバージョニングを有効にするか停止するかの確認ダイアログを開きます
+func (m UIModel) openVersioningPrompt() UIModel {
+	bucket := m.settings.bucket
+	enable := m.settings.config.Versioning != "Enabled"
+	title := i18n.T("settings.suspend_versioning", bucket)
+	if enable {
+		title = i18n.T("settings.enable_versioning", bucket)
+	}
+	m.prompt = m.newInputPrompt(title, "y/N", "",
+		func(m UIModel, value string) (UIModel, tea.Cmd) {
+			if !isYes(value) {
+				return m, nil
+			}
+			return m.setVersioning(bucket, enable)
+		})
+	return m
+}
+
+// setVersioning はバックグラウンドでバージョニングを有効にするか停止します
+func (m UIModel) setVersioning(bucket string, enable bool) (UIModel, tea.Cmd) {
+	m = m.updateSettings(func(s *settingsState) {
+		s.busy, s.status, s.err = true, "", nil
+	})
+	client := m.s3Client
+	return m, func() tea.Msg {
+		if err := client.SetBucketVersioning(context.Background(), bucket, enable); err != nil {
+			return settingAppliedMsg{bucket: bucket, err: err}
+		}
+		state := i18n.T("settings.versioning_suspend")
+		if enable {
+			state = i18n.T("settings.versioning_enabled")
+		}
+		return settingAppliedMsg{bucket: bucket, status: i18n.T("settings.versioning_done", bucket, state)}
+	}
+}
+
+// editSetting は content を一時ファイルに書き出して $EDITOR で開きます。
+// original は取得した時点の設定で、編集後の差分の表示に使います。
+func (m UIModel) editSetting(bucket string, setting aws.BucketSetting, original, content string) tea.Cmd {
+	edited := settingEditedMsg{bucket: bucket, setting: setting, original: original}
+	path, err := writeEditFile("s3-cli-"+string(setting)+"-*.json", content)
+	if err != nil {
+		edited.err = err
+		return func() tea.Msg { return edited }
+	}
+	return m.execProcess(EditorProcess(path), func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			edited.err = i18n.Errorf("cmd.editor_failed", err)
+			return edited
+		}
+		data, err := os.ReadFile(path)
+		edited.doc, edited.err = string(data), err
+		return edited
+	})
+}
+
+// writeEditFile は content をエディタで開く一時ファイルに書き出し、そのパスを返します
+func writeEditFile(pattern, content string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	_, err = f.WriteString(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// handleSettingEdited はエディタで編集した設定を検証し、差分を表示して反映するかを尋ねます。
+// 不正な場合は編集した内容のまま、もう一度エディタで開けます。
+func (m UIModel) handleSettingEdited(msg settingEditedMsg) (UIModel, tea.Cmd) {
+	if m.settings == nil || m.settings.bucket != msg.bucket {
+		return m, nil
+	}
+	name := settingName(msg.setting)
+	m = m.updateSettings(func(s *settingsState) {
+		s.pending, s.status, s.err = nil, "", msg.err
+	})
+	switch {
+	case msg.err != nil:
+		return m, nil
+
+	case strings.TrimSpace(msg.doc) == strings.TrimSpace(msg.original):
+		return m.updateSettings(func(s *settingsState) { s.status = i18n.T("settings.no_changes", name) }), nil
+	}
+
+	if err := aws.ValidateBucketSetting(msg.setting, msg.doc); err != nil {
+		m = m.updateSettings(func(s *settingsState) { s.err = err })
+		m.prompt = m.newInputPrompt(i18n.T("settings.edit_again", name), "y/N", "",
+			func(m UIModel, value string) (UIModel, tea.Cmd) {
+				if !isYes(value) {
+					return m.updateSettings(func(s *settingsState) {
+						s.status, s.err = i18n.T("settings.discarded", name), nil
+					}), nil
+				}
+				return m, m.editSetting(msg.bucket, msg.setting, msg.original, msg.doc)
+			})
+		return m, nil
+	}
+
+	change := &settingChange{setting: msg.setting, doc: msg.doc, diff: diffLines(msg.original, msg.doc)}
+	m = m.updateSettings(func(s *settingsState) { s.pending = change })
+	title := i18n.T("settings.confirm_apply", name, msg.bucket)
+	if aws.SettingEntries(msg.setting, msg.doc) == 0 {
+		title = i18n.T("settings.confirm_remove", name, msg.bucket)
+	}
+	m.prompt = m.newInputPrompt(title, "y/N", "",
+		func(m UIModel, value string) (UIModel, tea.Cmd) {
+			m = m.updateSettings(func(s *settingsState) { s.pending = nil })
+			if !isYes(value) {
+				return m.updateSettings(func(s *settingsState) { s.status = i18n.T("settings.discarded", name) }), nil
+			}
+			return m.applySetting(msg.bucket, msg.setting, msg.doc)
+		})
+	return m, nil
+}
+
+// applySetting はバックグラウンドで設定を反映します
+func (m UIModel) applySetting(bucket string, setting aws.BucketSetting, doc string) (UIModel, tea.Cmd) {
+	name := settingName(setting)
+	m = m.updateSettings(func(s *settingsState) {
+		s.busy, s.status, s.err = true, i18n.T("settings.applying", name), nil
+	})
+	client := m.s3Client
+	return m, func() tea.Msg {
+		if err := client.PutBucketSetting(context.Background(), bucket, setting, doc); err != nil {
+			return settingAppliedMsg{bucket: bucket, err: err}
+		}
+		if aws.SettingEntries(setting, doc) == 0 {
+			return settingAppliedMsg{bucket: bucket, status: i18n.T("settings.removed", name)}
+		}
+		return settingAppliedMsg{bucket: bucket, status: i18n.T("settings.applied", name)}
+	}
+}
+
+// handleSettingApplied は反映の結果を表示し、設定を取り直します
+func (m UIModel) handleSettingApplied(msg settingAppliedMsg) (UIModel, tea.Cmd) {
+	if m.settings == nil || m.settings.bucket != msg.bucket {
+		return m, nil
+	}
+	m = m.updateSettings(func(s *settingsState) {
+		s.busy, s.status, s.err = false, msg.status, msg.err
+	})
+	return m, m.fetchSettings(msg.bucket)
+}
+
+// settingName は設定の表示名を返します
+func settingName(setting aws.BucketSetting) string {
+	switch setting {
+	case aws.SettingVersioning:
+		return i18n.T("settings.name_versioning")
+	case aws.SettingLifecycle:
+		return i18n.T("settings.name_lifecycle")
+	case aws.SettingCORS:
+		return i18n.T("settings.name_cors")
+	case aws.SettingPolicy:
+		return i18n.T("settings.name_policy")
+	case aws.SettingTags:
+		return i18n.T("settings.name_tags")
+	}
+	return string(setting)
+}
+
+// settingSummary は設定画面の各行に表示する、設定の状態の要約を返します
+func settingSummary(config aws.BucketConfig, setting aws.BucketSetting) string {
+	if err := config.Errors[setting]; err != nil {
+		return fmt.Sprintf("⚠ %v", err)
+	}
+	if setting == aws.SettingVersioning {
+		switch config.Versioning {
+		case "Enabled":
+			return i18n.T("settings.versioning_enabled")
+		case "Suspended":
+			return i18n.T("settings.versioning_suspend")
+		}
+		return i18n.T("settings.versioning_off")
+	}
+
+	n := aws.SettingEntries(setting, config.Documents[setting])
+	switch {
+	case n == 0:
+		return i18n.T("settings.not_set")
+	case setting == aws.SettingPolicy:
+		return i18n.T("settings.statements", n)
+	case setting == aws.SettingTags:
+		return i18n.T("settings.tags", n)
+	}
+	return i18n.T("settings.rules", n)
+}
+
+// renderSettings はバケットの設定画面を描画します。反映の確認中は設定の一覧の代わりに差分を表示します
+func (m UIModel) renderSettings() string {
+	s := m.settings
+	width := m.viewWidth() - 2
+	title := m.theme.HeaderTitle.Render(" " + i18n.T("settings.title", s.bucket) + " ")
+
+	var body string
+	switch {
+	case s.loading:
+		body = m.theme.Dim.Render(i18n.T("settings.loading", s.bucket))
+	case s.pending != nil && m.prompt != nil:
+		body = m.renderSettingDiff(s.pending, width-2)
+	default:
+		labelWidth := 0
+		for _, setting := range settingRows {
+			labelWidth = max(labelWidth, runewidth.StringWidth(settingName(setting)))
+		}
+		lines := make([]string, len(settingRows))
+		for i, setting := range settingRows {
+			label := runewidth.FillRight(settingName(setting), labelWidth)
+			summary := runewidth.Truncate(settingSummary(s.config, setting), max(width-labelWidth-4, 1), "…")
+			switch {
+			case i == s.cursor:
+				lines[i] = m.theme.Selected.Render(runewidth.FillRight(label+"  "+summary, width-2))
+			case s.config.Errors[setting] != nil:
+				lines[i] = m.theme.Dim.Render(label) + "  " + m.theme.Error.Render(summary)
+			default:
+				lines[i] = m.theme.Dim.Render(label) + "  " + m.theme.Item.Render(summary)
+			}
+		}
+		body = strings.Join(lines, "\n")
+	}
+
+	view := title + "\n\n" + m.theme.Panel.Copy().Width(width).Render(body) + "\n"
+	switch {
+	case s.err != nil:
+		view += m.theme.Error.Render(runewidth.Truncate(fmt.Sprintf("⚠ %v", s.err), width, "…")) + "\n"
+	case s.status != "":
+		view += m.theme.Status.Render(s.status) + "\n"
+	}
+	if m.prompt != nil {
+		return view + m.renderPrompt()
+	}
+	return view + m.theme.Dim.Render(i18n.T("settings.hint", m.keys.Select.Help().Key, m.keys.Back.Help().Key))
+}
+
+// renderSettingDiff は編集前後の差分を、変更のない部分を省略して画面に収まる行数で描画します
+func (m UIModel) renderSettingDiff(change *settingChange, width int) string {
+	lines := compactDiff(change.diff, diffContext)
+	// 見出し・枠・状態・確認ダイアログの行を除いた高さ
+	maxLines := 20
+	if m.height > 0 {
+		maxLines = max(m.height-12, 3)
+	}
+
+	rendered := []string{m.theme.Status.Render(i18n.T("settings.diff_title", settingName(change.setting)))}
+	for i, line := range lines {
+		if i == maxLines-1 && len(lines) > maxLines {
+			rendered = append(rendered, m.theme.Dim.Render(i18n.T("settings.diff_more", len(lines)-i)))
+			break
+		}
+		text := runewidth.Truncate(string(line.op)+" "+line.text, width, "…")
+		switch line.op {
+		case diffAdded:
+			rendered = append(rendered, m.theme.Added.Render(text))
+		case diffRemoved:
+			rendered = append(rendered, m.theme.Removed.Render(text))
+		case diffSkipped:
+			rendered = append(rendered, m.theme.Dim.Render(i18n.T("settings.diff_unchanged", line.count)))
+		default:
+			rendered = append(rendered, m.theme.Item.Render(text))
+		}
+	}
+	return strings.Join(rendered, "\n")
+}
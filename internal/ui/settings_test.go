@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+)
+
+// newSettingsTestModel はバケットの設定画面を表示しているモデルを作成します
+func newSettingsTestModel(t *testing.T) UIModel {
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m.settings = &settingsState{
+		bucket: "beta",
+		config: aws.BucketConfig{
+			Documents: map[aws.BucketSetting]string{aws.SettingTags: "{\n  \"TagSet\": []\n}\n"},
+			Errors:    map[aws.BucketSetting]error{aws.SettingPolicy: errors.New("AccessDenied")},
+		},
+	}
+	return m
+}
+
+// TestSettingSummary は設定画面の各行の要約をテストします
+func TestSettingSummary(t *testing.T) {
+	config := aws.BucketConfig{
+		Versioning: "Suspended",
+		Documents: map[aws.BucketSetting]string{
+			aws.SettingLifecycle: `{"Rules": [{"Status": "Enabled", "Expiration": {"Days": 1}}]}`,
+			aws.SettingCORS:      `{"CORSRules": []}`,
+			aws.SettingTags:      `{"TagSet": [{"Key": "a", "Value": ""}, {"Key": "b", "Value": ""}]}`,
+		},
+		Errors: map[aws.BucketSetting]error{aws.SettingPolicy: errors.New("AccessDenied")},
+	}
+	testCases := []struct {
+		setting  aws.BucketSetting
+		expected string
+	}{
+		{setting: aws.SettingVersioning, expected: "停止中"},
+		{setting: aws.SettingLifecycle, expected: "1 件のルール"},
+		{setting: aws.SettingCORS, expected: "未設定"},
+		{setting: aws.SettingPolicy, expected: "⚠ AccessDenied"},
+		{setting: aws.SettingTags, expected: "2 件のタグ"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.setting), func(t *testing.T) {
+			if got := settingSummary(config, tc.setting); got != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestHandleSettingEdited は編集後の設定の検証と、反映の確認をテストします
+func TestHandleSettingEdited(t *testing.T) {
+	original := "{\n  \"TagSet\": []\n}\n"
+	edited := "{\n  \"TagSet\": [\n    {\"Key\": \"team\", \"Value\": \"data\"}\n  ]\n}\n"
+
+	t.Run("変更なし", func(t *testing.T) {
+		m, _ := newSettingsTestModel(t).handleSettingEdited(settingEditedMsg{bucket: "beta", setting: aws.SettingTags, original: original, doc: original + "\n"})
+		if m.prompt != nil || m.settings.status != "タグは変更されていません" {
+			t.Errorf("期待結果 変更なしの表示, 実際の結果 %+v", m.settings)
+		}
+	})
+
+	t.Run("不正な内容", func(t *testing.T) {
+		m, _ := newSettingsTestModel(t).handleSettingEdited(settingEditedMsg{bucket: "beta", setting: aws.SettingTags, original: original, doc: `{"TagSet": [{"Key": ""}]}`})
+		if m.settings.err == nil || m.prompt == nil {
+			t.Fatalf("期待結果 エラーと再編集の確認, 実際の結果 %+v", m.settings)
+		}
+		m, cmd := submitPrompt(m, "n")
+		if cmd != nil || m.settings.err != nil || m.settings.status != "タグの変更を破棄しました" {
+			t.Errorf("期待結果 変更の破棄, 実際の結果 %+v", m.settings)
+		}
+	})
+
+	t.Run("反映", func(t *testing.T) {
+		m, _ := newSettingsTestModel(t).handleSettingEdited(settingEditedMsg{bucket: "beta", setting: aws.SettingTags, original: original, doc: edited})
+		if m.prompt == nil || m.settings.pending == nil {
+			t.Fatalf("反映の確認が表示されていません: %+v", m.settings)
+		}
+		added := 0
+		for _, line := range m.settings.pending.diff {
+			if line.op == diffAdded {
+				added++
+			}
+		}
+		if added != 3 {
+			t.Errorf("期待結果 %d, 実際の結果 %d", 3, added)
+		}
+		m, cmd := submitPrompt(m, "y")
+		if !m.settings.busy || m.settings.pending != nil || cmd == nil {
+			t.Errorf("反映が始まっていません: %+v", m.settings)
+		}
+	})
+
+	t.Run("閉じた画面", func(t *testing.T) {
+		m := newTestModel(t, []string{"alpha", "beta"})
+		m, _ = m.handleSettingEdited(settingEditedMsg{bucket: "beta", setting: aws.SettingTags, original: original, doc: edited})
+		if m.settings != nil || m.prompt != nil {
+			t.Error("閉じた設定画面が開きました")
+		}
+	})
+}
+
+// TestSettingsEditUnavailable は取得できなかった設定を編集させないことをテストします
+func TestSettingsEditUnavailable(t *testing.T) {
+	m := newSettingsTestModel(t)
+	m.settings.cursor = 3 // バケットポリシー
+	m, cmd := m.handleSettingsKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || m.prompt != nil {
+		t.Error("取得できなかった設定の編集が始まりました")
+	}
+}
@@ -0,0 +1,266 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/state"
+)
+
+// maxTabTitle はタブバーに表示するタブ1つ分の名前の最大文字数です
+const maxTabTitle = 32
+
+// tabMsg はバックグラウンド処理の結果を、処理を始めたタブに届けるためのメッセージです
+type tabMsg struct {
+	tab int // タブのID
+	msg tea.Msg
+}
+
+// isSessionMsg はタブごとの状態を更新するメッセージかを返します。
+// 転送・再試行・ログ画面のメッセージはすべてのタブに共通のため、どのタブで受け取っても構いません。
+func isSessionMsg(msg tea.Msg) bool {
+	switch msg.(type) {
+	case s3ClientInitMsg, bucketsMsg, objectsMsg, listingLoadedMsg, uploadsMsg, uploadsAbortedMsg, locationProbeMsg,
+		detailsMsg, settingsMsg, settingEditedMsg, settingAppliedMsg, bucketOpMsg,
+		editFetchedMsg, editDoneMsg, editSavedMsg, openReadyMsg, openDoneMsg,
+		searchProgressMsg, searchDoneMsg, usageProgressMsg, usageDoneMsg, errorMsg:
+		return true
+	}
+	return false
+}
+
+// tagCmd は cmd の結果のうちタブごとのメッセージを、ID が tab のタブに届くようにします
+func tagCmd(tab int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return tagMsg(tab, cmd())
+	}
+}
+
+// tagMsg は msg がタブごとのメッセージなら tabMsg で包みます。tea.Batch の中のコマンドにも適用します
+func tagMsg(tab int, msg tea.Msg) tea.Msg {
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		cmds := make(tea.BatchMsg, len(batch))
+		for i, cmd := range batch {
+			cmds[i] = tagCmd(tab, cmd)
+		}
+		return cmds
+	}
+	if isSessionMsg(msg) {
+		return tabMsg{tab: tab, msg: msg}
+	}
+	return msg
+}
+
+// execProcess は tea.ExecProcess と同じですが、終了のメッセージをこのタブに届けます
+func (s session) execProcess(c *exec.Cmd, fn tea.ExecCallback) tea.Cmd {
+	id := s.id
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		return tabMsg{tab: id, msg: fn(err)}
+	})
+}
+
+// updateTab はタブ宛てのメッセージを処理します。
+// 表示していないタブ宛ての場合は、そのタブを一時的に表示中として処理し、表示中のタブを元に戻します。
+func (m UIModel) updateTab(msg tabMsg) (tea.Model, tea.Cmd) {
+	if msg.tab == m.id {
+		return m.Update(msg.msg)
+	}
+	i := m.tabIndex(msg.tab)
+	if i < 0 {
+		// 閉じたタブの処理の残りの結果は捨てる
+		return m, nil
+	}
+
+	active := m.activeTab
+	tabs := m.storedTabs()
+	m.tabs, m.activeTab, m.session = tabs, i, tabs[i]
+	model, cmd := m.Update(msg.msg)
+	bg, ok := model.(UIModel)
+	if !ok {
+		return model, cmd
+	}
+	tabs = bg.storedTabs()
+	bg.tabs, bg.activeTab, bg.session = tabs, active, tabs[active]
+	return bg, cmd
+}
+
+// tabIndex は ID が id のタブの位置を返します。見つからない場合は -1 を返します
+func (m UIModel) tabIndex(id int) int {
+	for i, t := range m.tabs {
+		if t.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// storedTabs は表示中のタブの状態を書き戻したタブの一覧を返します。
+// 以前のモデルとスライスを共有しないよう、コピーを返します。
+func (m UIModel) storedTabs() []session {
+	if len(m.tabs) == 0 {
+		return []session{m.session}
+	}
+	tabs := append([]session(nil), m.tabs...)
+	tabs[m.activeTab] = m.session
+	return tabs
+}
+
+// openNewTab は新しいタブで開く場所を尋ねます。空のまま確定するとバケット一覧を開きます
+func (m UIModel) openNewTab(value string, err error) UIModel {
+	m.prompt = m.newInputPrompt(i18n.T("tab.new"), "[profile@]bucket/prefix/", value,
+		func(m UIModel, value string) (UIModel, tea.Cmd) {
+			profile, loc, err := parseTabTarget(strings.TrimSpace(value))
+			if err != nil {
+				return m.openNewTab(value, err), nil
+			}
+			if profile == "" {
+				profile = m.profile
+			}
+			return m.newTab(profile, loc)
+		})
+	m.prompt.err = err
+	return m
+}
+
+// parseTabTarget は "[profile@][s3://]bucket/prefix/" の形式の入力をプロファイルと場所に分けます。
+// 場所を省略した場合はバケット一覧を開くため nil を返します。
+func parseTabTarget(value string) (string, *state.Location, error) {
+	var profile string
+	// バケット名に "@" は使えないため、最初の "/" より前の "@" をプロファイルの区切りとする
+	if i := strings.Index(value, "@"); i >= 0 && !strings.ContainsAny(value[:i], "/:") {
+		profile, value = value[:i], value[i+1:]
+	}
+	if value == "" {
+		return profile, nil, nil
+	}
+	loc, err := parseGoTo(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return profile, &loc, nil
+}
+
+// newTab は profile で loc（nil の場合はバケット一覧）を開くタブを追加し、そのタブを表示します。
+// 表示中のタブと同じプロファイルの場合は S3 クライアントとブックマーク、一覧のキャッシュを共有します。
+func (m UIModel) newTab(profile string, loc *state.Location) (UIModel, tea.Cmd) {
+	s := newSession(m.theme, profile, m.endpointURL)
+	m.tabSeq++
+	s.id = m.tabSeq
+	s.startLocation = loc
+	if profile == m.profile {
+		s.s3Client, s.store, s.listings = m.s3Client, m.store, m.listings
+	} else if m.openProfile != nil {
+		s.store, s.listings = m.openProfile(profile)
+	}
+
+	m.tabs = append(m.storedTabs(), s)
+	m.activeTab = len(m.tabs) - 1
+	m.session = s
+	m.logger.Info("tab opened", "tab", s.id, "profile", profile, "location", tabLocation(loc))
+
+	if m.s3Client == nil {
+		// 初期化が終わると startLocation を開く
+		return m, m.initS3Client()
+	}
+	if loc != nil {
+		return m.goToLocation(*loc)
+	}
+	return m, m.fetchBuckets
+}
+
+// tabLocation はログに記録するタブの場所を返します
+func tabLocation(loc *state.Location) string {
+	if loc == nil {
+		return ""
+	}
+	return loc.String()
+}
+
+// switchTab は i 番目のタブを表示します。範囲外の場合は反対の端から数えます
+func (m UIModel) switchTab(i int) UIModel {
+	tabs := m.storedTabs()
+	i = (i%len(tabs) + len(tabs)) % len(tabs)
+	m.tabs, m.activeTab, m.session = tabs, i, tabs[i]
+	return m
+}
+
+// closeTab は表示中のタブの検索と集計をキャンセルして閉じ、隣のタブを表示します
+func (m UIModel) closeTab() UIModel {
+	m.cancelSearch()
+	m.cancelUsage()
+	m.logger.Info("tab closed", "tab", m.id)
+
+	tabs := m.storedTabs()
+	tabs = append(tabs[:m.activeTab:m.activeTab], tabs[m.activeTab+1:]...)
+	m.activeTab = min(m.activeTab, len(tabs)-1)
+	m.session = tabs[m.activeTab]
+	m.tabs = tabs
+	if len(tabs) == 1 {
+		m.tabs, m.activeTab = nil, 0
+	}
+	return m
+}
+
+// title はタブバーに表示するタブの名前を返します
+func (s session) title() string {
+	var title string
+	switch s.state {
+	case BucketsView:
+		title = i18n.T("tab.buckets")
+	case UploadsView:
+		title = i18n.T("tab.uploads", s.uploadModel.BucketName)
+	case UsageView:
+		title = i18n.T("tab.usage", s.usageModel.BucketName)
+	default:
+		loc := s.currentLocation()
+		title = loc.Bucket + "/" + loc.Prefix
+	}
+	// 長いプレフィックスは末尾の階層を残して省略する
+	if r := []rune(title); len(r) > maxTabTitle {
+		title = "…" + string(r[len(r)-maxTabTitle+1:])
+	}
+	if s.search.running || s.usage.running || s.listing.refreshing {
+		title += " …"
+	}
+	return title
+}
+
+// renderTabBar はタブが2つ以上ある場合に、タブの一覧を1行で描画します。
+// タブごとにプロファイルが異なる場合は、名前の前にプロファイルを表示します。
+func (m UIModel) renderTabBar() string {
+	if len(m.tabs) < 2 {
+		return ""
+	}
+	tabs := m.storedTabs()
+	mixed := false
+	for _, t := range tabs {
+		if t.profile != tabs[0].profile {
+			mixed = true
+		}
+	}
+
+	labels := make([]string, len(tabs))
+	for i, t := range tabs {
+		title := t.title()
+		if mixed {
+			profile := t.profile
+			if profile == "" {
+				profile = "default"
+			}
+			title = profile + "@" + title
+		}
+		label := fmt.Sprintf(" %d:%s ", i+1, title)
+		if i == m.activeTab {
+			labels[i] = m.theme.Selected.Render(label)
+		} else {
+			labels[i] = m.theme.Dim.Render(label)
+		}
+	}
+	return strings.Join(labels, " ")
+}
@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/cache"
+	"github.com/tsuna-can/s3-cli/internal/state"
+)
+
+func TestParseTabTarget(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		profile  string
+		expected string // 場所。空の場合はバケット一覧
+		wantErr  bool
+	}{
+		{name: "空はバケット一覧", value: ""},
+		{name: "プロファイルだけ", value: "prod@", profile: "prod"},
+		{name: "バケットとプレフィックス", value: "alpha/logs/", expected: "s3://alpha/logs/"},
+		{name: "プロファイルとURI", value: "prod@s3://alpha/logs/", profile: "prod", expected: "s3://alpha/logs/"},
+		{name: "キーの中の@はプロファイルではない", value: "alpha/a@b/", expected: "s3://alpha/a@b/"},
+		{name: "URIの中の@はプロファイルではない", value: "s3://alpha/a@b", expected: "s3://alpha/a@b"},
+		{name: "不正な場所", value: "prod@s3://", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			profile, loc, err := parseTabTarget(tc.value)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("エラーが返されませんでした")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			got := tabLocation(loc)
+			if profile != tc.profile || got != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", []string{tc.profile, tc.expected}, []string{profile, got})
+			}
+		})
+	}
+}
+
+// TestTabs はタブを開いて切り替え、表示していないタブにも一覧の取得結果が届くことをテストします
+func TestTabs(t *testing.T) {
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m.s3Client = newTestClient(t)
+	m.bucketModel.Cursor = 1
+	m, _ = m.newTab("", &state.Location{Bucket: "alpha", Prefix: "logs/"})
+	if len(m.tabs) != 2 || m.activeTab != 1 || m.state != ObjectsView || m.id != 1 {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", []any{2, 1, ObjectsView, 1}, []any{len(m.tabs), m.activeTab, m.state, m.id})
+	}
+	if m.s3Client == nil {
+		t.Error("同じプロファイルのタブでS3クライアントが共有されていません")
+	}
+
+	// 次のタブは端から先頭に戻る
+	m = m.switchTab(m.activeTab + 1)
+	if m.activeTab != 0 || m.state != BucketsView || m.bucketModel.Cursor != 1 {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", []any{0, BucketsView, 1}, []any{m.activeTab, m.state, m.bucketModel.Cursor})
+	}
+
+	// 表示していないタブで取得した一覧は、そのタブに反映される
+	model, _ := m.Update(tabMsg{tab: 1, msg: objectsMsg{bucket: "alpha", prefix: "logs/", objects: []aws.ObjectInfo{{Key: "logs/a.log"}}}})
+	m = model.(UIModel)
+	if m.activeTab != 0 || m.state != BucketsView {
+		t.Errorf("表示中のタブが切り替わりました: %v", m.activeTab)
+	}
+	if got := m.tabs[1].objectModel.Objects; len(got) != 1 || got[0] != "logs/a.log" {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []string{"logs/a.log"}, got)
+	}
+	if bar := m.renderTabBar(); !strings.Contains(bar, "1:バケット一覧") || !strings.Contains(bar, "2:alpha/logs/") {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "1:バケット一覧 2:alpha/logs/", bar)
+	}
+
+	// 最後の1つになったらタブバーを表示しない
+	m = m.switchTab(1).closeTab()
+	if m.tabs != nil || m.id != 0 || m.state != BucketsView {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []any{0, BucketsView}, []any{m.id, m.state})
+	}
+	if m.renderTabBar() != "" {
+		t.Error("タブが1つなのにタブバーが表示されています")
+	}
+
+	// 閉じたタブへのメッセージは捨てる
+	model, _ = m.Update(tabMsg{tab: 1, msg: errorMsg{errors.New("closed")}})
+	if model.(UIModel).msg != "" {
+		t.Error("閉じたタブのエラーが表示されました")
+	}
+}
+
+// TestNewTabOtherProfile は別のプロファイルのタブがそのプロファイルのS3クライアントを作ることをテストします
+func TestNewTabOtherProfile(t *testing.T) {
+	opened := ""
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m.openProfile = func(profile string) (*state.Store, *cache.Cache) {
+		opened = profile
+		return nil, nil
+	}
+	m = m.openNewTab("", nil)
+	m, cmd := submitPrompt(m, "prod@alpha/")
+	if opened != "prod" || m.profile != "prod" || m.s3Client != nil || cmd == nil {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "prod", []any{opened, m.profile, m.s3Client})
+	}
+	if m.startLocation == nil || m.startLocation.Bucket != "alpha" {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "alpha", m.startLocation)
+	}
+	if bar := m.renderTabBar(); !strings.Contains(bar, "default@バケット一覧") || !strings.Contains(bar, "prod@") {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "default@バケット一覧 prod@...", bar)
+	}
+}
+
+func TestTagMsg(t *testing.T) {
+	testCases := []struct {
+		name     string
+		msg      tea.Msg
+		expected bool // tabMsg で包まれるか
+	}{
+		{name: "一覧の取得", msg: objectsMsg{}, expected: true},
+		{name: "検索の途中経過", msg: searchProgressMsg{}, expected: true},
+		{name: "転送はすべてのタブに共通", msg: downloadedMsg{}, expected: false},
+		{name: "終了", msg: tea.Quit(), expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, got := tagMsg(3, tc.msg).(tabMsg)
+			if got != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+
+	// tea.Batch の中のコマンドの結果も包む
+	batch := tagMsg(3, tea.BatchMsg{func() tea.Msg { return bucketsMsg{} }}).(tea.BatchMsg)
+	if msg, ok := batch[0]().(tabMsg); !ok || msg.tab != 3 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", 3, msg)
+	}
+}
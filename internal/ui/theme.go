@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// themeNames は選択できるテーマの名前です
+var themeNames = []string{"dark", "light", "high-contrast", "none"}
+
+// Theme は画面の配色とスタイルの定義です
+type Theme struct {
+	Name        string
+	HeaderTitle lipgloss.Style // ヘッダーバーのアプリ名部分
+	HeaderBar   lipgloss.Style // ヘッダーバー（プロファイル・エンドポイント・バケット）
+	Panel       lipgloss.Style // 一覧を囲む枠
+	Item        lipgloss.Style // 通常の行
+	Selected    lipgloss.Style // 選択中の行
+	Dim         lipgloss.Style // メタデータ列やスクロール表示
+	Match       lipgloss.Style // フィルターに一致した文字
+	Status      lipgloss.Style // 状態表示（検索の進捗など）
+	Error       lipgloss.Style // エラー表示
+	Added       lipgloss.Style // 差分で追加された行
+	Removed     lipgloss.Style // 差分で削除された行
+	HelpKey     lipgloss.Style
+	HelpDesc    lipgloss.Style
+}
+
+// ResolveThemeName はフラグ・設定ファイル・NO_COLOR環境変数からテーマ名を決めます。
+// フラグ、設定ファイルの順に優先し、どちらもなければ NO_COLOR が設定されている場合は "none" を使います。
+func ResolveThemeName(flagValue, configValue string) string {
+	switch {
+	case flagValue != "":
+		return flagValue
+	case configValue != "":
+		return configValue
+	case os.Getenv("NO_COLOR") != "":
+		return "none"
+	}
+	return "dark"
+}
+
+// ValidateTheme はテーマ名が使えるものかを確認します
+func ValidateTheme(name string) error {
+	for _, n := range themeNames {
+		if n == name {
+			return nil
+		}
+	}
+	return i18n.Errorf("config.unknown_theme", name, strings.Join(themeNames, ", "))
+}
+
+// newTheme は名前に対応するテーマを、指定したレンダラーで作成します
+func newTheme(name string, r *lipgloss.Renderer) (Theme, error) {
+	if err := ValidateTheme(name); err != nil {
+		return Theme{}, err
+	}
+
+	t := Theme{
+		Name:     name,
+		Panel:    r.NewStyle().Border(lipgloss.RoundedBorder()),
+		Item:     r.NewStyle(),
+		Dim:      r.NewStyle(),
+		Status:   r.NewStyle(),
+		Error:    r.NewStyle().Bold(true),
+		Added:    r.NewStyle(),
+		Removed:  r.NewStyle(),
+		HelpKey:  r.NewStyle(),
+		HelpDesc: r.NewStyle(),
+	}
+
+	switch name {
+	case "dark":
+		t.HeaderBar = r.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Background(lipgloss.Color("#3B3A8C"))
+		t.HeaderTitle = t.HeaderBar.Copy().Bold(true).Background(lipgloss.Color("#5A56E0"))
+		t.Panel = t.Panel.BorderForeground(lipgloss.Color("#5A56E0"))
+		t.Selected = r.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF")).Background(lipgloss.Color("#44447A"))
+		t.Dim = t.Dim.Foreground(lipgloss.Color("#7A7A7A"))
+		t.Match = r.NewStyle().Bold(true).Foreground(lipgloss.Color("#FF87D7"))
+		t.Status = t.Status.Foreground(lipgloss.Color("#87D7FF"))
+		t.Error = t.Error.Foreground(lipgloss.Color("#FF5F5F"))
+		t.Added = t.Added.Foreground(lipgloss.Color("#87D787"))
+		t.Removed = t.Removed.Foreground(lipgloss.Color("#FF8787"))
+		t.HelpKey = t.HelpKey.Foreground(lipgloss.Color("#A8A8A8"))
+		t.HelpDesc = t.HelpDesc.Foreground(lipgloss.Color("#6C6C6C"))
+	case "light":
+		t.HeaderBar = r.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Background(lipgloss.Color("#3F51B5"))
+		t.HeaderTitle = t.HeaderBar.Copy().Bold(true).Background(lipgloss.Color("#283593"))
+		t.Panel = t.Panel.BorderForeground(lipgloss.Color("#3F51B5"))
+		t.Selected = r.NewStyle().Bold(true).Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#D7D7FF"))
+		t.Dim = t.Dim.Foreground(lipgloss.Color("#8A8A8A"))
+		t.Match = r.NewStyle().Bold(true).Foreground(lipgloss.Color("#D7005F"))
+		t.Status = t.Status.Foreground(lipgloss.Color("#005F87"))
+		t.Error = t.Error.Foreground(lipgloss.Color("#D70000"))
+		t.Added = t.Added.Foreground(lipgloss.Color("#008700"))
+		t.Removed = t.Removed.Foreground(lipgloss.Color("#AF0000"))
+		t.HelpKey = t.HelpKey.Foreground(lipgloss.Color("#5F5F5F"))
+		t.HelpDesc = t.HelpDesc.Foreground(lipgloss.Color("#8A8A8A"))
+	case "high-contrast":
+		// 16色の基本色だけを使い、淡い色は使わない
+		t.HeaderBar = r.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("15"))
+		t.HeaderTitle = t.HeaderBar.Copy().Background(lipgloss.Color("11"))
+		t.Panel = t.Panel.Border(lipgloss.ThickBorder()).BorderForeground(lipgloss.Color("15"))
+		t.Selected = r.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("11"))
+		t.Dim = t.Dim.Foreground(lipgloss.Color("15"))
+		t.Match = r.NewStyle().Bold(true).Underline(true).Foreground(lipgloss.Color("14"))
+		t.Status = t.Status.Foreground(lipgloss.Color("14"))
+		t.Error = t.Error.Foreground(lipgloss.Color("9"))
+		t.Added = t.Added.Foreground(lipgloss.Color("10"))
+		t.Removed = t.Removed.Foreground(lipgloss.Color("9"))
+		t.HelpKey = t.HelpKey.Bold(true).Foreground(lipgloss.Color("15"))
+		t.HelpDesc = t.HelpDesc.Foreground(lipgloss.Color("15"))
+	default:
+		// "none": 色を使わず、反転・太字・下線だけで表現する
+		t.HeaderBar = r.NewStyle().Reverse(true)
+		t.HeaderTitle = t.HeaderBar.Copy().Bold(true)
+		t.Selected = r.NewStyle().Reverse(true)
+		t.Match = r.NewStyle().Bold(true).Underline(true)
+		t.HelpKey = t.HelpKey.Bold(true)
+	}
+	return t, nil
+}
+
+// applyToHelp はヘルプ表示のスタイルにテーマを適用します
+func (t Theme) applyToHelp(h *help.Model) {
+	h.Styles.ShortKey = t.HelpKey
+	h.Styles.ShortDesc = t.HelpDesc
+	h.Styles.ShortSeparator = t.Dim
+	h.Styles.FullKey = t.HelpKey
+	h.Styles.FullDesc = t.HelpDesc
+	h.Styles.FullSeparator = t.Dim
+	h.Styles.Ellipsis = t.Dim
+}
+
+// applyToInput は入力欄のスタイルにテーマを適用します
+func (t Theme) applyToInput(input *textinput.Model) {
+	input.PromptStyle = t.Item
+	input.TextStyle = t.Item
+	input.PlaceholderStyle = t.Dim
+	input.Cursor.Style = t.Selected
+	input.Cursor.TextStyle = t.Item
+}
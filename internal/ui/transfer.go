@@ -0,0 +1,237 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+)
+
+// maxFinishedTransfers は転送パネルに残しておく終了済みの転送の数です
+const maxFinishedTransfers = 3
+
+// progressInterval は転送の進捗をUIに通知する最短の間隔です
+const progressInterval = 100 * time.Millisecond
+
+// bandwidthSteps は +/- で切り替える帯域制限の段階です（1秒あたりのバイト数）。
+// 最大の段階より上は無制限になります。
+var bandwidthSteps = []int64{
+	256 << 10, 512 << 10,
+	1 << 20, 2 << 20, 5 << 20, 10 << 20, 20 << 20, 50 << 20, 100 << 20,
+}
+
+// transferState は転送パネルに表示する1件の転送です
+type transferState struct {
+	id       int
+	bucket   string
+	key      string
+	path     string // 保存先のパス（完了後）
+	done     int64
+	total    int64
+	finished bool
+	skipped  bool
+	err      error
+}
+
+// transferEvent は転送中のゴルーチンから送られる進捗です
+type transferEvent struct {
+	id    int
+	done  int64
+	total int64
+}
+
+// startDownload は転送パネルに転送を追加し、バックグラウンドでダウンロードを始めます
+func (m UIModel) startDownload(bucket, key string) (UIModel, tea.Cmd) {
+	m.transferSeq++
+	id := m.transferSeq
+	m.transfers = append(m.transfers, transferState{id: id, bucket: bucket, key: key})
+
+	client := m.s3Client
+	events := m.transferEvents
+	opts := aws.DownloadOptions{
+		OutputDir:      m.outputDir,
+		ConflictPolicy: m.conflictPolicy,
+		Progress:       throttledProgress(id, events),
+	}
+	m.logger.Info("download started", "bucket", bucket, "key", key)
+	return m, func() tea.Msg {
+		path, err := client.DownloadObject(context.Background(), bucket, key, opts)
+		if errors.Is(err, aws.ErrSkipped) {
+			return downloadedMsg{id: id, bucket: bucket, key: key, path: path, skipped: true}
+		}
+		return downloadedMsg{id: id, bucket: bucket, key: key, path: path, err: err}
+	}
+}
+
+// throttledProgress は進捗を一定間隔でだけチャネルに送るコールバックを返します。
+// UIの処理が追いつかない場合は、その進捗を捨てます。
+func throttledProgress(id int, events chan<- transferEvent) func(done, total int64) {
+	var last time.Time
+	return func(done, total int64) {
+		if time.Since(last) < progressInterval && done < total {
+			return
+		}
+		last = time.Now()
+		select {
+		case events <- transferEvent{id: id, done: done, total: total}:
+		default:
+		}
+	}
+}
+
+// waitForTransfer は次の転送の進捗を待つコマンドを返します
+func waitForTransfer(events <-chan transferEvent) tea.Cmd {
+	return func() tea.Msg {
+		return transferProgressMsg{event: <-events}
+	}
+}
+
+// handleTransferProgress は転送の進捗を反映します
+func (m UIModel) handleTransferProgress(msg transferProgressMsg) (UIModel, tea.Cmd) {
+	m.transfers = append([]transferState(nil), m.transfers...)
+	for i := range m.transfers {
+		if t := &m.transfers[i]; t.id == msg.event.id && !t.finished {
+			t.done, t.total = msg.event.done, msg.event.total
+		}
+	}
+	return m, waitForTransfer(m.transferEvents)
+}
+
+// handleDownloaded はダウンロードの終了を反映し、古い終了済みの転送をパネルから除きます
+func (m UIModel) handleDownloaded(msg downloadedMsg) UIModel {
+	transfers := make([]transferState, 0, len(m.transfers))
+	finished := 0
+	for i := len(m.transfers) - 1; i >= 0; i-- {
+		t := m.transfers[i]
+		if t.id == msg.id {
+			t.finished, t.skipped, t.err, t.path = true, msg.skipped, msg.err, msg.path
+			if msg.err == nil && t.total > 0 {
+				t.done = t.total
+			}
+		}
+		if t.finished {
+			finished++
+			if finished > maxFinishedTransfers {
+				continue
+			}
+		}
+		transfers = append([]transferState{t}, transfers...)
+	}
+	m.transfers = transfers
+
+	if msg.err != nil {
+		m.logger.Error("download failed", "bucket", msg.bucket, "key", msg.key, "error", msg.err)
+	} else {
+		m.logger.Info("download finished", "bucket", msg.bucket, "key", msg.key, "path", msg.path, "skipped", msg.skipped)
+	}
+	return m
+}
+
+// bandwidthLimit は現在の帯域制限（1秒あたりのバイト数、0は無制限）を返します
+func (m UIModel) bandwidthLimit() int64 {
+	if m.s3Client != nil {
+		return m.s3Client.Bandwidth().Limit()
+	}
+	return m.clientOptions.BandwidthLimit
+}
+
+// changeBandwidth は帯域制限を1段階上げる（up=true）か下げます。実行中の転送にもすぐに反映されます
+func (m UIModel) changeBandwidth(up bool) UIModel {
+	if m.s3Client == nil {
+		return m
+	}
+	limit := nextBandwidth(m.bandwidthLimit(), up)
+	m.s3Client.Bandwidth().SetLimit(limit)
+	m.logger.Info("bandwidth limit changed", "bytes_per_second", limit)
+	return m
+}
+
+// nextBandwidth は current の1段階上または下の帯域制限を返します（0は無制限）
+func nextBandwidth(current int64, up bool) int64 {
+	if up {
+		if current == 0 {
+			return 0
+		}
+		for _, step := range bandwidthSteps {
+			if step > current {
+				return step
+			}
+		}
+		return 0
+	}
+
+	if current == 0 {
+		return bandwidthSteps[len(bandwidthSteps)-1]
+	}
+	for i := len(bandwidthSteps) - 1; i >= 0; i-- {
+		if bandwidthSteps[i] < current {
+			return bandwidthSteps[i]
+		}
+	}
+	return bandwidthSteps[0]
+}
+
+// formatBandwidth は帯域制限を表示用の文字列にします
+func formatBandwidth(limit int64) string {
+	if limit == 0 {
+		return i18n.T("transfer.unlimited")
+	}
+	return formatSize(limit) + "/s"
+}
+
+// showTransferPanel は転送パネルを表示するかを返します
+func (m UIModel) showTransferPanel() bool {
+	return len(m.transfers) > 0 || m.bandwidthLimit() > 0
+}
+
+// transferPanelHeight は転送パネルの行数を返します
+func (m UIModel) transferPanelHeight() int {
+	if !m.showTransferPanel() {
+		return 0
+	}
+	return len(m.transfers) + 1
+}
+
+// renderTransfers は転送パネル（帯域制限と転送中・終了済みの転送）を描画します
+func (m UIModel) renderTransfers() string {
+	if !m.showTransferPanel() {
+		return ""
+	}
+	width := m.viewWidth()
+	title := i18n.T("transfer.title", formatBandwidth(m.bandwidthLimit()),
+		m.keys.BandwidthUp.Help().Key, m.keys.BandwidthDown.Help().Key)
+	view := "\n" + m.theme.Status.Render(runewidth.Truncate(title, width, "…"))
+
+	for _, t := range m.transfers {
+		var line string
+		style := m.theme.Item
+		switch {
+		case t.err != nil:
+			line = "✗ " + t.key + ": " + i18n.T("ui.error", t.err)
+			style = m.theme.Error
+		case t.skipped:
+			line = "– " + i18n.T("ui.download_skipped", t.path)
+			style = m.theme.Dim
+		case t.finished:
+			line = "✓ " + i18n.T("ui.download_done", t.bucket, t.key, t.path)
+			style = m.theme.Dim
+		default:
+			line = fmt.Sprintf("⇣ %s  %s", t.key, formatProgress(t.done, t.total))
+		}
+		view += "\n" + style.Render(runewidth.Truncate("  "+line, width, "…"))
+	}
+	return view
+}
+
+// formatProgress は転送済みのバイト数と割合を表示用の文字列にします
+func formatProgress(done, total int64) string {
+	if total <= 0 {
+		return formatSize(done)
+	}
+	return fmt.Sprintf("%s / %s  %d%%", formatSize(done), formatSize(total), done*100/total)
+}
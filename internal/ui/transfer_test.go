@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/tsuna-can/s3-cli/internal/logging"
+)
+
+// TestNextBandwidth は +/- による帯域制限の切り替えをテストします
+func TestNextBandwidth(t *testing.T) {
+	testCases := []struct {
+		name     string
+		current  int64
+		up       bool
+		expected int64
+	}{
+		{name: "無制限から下げる", current: 0, up: false, expected: 100 << 20},
+		{name: "無制限から上げる", current: 0, up: true, expected: 0},
+		{name: "最大の段階から上げる", current: 100 << 20, up: true, expected: 0},
+		{name: "最小の段階から下げる", current: 256 << 10, up: false, expected: 256 << 10},
+		{name: "段階の間から上げる", current: 3 << 20, up: true, expected: 5 << 20},
+		{name: "段階の間から下げる", current: 3 << 20, up: false, expected: 2 << 20},
+		{name: "段階から下げる", current: 20 << 20, up: false, expected: 10 << 20},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := nextBandwidth(tc.current, tc.up); got != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestHandleDownloaded は終了した転送が残り、古い終了済みの転送が消えることをテストします
+func TestHandleDownloaded(t *testing.T) {
+	m := UIModel{logger: logging.Disabled()}
+	for id := 1; id <= maxFinishedTransfers+2; id++ {
+		m.transfers = append(m.transfers, transferState{id: id, key: "key", total: 10})
+	}
+
+	for id := 1; id <= maxFinishedTransfers+1; id++ {
+		m = m.handleDownloaded(downloadedMsg{id: id, key: "key", path: "out"})
+	}
+
+	var ids []int
+	for _, tr := range m.transfers {
+		ids = append(ids, tr.id)
+	}
+	expected := []int{2, 3, 4, 5}
+	if len(ids) != len(expected) {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", expected, ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Fatalf("期待結果 %v, 実際の結果 %v", expected, ids)
+		}
+	}
+	if tr := m.transfers[0]; !tr.finished || tr.done != tr.total {
+		t.Errorf("終了した転送が完了になっていません: %+v", tr)
+	}
+	if m.transfers[len(m.transfers)-1].finished {
+		t.Error("実行中の転送が終了になっています")
+	}
+}
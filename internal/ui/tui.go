@@ -1,85 +1,216 @@
 package ui
 
 import (
-	"fmt"
-	"log"
-	"os"
-
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/cache"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/logging"
 	"github.com/tsuna-can/s3-cli/internal/model"
+	"github.com/tsuna-can/s3-cli/internal/state"
 )
 
 // UIModel represents the state for the terminal UI
 type UIModel struct {
-	s3Client    *aws.S3Client
-	state       ViewState
-	bucketModel model.BucketListModel
-	objectModel model.ObjectListModel
-	filterInput textinput.Model
-	outputDir   string
-	profile     string
-	endpointURL string
-	err         error
-	msg         string
-	width       int // ウィンドウ幅
-	height      int // ウィンドウ高さ
+	session                      // 表示中のタブの状態
+	tabs           []session     // タブが2つ以上の場合のすべてのタブ。表示中のタブの最新の状態は session にある
+	activeTab      int           // 表示中のタブの位置
+	tabSeq         int           // タブのIDの通し番号
+	openProfile    ProfileOpener // 別のプロファイルのタブで使うブックマークとキャッシュを開く（なければ使わない）
+	theme          Theme         // 配色テーマ
+	keys           keyMap        // キー操作の定義
+	help           help.Model    // フッターとヘルプ画面の描画
+	showHelp       bool          // ヘルプ画面を表示中
+	showLogs       bool          // ログ画面を表示中
+	logScroll      int           // ログ画面で末尾から何行さかのぼって表示しているか
+	logTick        int           // ログ画面の再描画タイマーの世代（古いタイマーを止めるため）
+	logger         *logging.Logger
+	clientOptions  aws.ClientOptions   // S3クライアントの接続設定
+	retryEvents    chan aws.RetryEvent // S3呼び出しの再試行の通知
+	retry          *retryStatus        // 表示中の再試行の状態（なければnil）
+	retrySeq       int                 // 再試行の通知の通し番号（古い表示消去タイマーを無視するため）
+	transfers      []transferState     // 転送パネルに表示する転送
+	transferSeq    int                 // 転送のIDの通し番号
+	transferEvents chan transferEvent  // 転送の進捗の通知
+	openWith       map[string]string   // 拡張子ごとのオブジェクトを開くコマンド
+	openTemps      []string            // 外部のプログラムで開くために作成した一時ディレクトリ
+	outputDir      string
+	concurrency    int                // バックグラウンド処理の並列数
+	conflictPolicy aws.ConflictPolicy // ダウンロード先に同名ファイルがある場合の動作
+	width          int                // ウィンドウ幅
+	height         int                // ウィンドウ高さ
+}
+
+// session はタブごとの閲覧の状態です。
+// 表示していないタブの一覧の取得や検索も、そのタブの session に反映されます。
+type session struct {
+	id            int // タブのID。バックグラウンド処理の結果をタブに届けるために使う
+	s3Client      *aws.S3Client
+	state         ViewState
+	bucketModel   model.BucketListModel
+	objectModel   model.ObjectListModel
+	uploadModel   model.UploadListModel
+	uploads       uploadsState // マルチパートアップロード一覧の状態
+	usageModel    model.UsageListModel
+	usage         usageState // 使用量の集計の状態
+	filterInput   textinput.Model
+	matchMode     MatchMode       // フィルターの一致方式
+	filterErr     error           // フィルターパターンのエラー（不正な正規表現など）
+	prompt        *inputPrompt    // 表示中の入力ダイアログ（なければnil）
+	details       *detailsState   // 表示中のオブジェクトの詳細画面（なければnil）
+	bucketOp      bucketOpState   // バケットの作成・削除の状態
+	settings      *settingsState  // 表示中のバケットの設定画面（なければnil）
+	objectOp      objectOpState   // オブジェクトの編集・外部のプログラムで開く操作の状態
+	bookmarks     *bookmarksState // 表示中のブックマークの画面（なければnil）
+	store         *state.Store    // ブックマークと最近開いた場所（なければ記録しない）
+	startLocation *state.Location // 起動時に開く場所（なければバケット一覧）
+	history       navHistory      // 戻る・進むの履歴
+	pending       *viewPosition   // 一覧の取得後に復元する表示位置（なければ先頭か今の位置）
+	listings      *cache.Cache    // オブジェクト一覧のキャッシュ（なければ毎回取得する）
+	listing       listingState    // 表示しているオブジェクト一覧の取得の状態
+	search        searchState     // サーバー側検索の状態
+	profile       string
+	endpointURL   string
+	err           error
+	msg           string
+}
+
+// ProfileOpener はプロファイルのブックマークと最近開いた場所の保存先、オブジェクト一覧のキャッシュを返します。
+// 保存先を開けない場合はストアに nil を返します。
+type ProfileOpener func(profile string) (*state.Store, *cache.Cache)
+
+// Options はUIの起動オプションです
+type Options struct {
+	OutputDir   string
+	Profile     string
+	EndpointURL string
+	Concurrency int // 検索などのバックグラウンド処理の並列数
+	// ConflictPolicy はダウンロード先に同名ファイルがある場合の動作です
+	ConflictPolicy aws.ConflictPolicy
+	// Keybindings は操作名ごとのキー割り当ての上書きです
+	Keybindings map[string][]string
+	// OpenWith は拡張子（"*" はその他すべて）ごとの、オブジェクトを開くコマンドです
+	OpenWith map[string]string
+	// State はブックマークと最近開いた場所の保存先です（nilの場合は記録しません）
+	State *state.Store
+	// Open は起動時に開く場所です（nilの場合はバケット一覧から始めます）
+	Open *state.Location
+	// Listings はオブジェクト一覧のキャッシュです（nilの場合は開くたびに取得します）
+	Listings *cache.Cache
+	// OpenProfile は別のプロファイルで開いたタブの State と Listings を返します
+	// （nilの場合、そのタブでは記録もキャッシュもしません）
+	OpenProfile ProfileOpener
+	// Theme は配色テーマの名前です（dark, light, high-contrast, none）
+	Theme string
+	// Logger はデバッグログの出力先です（nilの場合はログを記録しません）
+	Logger *logging.Logger
+	// Client はS3クライアントのリトライ・制限時間・流量制限の設定です。
+	// Profile、EndpointURL、Logger、OnRetry はUIが設定します。
+	Client aws.ClientOptions
 }
 
 // StartUI initializes and starts the terminal UI
-func StartUI(outputDir string, profile string, endpointURL string, debugMode bool) {
-	// デバッグログを設定
-	logFile, err := os.Create("/tmp/s3-cli-debug.log")
-	if err == nil {
-		defer logFile.Close()
-		log.SetOutput(logFile)
-		log.SetPrefix("DEBUG: ")
-		log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
+func StartUI(opts Options) error {
+	keys, err := newKeyMap(opts.Keybindings)
+	if err != nil {
+		return err
+	}
+	theme, err := newTheme(opts.Theme, lipgloss.DefaultRenderer())
+	if err != nil {
+		return err
 	}
 
-	log.Println("アプリケーション起動")
+	logger := opts.Logger
+	if logger == nil {
+		logger = logging.Disabled()
+	}
+	logger.Info("starting UI", "profile", opts.Profile, "endpoint", opts.EndpointURL, "theme", theme.Name)
 
 	// outputDirが空の場合はカレントディレクトリを使う
+	outputDir := opts.OutputDir
 	if outputDir == "" {
 		outputDir = "."
 	}
 
-	filterInput := textinput.New()
-	filterInput.Placeholder = "Filter buckets..."
-	filterInput.Prompt = "🔍 "
-	filterInput.Focus()
+	helpModel := help.New()
+	theme.applyToHelp(&helpModel)
 
 	initialModel := UIModel{
-		state:       BucketsView,
-		filterInput: filterInput,
-		outputDir:   outputDir,
-		profile:     profile,
-		endpointURL: endpointURL,
+		session:        newSession(theme, opts.Profile, opts.EndpointURL),
+		openProfile:    opts.OpenProfile,
+		theme:          theme,
+		keys:           keys,
+		help:           helpModel,
+		logger:         logger,
+		clientOptions:  opts.Client,
+		retryEvents:    make(chan aws.RetryEvent, 16),
+		transferEvents: make(chan transferEvent, 64),
+		outputDir:      outputDir,
+		concurrency:    opts.Concurrency,
+		conflictPolicy: opts.ConflictPolicy,
+		openWith:       opts.OpenWith,
 	}
+	initialModel.store = opts.State
+	initialModel.startLocation = opts.Open
+	initialModel.listings = opts.Listings
 
 	p := tea.NewProgram(initialModel)
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error running UI: %v\n", err)
+	final, err := p.Run()
+	if m, ok := final.(UIModel); ok {
+		m.removeOpenTemps()
 	}
+	if err != nil {
+		return i18n.Errorf("ui.run_failed", err)
+	}
+	return nil
 }
 
 // Init initializes the UI model
 func (m UIModel) Init() tea.Cmd {
-	return m.initS3Client()
+	return tea.Batch(tagCmd(m.id, m.initS3Client()), waitForRetry(m.retryEvents), waitForTransfer(m.transferEvents))
+}
+
+// newSession はバケット一覧から始める、タブ1つ分の状態を作成します
+func newSession(theme Theme, profile, endpointURL string) session {
+	filterInput := textinput.New()
+	filterInput.Placeholder = i18n.T("ui.filter_buckets")
+	filterInput.Prompt = filterPrompt(MatchSubstring)
+	theme.applyToInput(&filterInput)
+
+	return session{
+		state:       BucketsView,
+		filterInput: filterInput,
+		profile:     profile,
+		endpointURL: endpointURL,
+	}
 }
 
 // initS3Client initializes the S3 client using AWS configuration
-func (m *UIModel) initS3Client() tea.Cmd {
+func (m UIModel) initS3Client() tea.Cmd {
+	opts := m.clientOptions
+	opts.Profile = m.profile
+	opts.EndpointURL = m.endpointURL
+	opts.Logger = m.logger.Logger
+	events := m.retryEvents
+	opts.OnRetry = func(e aws.RetryEvent) {
+		// 表示が追いつかない場合は古い通知を捨てる
+		select {
+		case events <- e:
+		default:
+		}
+	}
+	logger := m.logger
 	return func() tea.Msg {
-		log.Println("S3クライアント初期化開始")
-		client, err := aws.NewS3Client(m.profile, m.endpointURL)
+		client, err := aws.NewS3Client(opts)
 		if err != nil {
-			log.Printf("S3クライアント初期化エラー: %v\n", err)
+			logger.Error("failed to initialize S3 client", "error", err)
 			return errorMsg{err}
 		}
-		log.Printf("S3クライアント初期化成功。プロファイル: %s, リージョン: %s, エンドポイント: %s\n",
-			client.GetProfile(), client.GetRegion(), client.GetEndpointURL())
+		logger.Info("S3 client initialized",
+			"profile", client.GetProfile(), "region", client.GetRegion(), "endpoint", client.GetEndpointURL())
 		return s3ClientInitMsg{client}
 	}
 }
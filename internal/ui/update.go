@@ -2,13 +2,32 @@ package ui
 
 import (
 	"context"
-	"fmt"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/query"
+	"github.com/tsuna-can/s3-cli/internal/state"
 )
 
 // Update はUIイベントを処理し、モデルを更新します
 func (m UIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tabMsg); ok {
+		return m.updateTab(msg)
+	}
+	model, cmd := m.update(msg)
+	if m, ok := model.(UIModel); ok {
+		// カーソルの移動や一覧の入れ替えの後も、カーソルが表示範囲に収まるようにする
+		model = m.syncScroll()
+		// バックグラウンド処理の結果は、タブを切り替えた後も処理を始めたタブに届ける
+		cmd = tagCmd(m.id, cmd)
+	}
+	return model, cmd
+}
+
+// update はUIイベントごとの処理を振り分けます
+func (m UIModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		// ウィンドウサイズの更新
@@ -17,6 +36,9 @@ func (m UIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.prompt != nil {
+			return m.handlePromptKey(msg)
+		}
 		model, cmd := m.handleKeyMsg(msg)
 		if model != nil {
 			return model, cmd
@@ -25,26 +47,105 @@ func (m UIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case s3ClientInitMsg:
 		m.s3Client = msg.client
+		if m.startLocation != nil {
+			// バケット一覧を経由せずに、指定された場所を開く
+			return m.goToLocation(*m.startLocation)
+		}
 		return m, m.fetchBuckets
 
+	case uploadsMsg:
+		return m.handleUploads(msg), nil
+
+	case uploadsAbortedMsg:
+		return m.handleUploadsAborted(msg)
+
+	case detailsMsg:
+		return m.handleDetails(msg), nil
+
+	case settingsMsg:
+		return m.handleSettings(msg), nil
+
+	case settingEditedMsg:
+		return m.handleSettingEdited(msg)
+
+	case settingAppliedMsg:
+		return m.handleSettingApplied(msg)
+
+	case bucketOpMsg:
+		return m.handleBucketOp(msg)
+
+	case editFetchedMsg:
+		return m.handleEditFetched(msg)
+
+	case editDoneMsg:
+		return m.handleEditDone(msg)
+
+	case editSavedMsg:
+		return m.handleEditSaved(msg), nil
+
+	case openReadyMsg:
+		return m.handleOpenReady(msg)
+
+	case openDoneMsg:
+		return m.handleOpenDone(msg), nil
+
 	case bucketsMsg:
-		m.bucketModel.Buckets = msg.buckets
-		m.bucketModel.FilteredBuckets = msg.buckets
+		buckets := msg.buckets
+		if msg.err != nil {
+			// ListBuckets が許可されていなくても使えるよう、開けたことのあるバケットを代わりに表示する
+			m.logger.Warn("listing buckets failed, showing known buckets instead", "error", msg.err)
+			buckets = knownBuckets(m.store)
+			m.bucketOp = bucketOpState{status: i18n.T("bucket.list_failed", m.keys.GoTo.Help().Key), err: msg.err}
+		}
+		m.bucketModel.Buckets = buckets
+		m.bucketModel.FilteredBuckets = buckets
 		m.bucketModel.Cursor = 0
+		if m.pending != nil && m.state == BucketsView {
+			// 履歴で戻ったバケット一覧の表示位置を復元する
+			m.applyFilter()
+			m.bucketModel.Cursor, m.bucketModel.Offset = m.pending.locate(m.bucketModel.FilteredBuckets)
+			m.pending = nil
+		}
 
 	case objectsMsg:
-		m.objectModel.Objects = msg.objects
-		m.objectModel.FilteredObjects = msg.objects
-		m.objectModel.Cursor = 0
+		return m.handleObjects(msg), nil
+
+	case listingLoadedMsg:
+		return m.handleListingLoaded(msg)
+
+	case locationProbeMsg:
+		return m.handleLocationProbe(msg)
+
+	case searchProgressMsg:
+		return m.handleSearchProgress(msg)
+
+	case searchDoneMsg:
+		return m.handleSearchDone(msg), nil
+
+	case usageProgressMsg:
+		return m.handleUsageProgress(msg)
+
+	case usageDoneMsg:
+		return m.handleUsageDone(msg), nil
+
+	case retryMsg:
+		return m.handleRetry(msg)
+
+	case retryClearMsg:
+		return m.handleRetryClear(msg), nil
+
+	case logTickMsg:
+		return m, m.handleLogTick(msg)
 
 	case errorMsg:
 		m.err = nil
-		m.msg = fmt.Sprintf("エラー: %v", msg.err)
+		m.msg = i18n.T("ui.error", msg.err)
 
 	case downloadedMsg:
-		m.err = nil
-		m.msg = fmt.Sprintf("ダウンロード完了: %s/%s → %s", msg.bucket, msg.key, msg.outputDir)
-		return m, tea.Quit
+		return m.handleDownloaded(msg), nil
+
+	case transferProgressMsg:
+		return m.handleTransferProgress(msg)
 	}
 
 	var cmd tea.Cmd
@@ -59,77 +160,352 @@ func (m UIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKeyMsg はキーボード入力を処理します
 func (m UIModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyCtrlC:
+	km := m.keys
+
+	// ヘルプ表示中はヘルプを閉じる操作だけを受け付ける
+	if m.showHelp {
+		switch {
+		case msg.Type == tea.KeyCtrlC:
+			return m, tea.Quit
+		case key.Matches(msg, km.Help, km.Back, km.Quit):
+			m.showHelp = false
+		}
+		return m, nil
+	}
+
+	if m.showLogs {
+		return m.handleLogsKey(msg), nil
+	}
+
+	if m.details != nil {
+		return m.handleDetailsKey(msg)
+	}
+
+	if m.settings != nil {
+		return m.handleSettingsKey(msg)
+	}
+
+	if m.bookmarks != nil {
+		return m.handleBookmarksKey(msg)
+	}
+
+	// フィルター入力中は文字入力をフィルターに渡し、Enter/Escで入力を終える
+	if m.filterInput.Focused() {
+		switch {
+		case isTextInput(msg):
+			return nil, nil
+		case msg.Type == tea.KeyEnter || msg.Type == tea.KeyEsc:
+			m.filterInput.Blur()
+			return m, nil
+		}
+	}
+
+	switch {
+	case msg.Type == tea.KeyCtrlC, key.Matches(msg, km.Quit):
 		return m, tea.Quit
 
-	case tea.KeyEsc:
+	case key.Matches(msg, km.Help):
+		m.showHelp = true
+		return m, nil
+
+	case key.Matches(msg, km.NewTab):
+		return m.openNewTab("", nil), nil
+
+	case key.Matches(msg, km.CloseTab):
+		if len(m.tabs) > 1 {
+			return m.closeTab(), nil
+		}
+
+	case key.Matches(msg, km.NextTab):
+		if len(m.tabs) > 1 {
+			return m.switchTab(m.activeTab + 1), nil
+		}
+
+	case key.Matches(msg, km.PrevTab):
+		if len(m.tabs) > 1 {
+			return m.switchTab(m.activeTab - 1), nil
+		}
+	}
+
+	if m.state == UploadsView {
+		if model, cmd, ok := m.handleUploadsKey(msg); ok {
+			return model, cmd
+		}
+	}
+	if m.state == UsageView {
+		if model, cmd, ok := m.handleUsageKey(msg); ok {
+			return model, cmd
+		}
+	}
+
+	switch {
+	case key.Matches(msg, km.Uploads):
+		if m.state == BucketsView && len(m.bucketModel.FilteredBuckets) > 0 {
+			return m.openUploads(m.bucketModel.FilteredBuckets[m.bucketModel.Cursor])
+		}
+		if m.state == ObjectsView {
+			return m.openUploads(m.objectModel.BucketName)
+		}
+
+	case key.Matches(msg, km.Usage):
+		if m.state == BucketsView && len(m.bucketModel.FilteredBuckets) > 0 {
+			return m.openUsage(m.bucketModel.FilteredBuckets[m.bucketModel.Cursor], "")
+		}
+		if m.state == ObjectsView {
+			loc := m.currentLocation()
+			return m.openUsage(loc.Bucket, loc.Prefix)
+		}
+
+	case key.Matches(msg, km.BandwidthUp):
+		return m.changeBandwidth(true), nil
+
+	case key.Matches(msg, km.BandwidthDown):
+		return m.changeBandwidth(false), nil
+
+	case key.Matches(msg, km.Logs):
+		return m.openLogs()
+
+	case key.Matches(msg, km.CreateBucket):
+		// S3クライアントの初期化に失敗した（または終わっていない）間は作成・削除できない
+		if m.state == BucketsView && !m.bucketOp.busy && m.s3Client != nil {
+			return m.openCreateBucket("", nil), nil
+		}
+
+	case key.Matches(msg, km.DeleteBucket):
+		if m.state == BucketsView && !m.bucketOp.busy && m.s3Client != nil {
+			return m.openDeleteBucket("", nil), nil
+		}
+
+	case key.Matches(msg, km.Settings):
+		if m.state == BucketsView && len(m.bucketModel.FilteredBuckets) > 0 {
+			return m.openSettings(m.bucketModel.FilteredBuckets[m.bucketModel.Cursor])
+		}
 		if m.state == ObjectsView {
-			m.state = BucketsView
-			m.filterInput.Reset()
-			m.filterInput.Placeholder = "Filter buckets..."
+			return m.openSettings(m.objectModel.BucketName)
+		}
+
+	case key.Matches(msg, km.Details):
+		if m.state == ObjectsView {
+			return m.openDetails()
+		}
+
+	case key.Matches(msg, km.Edit):
+		if m.state == ObjectsView && !m.objectOp.busy && len(m.objectModel.FilteredObjects) > 0 {
+			return m.startEdit(m.objectModel.BucketName, m.objectModel.FilteredObjects[m.objectModel.Cursor])
+		}
+
+	case key.Matches(msg, km.OpenWith):
+		if m.state == ObjectsView && !m.objectOp.busy {
+			return m.openOpenWith(), nil
+		}
+
+	case key.Matches(msg, km.Bookmark):
+		if m.state == ObjectsView && !m.objectOp.busy {
+			return m.toggleBookmark(), nil
+		}
+
+	case key.Matches(msg, km.Refresh):
+		if m.state == ObjectsView && m.search.progress == nil && !m.listing.refreshing {
+			return m.refreshListing()
+		}
+		if m.state == BucketsView && m.s3Client == nil {
+			// S3クライアントの初期化に失敗した場合は、初期化からやり直す
+			return m, m.initS3Client()
+		}
+		if m.state == BucketsView && !m.bucketOp.busy {
+			return m, m.fetchBuckets
+		}
+
+	case key.Matches(msg, km.GoTo):
+		if m.state.browsing() {
+			return m.openGoTo("", nil), nil
+		}
+
+	case key.Matches(msg, km.Bookmarks):
+		if m.state.browsing() {
+			return m.openBookmarks(), nil
+		}
+
+	case key.Matches(msg, km.Filter):
+		if m.state.browsing() {
+			return m, m.filterInput.Focus()
+		}
+
+	case key.Matches(msg, km.MatchMode):
+		// フィルターの一致方式を切り替える
+		m.matchMode = m.matchMode.Next()
+		m.filterInput.Prompt = filterPrompt(m.matchMode)
+		m.applyFilter()
+		return m, nil
+
+	case key.Matches(msg, km.Search):
+		// サーバー側検索のダイアログを開く
+		if m.state == ObjectsView {
+			return m.openSearchPrompt(), nil
+		}
+
+	case key.Matches(msg, km.CancelSearch):
+		if m.state == ObjectsView && m.search.running {
+			m.cancelSearch()
 			return m, nil
 		}
 
-	case tea.KeyEnter:
+	case key.Matches(msg, km.Back):
+		if m.state == ObjectsView || (m.state == BucketsView && len(m.history.back) > 0) {
+			return m.goBack()
+		}
+
+	case key.Matches(msg, km.Forward):
+		if m.state.browsing() {
+			return m.goForward()
+		}
+
+	case key.Matches(msg, km.Select):
 		if m.state == BucketsView && len(m.bucketModel.FilteredBuckets) > 0 {
 			selectedBucket := m.bucketModel.FilteredBuckets[m.bucketModel.Cursor]
-			m.state = ObjectsView
-			m.objectModel.BucketName = selectedBucket
-			m.filterInput.Reset()
-			m.filterInput.Placeholder = "Filter objects..."
-			return m, m.fetchObjects(selectedBucket)
+			return m.navigateTo(state.Location{Bucket: selectedBucket})
 		}
 		if m.state == ObjectsView && len(m.objectModel.FilteredObjects) > 0 {
 			selectedObject := m.objectModel.FilteredObjects[m.objectModel.Cursor]
-			bucket := m.objectModel.BucketName
-			outputDir := m.outputDir
-			return m, m.downloadObject(bucket, selectedObject, outputDir)
+			return m.startDownload(m.objectModel.BucketName, selectedObject)
 		}
 
-	case tea.KeyUp:
-		if m.state == BucketsView {
-			m.bucketModel.Cursor--
-			if m.bucketModel.Cursor < 0 {
-				m.bucketModel.Cursor = 0
-			}
-		} else {
-			m.objectModel.Cursor--
-			if m.objectModel.Cursor < 0 {
-				m.objectModel.Cursor = 0
-			}
-		}
+	case key.Matches(msg, km.Up):
+		m.moveCursor(-1)
 		return m, nil
 
-	case tea.KeyDown:
-		if m.state == BucketsView {
-			m.bucketModel.Cursor++
-			if m.bucketModel.Cursor >= len(m.bucketModel.FilteredBuckets) {
-				m.bucketModel.Cursor = len(m.bucketModel.FilteredBuckets) - 1
-			}
-		} else {
-			m.objectModel.Cursor++
-			if m.objectModel.Cursor >= len(m.objectModel.FilteredObjects) {
-				m.objectModel.Cursor = len(m.objectModel.FilteredObjects) - 1
-			}
-		}
+	case key.Matches(msg, km.Down):
+		m.moveCursor(1)
+		return m, nil
+
+	case key.Matches(msg, km.PageUp):
+		m.moveCursor(-m.listHeight())
+		return m, nil
+
+	case key.Matches(msg, km.PageDown):
+		m.moveCursor(m.listHeight())
+		return m, nil
+
+	case key.Matches(msg, km.Top):
+		m.setCursor(0)
+		return m, nil
+
+	case key.Matches(msg, km.Bottom):
+		m.setCursor(m.itemCount() - 1)
 		return m, nil
 	}
-	// ここでnil,nilを返すことで、通常の文字入力はfilterInputに渡される
+	// ここでnil,nilを返すことで、フィルター入力中の編集キーはfilterInputに渡される
 	return nil, nil
 }
 
-// applyFilter はフィルターを適用します
+// itemCount は現在の一覧に表示されている項目数を返します
+func (m UIModel) itemCount() int {
+	switch m.state {
+	case BucketsView:
+		return len(m.bucketModel.FilteredBuckets)
+	case UploadsView:
+		return len(m.uploadModel.Uploads)
+	case UsageView:
+		return len(m.usageRows())
+	}
+	return len(m.objectModel.FilteredObjects)
+}
+
+// moveCursor はカーソルを相対的に移動します
+func (m *UIModel) moveCursor(delta int) {
+	switch m.state {
+	case BucketsView:
+		m.setCursor(m.bucketModel.Cursor + delta)
+	case UploadsView:
+		m.setCursor(m.uploadModel.Cursor + delta)
+	case UsageView:
+		m.setCursor(m.usageModel.Cursor + delta)
+	default:
+		m.setCursor(m.objectModel.Cursor + delta)
+	}
+}
+
+// setCursor はカーソルを一覧の範囲内の指定位置に移動します
+func (m *UIModel) setCursor(idx int) {
+	if idx >= m.itemCount() {
+		idx = m.itemCount() - 1
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	switch m.state {
+	case BucketsView:
+		m.bucketModel.Cursor = idx
+	case UploadsView:
+		m.uploadModel.Cursor = idx
+	case UsageView:
+		m.usageModel.Cursor = idx
+	default:
+		m.objectModel.Cursor = idx
+	}
+}
+
+// applyFilter はフィルターを適用します。
+// オブジェクト一覧では size>100MB のような属性条件も解釈します。
+// 正規表現とglobでは、既知の属性条件以外の部分をパターンとしてそのまま使います。
+// パターンが不正な場合はエラーを保持し、直前の絞り込み結果をそのまま表示します。
 func (m *UIModel) applyFilter() {
+	if !m.state.browsing() {
+		// アップロード一覧と使用量の画面はフィルターの対象外
+		return
+	}
+	var items []string
+	pattern := m.filterInput.Value()
+	if m.state == BucketsView {
+		items = m.bucketModel.Buckets
+	} else {
+		parse := query.Parse
+		if m.matchMode == MatchRegex || m.matchMode == MatchGlob {
+			parse = query.Extract
+		}
+		expr, err := parse(pattern)
+		if err != nil {
+			m.filterErr = err
+			return
+		}
+		pattern = expr.Text
+		items = m.objectModel.Objects
+		if expr.HasConditions() {
+			now := time.Now()
+			items = make([]string, 0, len(m.objectModel.Objects))
+			for _, key := range m.objectModel.Objects {
+				if expr.Match(m.objectModel.Details[key], now) {
+					items = append(items, key)
+				}
+			}
+		}
+	}
+
+	results, err := matchItems(items, pattern, m.matchMode)
+	if err != nil {
+		m.filterErr = err
+		return
+	}
+	m.filterErr = nil
+
+	filtered := make([]string, len(results))
+	positions := make([][]int, len(results))
+	for i, r := range results {
+		filtered[i] = r.item
+		positions[i] = r.positions
+	}
+
 	if m.state == BucketsView {
-		m.bucketModel.FilteredBuckets = filterItems(m.bucketModel.Buckets, m.filterInput.Value())
+		m.bucketModel.FilteredBuckets = filtered
+		m.bucketModel.MatchPositions = positions
 		if len(m.bucketModel.FilteredBuckets) > 0 {
 			m.bucketModel.Cursor = min(m.bucketModel.Cursor, len(m.bucketModel.FilteredBuckets)-1)
 		} else {
 			m.bucketModel.Cursor = 0
 		}
 	} else {
-		m.objectModel.FilteredObjects = filterItems(m.objectModel.Objects, m.filterInput.Value())
+		m.objectModel.FilteredObjects = filtered
+		m.objectModel.MatchPositions = positions
 		if len(m.objectModel.FilteredObjects) > 0 {
 			m.objectModel.Cursor = min(m.objectModel.Cursor, len(m.objectModel.FilteredObjects)-1)
 		} else {
@@ -141,30 +517,5 @@ func (m *UIModel) applyFilter() {
 // fetchBuckets はS3バケット一覧を取得します
 func (m UIModel) fetchBuckets() tea.Msg {
 	buckets, err := m.s3Client.ListBuckets(context.Background())
-	if err != nil {
-		return errorMsg{err}
-	}
-	return bucketsMsg{buckets}
-}
-
-// fetchObjects はバケット内のオブジェクト一覧を取得します
-func (m UIModel) fetchObjects(bucketName string) tea.Cmd {
-	return func() tea.Msg {
-		objects, err := m.s3Client.ListObjects(context.Background(), bucketName)
-		if err != nil {
-			return errorMsg{err}
-		}
-		return objectsMsg{objects}
-	}
-}
-
-// downloadObject はオブジェクトをダウンロードするCmdを返します
-func (m UIModel) downloadObject(bucket, key, outputDir string) tea.Cmd {
-	return func() tea.Msg {
-		err := m.s3Client.DownloadObject(context.Background(), bucket, key, outputDir)
-		if err != nil {
-			return errorMsg{err}
-		}
-		return downloadedMsg{bucket: bucket, key: key, outputDir: outputDir}
-	}
+	return bucketsMsg{buckets: buckets, err: err}
 }
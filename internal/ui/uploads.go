@@ -0,0 +1,282 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/model"
+	"github.com/tsuna-can/s3-cli/internal/query"
+)
+
+// uploadsState は完了していないマルチパートアップロード一覧の表示状態です
+type uploadsState struct {
+	returnTo ViewState // 一覧を閉じたときに戻る表示状態
+	loading  bool
+	status   string // 直前の操作の結果
+	err      error
+}
+
+// openUploads は bucket の完了していないマルチパートアップロードの一覧を開きます
+func (m UIModel) openUploads(bucket string) (UIModel, tea.Cmd) {
+	m.uploads = uploadsState{returnTo: m.state, loading: true}
+	m.uploadModel = model.UploadListModel{BucketName: bucket, Selected: map[string]bool{}}
+	m.state = UploadsView
+	m.filterInput.Blur()
+	return m, m.fetchUploads(bucket)
+}
+
+// closeUploads は一覧を閉じて、開く前の表示状態に戻ります
+func (m UIModel) closeUploads() UIModel {
+	m.state = m.uploads.returnTo
+	m.uploads = uploadsState{}
+	return m
+}
+
+// fetchUploads はアップロードの一覧と、それぞれのパート数・合計サイズを取得します
+func (m UIModel) fetchUploads(bucket string) tea.Cmd {
+	client := m.s3Client
+	return func() tea.Msg {
+		ctx := context.Background()
+		uploads, err := client.ListMultipartUploads(ctx, bucket)
+		if err != nil {
+			return uploadsMsg{bucket: bucket, err: err}
+		}
+		for i := range uploads {
+			if err := client.CountParts(ctx, bucket, &uploads[i]); err != nil {
+				// 一覧の取得後に中止されたアップロードなど。パート数は不明のまま表示する
+				m.logger.Warn("failed to list parts", "bucket", bucket, "key", uploads[i].Key, "upload_id", uploads[i].UploadID, "error", err)
+			}
+		}
+		return uploadsMsg{bucket: bucket, uploads: uploads}
+	}
+}
+
+// handleUploads は取得したアップロードの一覧を反映します
+func (m UIModel) handleUploads(msg uploadsMsg) UIModel {
+	if m.state != UploadsView || msg.bucket != m.uploadModel.BucketName {
+		return m
+	}
+	m.uploads.loading = false
+	m.uploads.err = msg.err
+	m.uploadModel.Uploads = msg.uploads
+
+	// 中止などで無くなったアップロードの選択を外す
+	selected := map[string]bool{}
+	for _, u := range msg.uploads {
+		if m.uploadModel.Selected[u.UploadID] {
+			selected[u.UploadID] = true
+		}
+	}
+	m.uploadModel.Selected = selected
+	m.setCursor(m.uploadModel.Cursor)
+	return m
+}
+
+// handleUploadsKey はアップロード一覧に固有のキー操作を処理します。処理しなかった場合は false を返します
+func (m UIModel) handleUploadsKey(msg tea.KeyMsg) (UIModel, tea.Cmd, bool) {
+	km := m.keys
+	switch {
+	case key.Matches(msg, km.Back):
+		return m.closeUploads(), nil, true
+
+	case key.Matches(msg, km.ToggleSelect):
+		if u, ok := m.currentUpload(); ok {
+			selected := make(map[string]bool, len(m.uploadModel.Selected)+1)
+			for id := range m.uploadModel.Selected {
+				selected[id] = true
+			}
+			if selected[u.UploadID] {
+				delete(selected, u.UploadID)
+			} else {
+				selected[u.UploadID] = true
+			}
+			m.uploadModel.Selected = selected
+			m.moveCursor(1)
+		}
+		return m, nil, true
+
+	case key.Matches(msg, km.Abort):
+		targets := m.abortTargets()
+		if len(targets) > 0 {
+			m.prompt = m.newInputPrompt(i18n.T("uploads.confirm_abort", len(targets)), "y/N", "",
+				func(m UIModel, value string) (UIModel, tea.Cmd) {
+					if !isYes(value) {
+						return m, nil
+					}
+					return m.abortUploads(targets)
+				})
+		}
+		return m, nil, true
+
+	case key.Matches(msg, km.AbortOlder):
+		return m.openAbortOlderPrompt("7d", nil), nil, true
+	}
+	return m, nil, false
+}
+
+// openAbortOlderPrompt は指定した経過時間より古いアップロードをすべて中止するダイアログを開きます
+func (m UIModel) openAbortOlderPrompt(value string, err error) UIModel {
+	m.prompt = m.newInputPrompt(i18n.T("uploads.older_prompt"), "7d", value,
+		func(m UIModel, value string) (UIModel, tea.Cmd) {
+			age, err := parseUploadAge(value)
+			if err != nil {
+				return m.openAbortOlderPrompt(value, err), nil
+			}
+			targets := olderUploads(m.uploadModel.Uploads, time.Now().Add(-age))
+			if len(targets) == 0 {
+				m.uploads.status = i18n.T("uploads.none_older", value)
+				return m, nil
+			}
+			return m.abortUploads(targets)
+		})
+	m.prompt.err = err
+	return m
+}
+
+// parseUploadAge は "7d" のような経過時間を変換します。"0" はすべてのアップロードを表します
+func parseUploadAge(value string) (time.Duration, error) {
+	if strings.TrimSpace(value) == "0" {
+		return 0, nil
+	}
+	return query.ParseAge(strings.TrimSpace(value))
+}
+
+// olderUploads は cutoff 以前に開始されたアップロードを返します
+func olderUploads(uploads []aws.MultipartUpload, cutoff time.Time) []aws.MultipartUpload {
+	var older []aws.MultipartUpload
+	for _, u := range uploads {
+		if !u.Initiated.After(cutoff) {
+			older = append(older, u)
+		}
+	}
+	return older
+}
+
+// isYes は確認ダイアログの入力が肯定かを返します
+func isYes(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// currentUpload はカーソル位置のアップロードを返します
+func (m UIModel) currentUpload() (aws.MultipartUpload, bool) {
+	if m.uploadModel.Cursor >= len(m.uploadModel.Uploads) {
+		return aws.MultipartUpload{}, false
+	}
+	return m.uploadModel.Uploads[m.uploadModel.Cursor], true
+}
+
+// abortTargets は中止するアップロードを返します。選択がなければカーソル位置のアップロードです
+func (m UIModel) abortTargets() []aws.MultipartUpload {
+	var targets []aws.MultipartUpload
+	for _, u := range m.uploadModel.Uploads {
+		if m.uploadModel.Selected[u.UploadID] {
+			targets = append(targets, u)
+		}
+	}
+	if len(targets) == 0 {
+		if u, ok := m.currentUpload(); ok {
+			targets = append(targets, u)
+		}
+	}
+	return targets
+}
+
+// abortUploads はバックグラウンドでアップロードを中止し、終わったら一覧を取り直します
+func (m UIModel) abortUploads(targets []aws.MultipartUpload) (UIModel, tea.Cmd) {
+	m.uploads.loading = true
+	bucket := m.uploadModel.BucketName
+	client := m.s3Client
+	logger := m.logger
+	return m, func() tea.Msg {
+		aborted := 0
+		for _, u := range targets {
+			if err := client.AbortMultipartUpload(context.Background(), bucket, u.Key, u.UploadID); err != nil {
+				return uploadsAbortedMsg{bucket: bucket, aborted: aborted, err: err}
+			}
+			logger.Info("multipart upload aborted", "bucket", bucket, "key", u.Key, "upload_id", u.UploadID)
+			aborted++
+		}
+		return uploadsAbortedMsg{bucket: bucket, aborted: aborted}
+	}
+}
+
+// handleUploadsAborted は中止の結果を表示し、一覧を取り直します
+func (m UIModel) handleUploadsAborted(msg uploadsAbortedMsg) (UIModel, tea.Cmd) {
+	if m.state != UploadsView || msg.bucket != m.uploadModel.BucketName {
+		return m, nil
+	}
+	m.uploads.status = i18n.T("uploads.aborted", msg.aborted)
+	if msg.err != nil {
+		m.uploads.status += "  " + i18n.T("ui.error", msg.err)
+	}
+	return m, m.fetchUploads(msg.bucket)
+}
+
+// uploadItems は一覧に表示する各行の名前（選択状態とキー）を返します
+func (m UIModel) uploadItems() []string {
+	items := make([]string, len(m.uploadModel.Uploads))
+	for i, u := range m.uploadModel.Uploads {
+		mark := "[ ] "
+		if m.uploadModel.Selected[u.UploadID] {
+			mark = "[x] "
+		}
+		items[i] = mark + u.Key
+	}
+	return items
+}
+
+// uploadColumns はアップロードの開始日時・パート数・合計サイズ・アップロードIDの列を返します
+func (m UIModel) uploadColumns(i int) string {
+	u := m.uploadModel.Uploads[i]
+	id := u.UploadID
+	if len(id) > 12 {
+		id = id[:12] + "…"
+	}
+	return fmt.Sprintf("%s  %5d  %10s  %-13s",
+		u.Initiated.Local().Format("2006-01-02 15:04"), u.Parts, formatSize(u.Size), id)
+}
+
+// renderUploadStatus はアップロード一覧の上に表示する状態の行を返します
+func (m UIModel) renderUploadStatus() string {
+	var total int64
+	for _, u := range m.uploadModel.Uploads {
+		total += u.Size
+	}
+	status := i18n.T("uploads.summary", len(m.uploadModel.Uploads), formatSize(total), len(m.uploadModel.Selected))
+	switch {
+	case m.uploads.loading:
+		status += "  " + i18n.T("uploads.loading")
+	case m.uploads.err != nil:
+		return m.theme.Error.Render(i18n.T("ui.error", m.uploads.err))
+	case m.uploads.status != "":
+		status += "  " + m.uploads.status
+	}
+	return m.theme.Status.Render(status)
+}
+
+// renderUploadView はアップロード一覧ビューを描画します
+func (m UIModel) renderUploadView() string {
+	header := m.renderHeader(m.uploadModel.BucketName) + "\n"
+	header += m.renderUploadStatus() + "\n"
+	header += m.theme.Dim.Render(i18n.T("uploads.columns")) + "\n\n"
+
+	listView := m.renderList(
+		m.uploadItems(),
+		nil,
+		m.uploadModel.Cursor,
+		0,
+		i18n.T("uploads.empty"),
+		m.uploadColumns,
+	)
+	return header + listView + m.renderFooter()
+}
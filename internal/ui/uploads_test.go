@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+)
+
+// TestUploadsSelection はアップロード一覧での選択と中止の対象をテストします
+func TestUploadsSelection(t *testing.T) {
+	m := newGoldenModel(t, "none", 80)
+	m.state = UploadsView
+	m.uploads.returnTo = ObjectsView
+	m.uploadModel.Selected = map[string]bool{}
+	m.uploadModel.Cursor = 0
+	ids := []string{m.uploadModel.Uploads[0].UploadID, m.uploadModel.Uploads[1].UploadID}
+
+	// 選択がなければカーソル位置のアップロードが対象になる
+	if targets := m.abortTargets(); len(targets) != 1 || targets[0].UploadID != ids[0] {
+		t.Fatalf("期待結果 %v, 実際の結果 %+v", ids[:1], targets)
+	}
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	model, _ := m.Update(space)
+	m = model.(UIModel)
+	if !m.uploadModel.Selected[ids[0]] || m.uploadModel.Cursor != 1 {
+		t.Fatalf("選択後の状態が正しくありません: 選択 %v, カーソル %d", m.uploadModel.Selected, m.uploadModel.Cursor)
+	}
+	model, _ = m.Update(space)
+	m = model.(UIModel)
+	if targets := m.abortTargets(); len(targets) != 2 {
+		t.Errorf("中止の対象: 期待結果 %d件, 実際の結果 %d件", 2, len(targets))
+	}
+
+	// 選択を外す
+	m.uploadModel.Cursor = 0
+	model, _ = m.Update(space)
+	m = model.(UIModel)
+	if targets := m.abortTargets(); len(targets) != 1 || targets[0].UploadID != ids[1] {
+		t.Errorf("期待結果 %v, 実際の結果 %+v", ids[1:], targets)
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if state := model.(UIModel).state; state != ObjectsView {
+		t.Errorf("期待結果 %v, 実際の結果 %v", ObjectsView, state)
+	}
+}
+
+// TestOlderUploads は経過時間による中止の対象をテストします
+func TestOlderUploads(t *testing.T) {
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	uploads := []aws.MultipartUpload{
+		{Key: "old", Initiated: now.Add(-10 * 24 * time.Hour)},
+		{Key: "week", Initiated: now.Add(-7 * 24 * time.Hour)},
+		{Key: "new", Initiated: now.Add(-time.Hour)},
+	}
+
+	testCases := []struct {
+		name     string
+		age      string
+		expected []string
+	}{
+		{name: "7日より古い", age: "7d", expected: []string{"old", "week"}},
+		{name: "30日より古い", age: "30d", expected: nil},
+		{name: "すべて", age: "0", expected: []string{"old", "week", "new"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			age, err := parseUploadAge(tc.age)
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			var keys []string
+			for _, u := range olderUploads(uploads, now.Add(-age)) {
+				keys = append(keys, u.Key)
+			}
+			if len(keys) != len(tc.expected) {
+				t.Fatalf("期待結果 %v, 実際の結果 %v", tc.expected, keys)
+			}
+			for i := range keys {
+				if keys[i] != tc.expected[i] {
+					t.Fatalf("期待結果 %v, 実際の結果 %v", tc.expected, keys)
+				}
+			}
+		})
+	}
+
+	if _, err := parseUploadAge("soon"); err == nil {
+		t.Error("不正な経過時間でエラーが返されませんでした")
+	}
+}
@@ -0,0 +1,364 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/model"
+	"github.com/tsuna-can/s3-cli/internal/usage"
+)
+
+// usageBarWidth は使用量の棒グラフの幅です
+const usageBarWidth = 16
+
+// usageState は使用量の集計の状態です
+type usageState struct {
+	returnTo ViewState // 画面を閉じたときに戻る表示状態
+	id       int       // 集計ごとに増える番号。古い集計の結果を無視するために使う
+	running  bool
+	err      error
+	status   string // 直前の書き出しの結果
+	cancel   context.CancelFunc
+	progress <-chan aws.SearchProgress
+	done     <-chan error
+}
+
+// openUsage は bucket の prefix 配下の使用量の画面を開き、集計を始めます
+func (m UIModel) openUsage(bucket, prefix string) (UIModel, tea.Cmd) {
+	m.usage.returnTo = m.state
+	m.state = UsageView
+	m.filterInput.Blur()
+	m.usageModel = model.UsageListModel{BucketName: bucket, Path: []string{prefix}}
+	return m.scanUsage()
+}
+
+// scanUsage は usageModel の根のプレフィックス配下をバックグラウンドで走査して集計します。
+// 開いている階層はそのままにするため、集計し直した場合も同じ階層を表示します。
+func (m UIModel) scanUsage() (UIModel, tea.Cmd) {
+	m.cancelUsage()
+	ctx, cancel := context.WithCancel(context.Background())
+	progress := make(chan aws.SearchProgress, 16)
+	done := make(chan error, 1)
+	opts := aws.SearchOptions{
+		Bucket:      m.usageModel.BucketName,
+		Prefix:      m.usageModel.Path[0],
+		Concurrency: m.concurrency,
+	}
+	client := m.s3Client
+	go func() {
+		done <- client.SearchObjects(ctx, opts, progress)
+		close(progress)
+	}()
+
+	m.usage = usageState{
+		returnTo: m.usage.returnTo,
+		id:       m.usage.id + 1,
+		running:  true,
+		cancel:   cancel,
+		progress: progress,
+		done:     done,
+	}
+	m.usageModel.Tree = usage.New(opts.Bucket, opts.Prefix, time.Now())
+	m.logger.Info("usage scan started", "bucket", opts.Bucket, "prefix", opts.Prefix)
+	return m, m.waitForUsage()
+}
+
+// cancelUsage は実行中の集計をキャンセルします
+func (m *UIModel) cancelUsage() {
+	if m.usage.running && m.usage.cancel != nil {
+		m.usage.cancel()
+	}
+}
+
+// closeUsage は集計をキャンセルして、開く前の表示状態に戻ります
+func (m UIModel) closeUsage() UIModel {
+	m.cancelUsage()
+	m.state = m.usage.returnTo
+	// 番号を進めて、キャンセルした集計の残りの結果を無視する
+	m.usage = usageState{id: m.usage.id + 1}
+	m.usageModel = model.UsageListModel{}
+	return m
+}
+
+// waitForUsage は集計の次の途中経過を待つCmdを返します
+func (m UIModel) waitForUsage() tea.Cmd {
+	id, progress, done := m.usage.id, m.usage.progress, m.usage.done
+	return func() tea.Msg {
+		p, ok := <-progress
+		if !ok {
+			return usageDoneMsg{id: id, err: <-done}
+		}
+		return usageProgressMsg{id: id, progress: p}
+	}
+}
+
+// handleUsageProgress は走査したオブジェクトを集計に加えます
+func (m UIModel) handleUsageProgress(msg usageProgressMsg) (UIModel, tea.Cmd) {
+	if msg.id != m.usage.id {
+		return m, nil
+	}
+	for _, obj := range msg.progress.Matched {
+		m.usageModel.Tree.Add(obj)
+	}
+	return m, m.waitForUsage()
+}
+
+// handleUsageDone は集計の終了を反映します
+func (m UIModel) handleUsageDone(msg usageDoneMsg) UIModel {
+	if msg.id != m.usage.id {
+		return m
+	}
+	m.usage.running = false
+	m.usage.err = msg.err
+	m.usage.cancel()
+	tree := m.usageModel.Tree
+	tree.Complete = msg.err == nil
+	if msg.err != nil && !errors.Is(msg.err, context.Canceled) {
+		m.logger.Error("usage scan failed", "bucket", tree.Bucket, "prefix", tree.Root.Prefix, "error", msg.err)
+	} else {
+		m.logger.Info("usage scan finished", "bucket", tree.Bucket, "prefix", tree.Root.Prefix,
+			"objects", tree.Root.Total.Objects, "size", tree.Root.Total.Size, "complete", tree.Complete)
+	}
+	return m
+}
+
+// handleUsageKey は使用量の画面に固有のキー操作を処理します。処理しなかった場合は false を返します
+func (m UIModel) handleUsageKey(msg tea.KeyMsg) (UIModel, tea.Cmd, bool) {
+	km := m.keys
+	u := &m.usageModel
+	switch {
+	case key.Matches(msg, km.Back):
+		if len(u.Path) == 1 {
+			return m.closeUsage(), nil, true
+		}
+		// 親の階層に戻り、開く前のカーソル位置に合わせる
+		last := len(u.Path) - 1
+		u.Cursor, u.Offset = u.Cursors[last-1], 0
+		u.Path, u.Cursors = u.Path[:last:last], u.Cursors[:last-1:last-1]
+		return m, nil, true
+
+	case key.Matches(msg, km.Select):
+		rows := m.usageRows()
+		if u.Cursor < len(rows) && rows[u.Cursor] != nil {
+			u.Path = append(u.Path[:len(u.Path):len(u.Path)], rows[u.Cursor].Prefix)
+			u.Cursors = append(u.Cursors[:len(u.Cursors):len(u.Cursors)], u.Cursor)
+			u.Cursor, u.Offset = 0, 0
+		}
+		return m, nil, true
+
+	case key.Matches(msg, km.CancelSearch):
+		m.cancelUsage()
+		return m, nil, true
+
+	case key.Matches(msg, km.Refresh):
+		if m.usage.running {
+			return m, nil, true
+		}
+		model, cmd := m.scanUsage()
+		return model, cmd, true
+
+	case key.Matches(msg, km.Export):
+		return m.openUsageExport("", nil), nil, true
+	}
+	return m, nil, false
+}
+
+// usageNode は表示している階層の集計を返します。まだ走査していない場合は nil を返します
+func (m UIModel) usageNode() *usage.Node {
+	u := m.usageModel
+	if u.Tree == nil {
+		return nil
+	}
+	return u.Tree.Find(u.Path[len(u.Path)-1])
+}
+
+// usageRows は表示している階層の行を大きい順に返します。
+// サブプレフィックスに含まれない直下のオブジェクトは、まとめて nil の行で表します。
+func (m UIModel) usageRows() []*usage.Node {
+	n := m.usageNode()
+	if n == nil {
+		return nil
+	}
+	rows := n.Sorted()
+	if n.Direct.Objects > 0 {
+		i := 0
+		for i < len(rows) && rows[i].Total.Size >= n.Direct.Size {
+			i++
+		}
+		rows = append(rows[:i], append([]*usage.Node{nil}, rows[i:]...)...)
+	}
+	return rows
+}
+
+// openUsageExport は表示している階層の集計を書き出すファイルを尋ねます
+func (m UIModel) openUsageExport(path string, err error) UIModel {
+	if path == "" {
+		path = filepath.Join(m.outputDir, usageExportName(m.usageModel.BucketName, m.usageModel.Path[len(m.usageModel.Path)-1]))
+	}
+	m.prompt = m.newInputPrompt(i18n.T("usage.export_prompt"), "usage.json", path,
+		func(m UIModel, path string) (UIModel, tea.Cmd) {
+			path = strings.TrimSpace(path)
+			if path == "" {
+				return m, nil
+			}
+			if err := m.exportUsage(path); err != nil {
+				return m.openUsageExport(path, err), nil
+			}
+			m.usage.status = i18n.T("usage.exported", path)
+			return m, nil
+		})
+	m.prompt.err = err
+	return m
+}
+
+// usageExportName は書き出すファイルの既定の名前です（"usage-bucket-logs-2026.json"）
+func usageExportName(bucket, prefix string) string {
+	name := "usage-" + bucket
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		name += "-" + strings.ReplaceAll(prefix, "/", "-")
+	}
+	return name + ".json"
+}
+
+// exportUsage は表示している階層の集計を、拡張子に応じて JSON か CSV で書き出します
+func (m UIModel) exportUsage(path string) error {
+	n := m.usageNode()
+	if n == nil {
+		return i18n.Errorf("usage.nothing_to_export")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := m.usageModel.Tree.Write(f, n, usage.FormatForPath(path)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	m.logger.Info("usage exported", "bucket", m.usageModel.BucketName, "prefix", n.Prefix, "path", path)
+	return nil
+}
+
+// usageItems は一覧に表示する各行の名前を返します
+func (m UIModel) usageItems(rows []*usage.Node) []string {
+	items := make([]string, len(rows))
+	for i, n := range rows {
+		if n == nil {
+			items[i] = i18n.T("usage.direct")
+			continue
+		}
+		items[i] = n.Name
+	}
+	return items
+}
+
+// usageColumns はサイズ・割合・棒グラフ・オブジェクト数の列を返します
+func (m UIModel) usageColumns(rows []*usage.Node) func(i int) string {
+	parent := m.usageNode()
+	return func(i int) string {
+		stat := parent.Direct
+		if rows[i] != nil {
+			stat = rows[i].Total
+		}
+		return fmt.Sprintf("%10s  %5.1f%%  %s  %11s",
+			formatSize(stat.Size), usage.Percent(stat.Size, parent.Total.Size),
+			usageBar(stat.Size, parent.Total.Size, usageBarWidth), formatCount(int(stat.Objects)))
+	}
+}
+
+// usageBar は total に対する n の割合を width 文字の棒グラフにします
+func usageBar(n, total int64, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = int((n*int64(width) + total/2) / total)
+	}
+	filled = max(min(filled, width), 0)
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// renderUsageStatus は集計の状態と合計を1行で返します
+func (m UIModel) renderUsageStatus() string {
+	var state string
+	switch {
+	case m.usage.running:
+		state = i18n.T("usage.running")
+	case errors.Is(m.usage.err, context.Canceled):
+		state = i18n.T("usage.canceled")
+	case m.usage.err != nil:
+		return m.theme.Error.Render(i18n.T("usage.failed", m.usage.err))
+	default:
+		state = i18n.T("usage.done")
+	}
+
+	var total usage.Stat
+	if m.usageModel.Tree != nil {
+		total = m.usageModel.Tree.Root.Total
+	}
+	status := state + "  " + i18n.T("usage.totals", formatCount(int(total.Objects)), formatSize(total.Size))
+	switch {
+	case m.usage.running:
+		status += "  " + i18n.T("search.cancel_hint", m.keys.CancelSearch.Help().Key)
+	case m.usage.status != "":
+		status += "  " + m.usage.status
+	}
+	return m.theme.Status.Render(status)
+}
+
+// renderUsageBreakdown は表示している階層のストレージクラスごと・経過時間ごとの内訳を2行で返します
+func (m UIModel) renderUsageBreakdown() string {
+	n := m.usageNode()
+	if n == nil || n.Total.Objects == 0 {
+		return "\n"
+	}
+	var classes []string
+	for _, name := range n.ClassNames() {
+		classes = append(classes, breakdownPart(name, n.Classes[name].Size, n.Total.Size))
+	}
+	var ages []string
+	for i, b := range usage.AgeBuckets {
+		if n.Ages[i].Objects > 0 {
+			ages = append(ages, breakdownPart(b.Label, n.Ages[i].Size, n.Total.Size))
+		}
+	}
+	return m.theme.Dim.Render(i18n.T("usage.classes", strings.Join(classes, " · "))) + "\n" +
+		m.theme.Dim.Render(i18n.T("usage.ages", strings.Join(ages, " · ")))
+}
+
+// breakdownPart は内訳の1項目を "GLACIER 1.2 GB (40%)" のように返します
+func breakdownPart(name string, size, total int64) string {
+	return fmt.Sprintf("%s %s (%.0f%%)", name, formatSize(size), usage.Percent(size, total))
+}
+
+// renderUsageView は使用量の画面を描画します
+func (m UIModel) renderUsageView() string {
+	u := m.usageModel
+	location := u.BucketName
+	if prefix := u.Path[len(u.Path)-1]; prefix != "" {
+		location += "/" + prefix
+	}
+	header := m.renderHeader(location) + "\n"
+	header += m.renderUsageStatus() + "\n"
+	header += m.renderUsageBreakdown() + "\n\n"
+
+	rows := m.usageRows()
+	listView := m.renderList(
+		m.usageItems(rows),
+		nil,
+		u.Cursor,
+		u.Offset,
+		i18n.T("usage.empty"),
+		m.usageColumns(rows),
+	)
+	return header + listView + m.renderFooter()
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/model"
+	"github.com/tsuna-can/s3-cli/internal/usage"
+)
+
+// newUsageTestModel は alpha の logs/ 配下を集計している使用量の画面を返します
+func newUsageTestModel(t *testing.T) UIModel {
+	m := newTestModel(t, []string{"alpha", "beta"})
+	m.state = UsageView
+	m.usage = usageState{returnTo: ObjectsView, id: 1, running: true}
+	m.usageModel = model.UsageListModel{
+		BucketName: "alpha",
+		Tree:       usage.New("alpha", "logs/", time.Now()),
+		Path:       []string{"logs/"},
+	}
+	now := time.Now()
+	m, _ = m.handleUsageProgress(usageProgressMsg{id: 1, progress: aws.SearchProgress{Matched: []aws.ObjectInfo{
+		{Key: "logs/app/a.log", Size: 100, LastModified: now},
+		{Key: "logs/app/old/b.log", Size: 300, LastModified: now},
+		{Key: "logs/web/c.log", Size: 50, LastModified: now},
+		{Key: "logs/readme", Size: 200, LastModified: now},
+	}}})
+	return m
+}
+
+// TestUsageNavigation は使用量の画面で階層を開いて戻れることをテストします
+func TestUsageNavigation(t *testing.T) {
+	m := newUsageTestModel(t)
+	esc := tea.KeyMsg{Type: tea.KeyEsc}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	// 大きい順に並び、直下のオブジェクトはまとめて1行になる
+	if got := strings.Join(m.usageItems(m.usageRows()), ","); got != "app/,（このプレフィックス直下のオブジェクト）,web/" {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", "app/,（このプレフィックス直下のオブジェクト）,web/", got)
+	}
+
+	// 直下のオブジェクトの行は開けない
+	m.usageModel.Cursor = 1
+	m, _, _ = m.handleUsageKey(enter)
+	if len(m.usageModel.Path) != 1 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", 1, m.usageModel.Path)
+	}
+
+	m.usageModel.Cursor = 2
+	m, _, _ = m.handleUsageKey(enter)
+	if node := m.usageNode(); node == nil || node.Prefix != "logs/web/" || m.usageModel.Cursor != 0 {
+		t.Fatalf("期待結果 %v, 実際の結果 %v", "logs/web/", m.usageModel.Path)
+	}
+
+	// 戻ると、開く前のカーソル位置に戻る
+	m, _, _ = m.handleUsageKey(esc)
+	if len(m.usageModel.Path) != 1 || m.usageModel.Cursor != 2 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", []any{1, 2}, []any{len(m.usageModel.Path), m.usageModel.Cursor})
+	}
+
+	// 根で戻ると画面を閉じ、残りの途中経過は無視する
+	m, _, _ = m.handleUsageKey(esc)
+	if m.state != ObjectsView {
+		t.Errorf("期待結果 %v, 実際の結果 %v", ObjectsView, m.state)
+	}
+	m, cmd := m.handleUsageProgress(usageProgressMsg{id: 1})
+	if cmd != nil {
+		t.Error("閉じた画面の集計を待ち続けています")
+	}
+}
+
+// TestUsageDone は集計の終了と書き出しをテストします
+func TestUsageDone(t *testing.T) {
+	m := newUsageTestModel(t)
+	m.usage.cancel = func() {}
+	m = m.handleUsageDone(usageDoneMsg{id: 1})
+	if m.usage.running || !m.usageModel.Tree.Complete {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "complete", m.usage)
+	}
+	if got := m.renderUsageStatus(); !strings.Contains(got, "4 個, 650 B") {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "4 個, 650 B", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "usage.csv")
+	if err := m.exportUsage(path); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 5 || !strings.HasPrefix(lines[1], "alpha,logs/,4,650,") {
+		t.Errorf("期待結果 %v, 実際の結果 %v", "alpha,logs/,4,650,...", lines)
+	}
+}
+
+func TestUsageBar(t *testing.T) {
+	testCases := []struct {
+		name     string
+		n, total int64
+		expected string
+	}{
+		{name: "空", n: 0, total: 100, expected: "░░░░"},
+		{name: "半分", n: 50, total: 100, expected: "██░░"},
+		{name: "すべて", n: 100, total: 100, expected: "████"},
+		{name: "合計が0", n: 0, total: 0, expected: "░░░░"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := usageBar(tc.n, tc.total, 4); got != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, got)
+			}
+		})
+	}
+}
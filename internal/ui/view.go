@@ -3,24 +3,90 @@ package ui
 import (
 	"fmt"
 	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
 )
 
-// View はUIの現在の状態を表示します
+// defaultWidth はウィンドウサイズが分かる前に使う画面幅です
+const defaultWidth = 80
+
+// filterPrompt は一致方式を表示するフィルター入力欄のプロンプトを返します
+func filterPrompt(mode MatchMode) string {
+	return fmt.Sprintf("🔍 [%s] ", mode)
+}
+
+// renderFilter はフィルター入力欄と、パターンが不正な場合のエラーを描画します
+func (m UIModel) renderFilter() string {
+	view := m.filterInput.View() + "\n"
+	if m.filterErr != nil {
+		view += m.theme.Error.Render(fmt.Sprintf("⚠ %v", m.filterErr)) + "\n"
+	}
+	return view + "\n"
+}
+
+// View はUIの現在の状態を表示します。タブが2つ以上ある場合は先頭にタブバーを表示します
 func (m UIModel) View() string {
+	if bar := m.renderTabBar(); bar != "" {
+		return bar + "\n" + m.view()
+	}
+	return m.view()
+}
+
+// view は表示中のタブの画面を描画します
+func (m UIModel) view() string {
 	if m.msg != "" {
-		return fmt.Sprintf("%s\n\nCtrl+Cで終了してください。", m.msg)
+		if len(m.tabs) > 1 {
+			return m.msg + "\n\n" + i18n.T("tab.error_hint", m.keys.CloseTab.Help().Key)
+		}
+		return m.msg + "\n\n" + i18n.T("ui.quit_hint")
 	}
 
-	if m.state == BucketsView {
+	if m.showHelp {
+		return m.renderHelp()
+	}
+
+	if m.showLogs {
+		return m.renderLogs()
+	}
+
+	if m.details != nil {
+		return m.renderDetails()
+	}
+
+	if m.settings != nil {
+		return m.renderSettings()
+	}
+
+	if m.bookmarks != nil {
+		return m.renderBookmarks()
+	}
+
+	switch m.state {
+	case BucketsView:
 		return m.renderBucketView()
-	} else {
+	case UploadsView:
+		return m.renderUploadView()
+	case UsageView:
+		return m.renderUsageView()
+	default:
 		return m.renderObjectView()
 	}
 }
 
-// renderBucketView はバケット一覧ビューを描画します
-func (m UIModel) renderBucketView() string {
-	var profile, endpoint string
+// viewWidth は描画に使う画面幅を返します
+func (m UIModel) viewWidth() int {
+	if m.width > 0 {
+		return m.width
+	}
+	return defaultWidth
+}
+
+// renderHeader はプロファイル・エンドポイント・バケットを表示するヘッダーバーを描画します。
+// S3呼び出しの再試行中は、その状態も表示します。
+func (m UIModel) renderHeader(bucket string) string {
+	profile, endpoint := m.profile, m.endpointURL
 	if m.s3Client != nil {
 		profile = m.s3Client.GetProfile()
 		if m.s3Client.GetEndpointURL() != "" {
@@ -28,107 +94,246 @@ func (m UIModel) renderBucketView() string {
 		}
 	}
 
+	fields := []string{i18n.T("ui.header_profile") + ": " + profile, i18n.T("ui.header_endpoint") + ": " + endpoint}
+	if bucket != "" {
+		fields = append(fields, i18n.T("ui.header_bucket")+": "+bucket)
+	}
+
+	title := m.theme.HeaderTitle.Render(" s3-cli ")
+	width := m.viewWidth() - lipgloss.Width(title)
+	text := runewidth.Truncate(" "+strings.Join(fields, " │ "), width, "…")
+	header := title + m.theme.HeaderBar.Render(runewidth.FillRight(text, width))
+
+	// 再試行中は応答が止まって見えないよう、ヘッダーの下に状態を表示する
+	if status := m.renderRetryStatus(); status != "" {
+		header += "\n" + status
+	}
+	return header
+}
+
+// renderFooter はキー操作の説明（または入力ダイアログ）を描画します
+func (m UIModel) renderFooter() string {
+	if m.prompt != nil {
+		return m.renderPrompt()
+	}
+	h := m.help
+	h.Width = m.viewWidth()
+	return "\n" + h.ShortHelpView(m.helpKeys().ShortHelp())
+}
+
+// renderHelp はキー操作の一覧を表示するヘルプ画面を描画します
+func (m UIModel) renderHelp() string {
+	h := m.help
+	h.Width = m.viewWidth()
+	close := m.keys.Help.Help().Key
+	panel := m.theme.Panel.Copy().Padding(0, 1).Render(h.FullHelpView(m.helpKeys().FullHelp()))
+	return fmt.Sprintf("%s\n\n%s\n\n%s",
+		m.theme.HeaderTitle.Render(" "+i18n.T("ui.help_title")+" "), panel, m.theme.Dim.Render(i18n.T("ui.help_close", close)))
+}
+
+// renderBucketView はバケット一覧ビューを描画します
+func (m UIModel) renderBucketView() string {
 	// ヘッダー部分（常に表示）
-	header := fmt.Sprintf("Profile: %s\nEndpoint url: %s\n\n", profile, endpoint)
-	header += m.filterInput.View() + "\n\n"
+	header := m.renderHeader("") + "\n"
+	if status := m.renderBucketStatus(); status != "" {
+		header += status + "\n"
+	}
+	header += "\n" + m.renderFilter()
 
 	// リスト部分（共通関数を使用）
 	listView := m.renderList(
 		m.bucketModel.FilteredBuckets,
+		m.bucketModel.MatchPositions,
 		m.bucketModel.Cursor,
-		"条件に一致するバケットが見つかりません",
+		m.bucketModel.Offset,
+		i18n.T("ui.no_buckets"),
+		nil,
 	)
 
 	// フッター部分（常に表示）
-	footer := "\n(↑/↓: 移動, Enter: 選択, Ctrl+C: 終了)"
-
-	return header + listView + footer
+	return header + listView + m.renderFooter()
 }
 
 // renderObjectView はオブジェクト一覧ビューを描画します
 func (m UIModel) renderObjectView() string {
-	var profile, endpoint string
-	if m.s3Client != nil {
-		profile = m.s3Client.GetProfile()
-		if m.s3Client.GetEndpointURL() != "" {
-			endpoint = m.s3Client.GetEndpointURL()
-		}
-	}
-
 	// ヘッダー部分（常に表示）
-	header := fmt.Sprintf("Profile: %s\nEndpoint url: %s\nBucket: %s\n\n", profile, endpoint, m.objectModel.BucketName)
-	header += m.filterInput.View() + "\n\n"
+	location := m.objectModel.BucketName
+	if m.objectModel.Prefix != "" {
+		location += "/" + m.objectModel.Prefix
+	}
+	header := m.renderHeader(location) + "\n"
+	if status := m.renderObjectStatus(); status != "" {
+		header += status + "\n"
+	}
+	if status := m.renderSearchStatus(); status != "" {
+		header += m.theme.Status.Render(strings.TrimSuffix(status, "\n")) + "\n"
+	}
+	if status := m.renderListingStatus(); status != "" {
+		header += m.theme.Dim.Render(status) + "\n"
+	}
+	header += "\n" + m.renderFilter()
 
 	// リスト部分（共通関数を使用）
 	listView := m.renderList(
 		m.objectModel.FilteredObjects,
+		m.objectModel.MatchPositions,
 		m.objectModel.Cursor,
-		"条件に一致するオブジェクトが見つかりません",
+		m.objectModel.Offset,
+		i18n.T("ui.no_objects"),
+		func(i int) string { return m.objectColumns(m.objectModel.FilteredObjects[i]) },
 	)
 
 	// フッター部分（常に表示）
-	footer := "\n(↑/↓: 移動, Enter: ダウンロード, Esc: バケット一覧に戻る, Ctrl+C: 終了)"
+	return header + listView + m.renderTransfers() + m.renderFooter()
+}
 
-	return header + listView + footer
+// objectColumns はオブジェクトのサイズ・更新日時・ストレージクラスの列を返します
+func (m UIModel) objectColumns(key string) string {
+	info, ok := m.objectModel.Details[key]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%10s  %s  %-12s",
+		formatSize(info.Size),
+		info.LastModified.Local().Format("2006-01-02 15:04"),
+		info.StorageClass)
 }
 
-// renderList はリスト部分を描画する共通関数です
-func (m UIModel) renderList(items []string, cursor int, emptyMessage string) string {
+// renderList はリスト部分を枠付きで描画する共通関数です。
+// positionsはitemsと同じ順序で、ハイライトする文字の位置を持ちます。
+// offsetは表示している先頭の項目の位置で、カーソルが表示範囲から外れる場合は調整されます。
+// columnsがnilでない場合は、各行の右側に薄い色でitemsの位置に対応するメタデータ列を表示します。
+func (m UIModel) renderList(items []string, positions [][]int, cursor, offset int, emptyMessage string, columns func(i int) string) string {
+	// 枠の左右の線の分を除いた幅
+	width := m.viewWidth() - 2
+	if width < 10 {
+		width = 10
+	}
+
 	if len(items) == 0 {
-		return emptyMessage
+		return m.theme.Panel.Copy().Width(width).Render(m.theme.Dim.Render(emptyMessage))
 	}
 
 	// 表示可能な最大行数を計算
-	maxVisibleItems := m.height - 10 // ヘッダーとフッターのスペースを考慮
-	if maxVisibleItems < 1 {
-		maxVisibleItems = 1 // 最低でも1行は表示
-	}
+	maxVisibleItems := m.listHeight()
 
 	// 表示範囲を計算
-	startIdx, endIdx := m.calculateVisibleRange(items, cursor, maxVisibleItems)
+	startIdx, endIdx := calculateVisibleRange(len(items), cursor, offset, maxVisibleItems)
 
 	// 表示する範囲のアイテムを描画
-	resultItems := make([]string, 0, endIdx-startIdx)
+	lines := make([]string, 0, endIdx-startIdx+2)
+	if startIdx > 0 {
+		lines = append(lines, m.theme.Dim.Render(i18n.T("ui.more_above")))
+	}
 	for i := startIdx; i < endIdx; i++ {
-		cursorMark := " "
-		if i == cursor {
-			cursorMark = ">"
+		var itemPositions []int
+		if i < len(positions) {
+			itemPositions = positions[i]
+		}
+		meta := ""
+		if columns != nil {
+			meta = columns(i)
 		}
-		resultItems = append(resultItems, fmt.Sprintf("%s %s", cursorMark, items[i]))
+		lines = append(lines, m.renderRow(items[i], itemPositions, meta, i == cursor, width))
 	}
-
-	// スクロールインジケータを表示
-	var result string
-	if startIdx > 0 {
-		result += "↑ (more)\n"
+	if endIdx < len(items) {
+		lines = append(lines, m.theme.Dim.Render(i18n.T("ui.more_below")))
 	}
 
-	result += strings.Join(resultItems, "\n")
+	return m.theme.Panel.Copy().Width(width).Render(strings.Join(lines, "\n"))
+}
 
-	if endIdx < len(items) {
-		result += "\n↓ (more)"
+// renderRow は一覧の1行を描画します。名前が長い場合は末尾を省略します
+func (m UIModel) renderRow(item string, positions []int, meta string, selected bool, width int) string {
+	base := m.theme.Item
+	cursorMark := "  "
+	if selected {
+		base = m.theme.Selected
+		cursorMark = "▸ "
 	}
+	// 選択行では背景色が途切れないよう、各部分のスタイルに行のスタイルを引き継ぐ
+	match := m.theme.Match.Copy().Inherit(base)
+	dim := m.theme.Dim.Copy().Inherit(base)
 
-	return result
+	metaWidth := 0
+	if meta != "" {
+		metaWidth = runewidth.StringWidth(meta) + 2
+	}
+	nameWidth := width - runewidth.StringWidth(cursorMark) - metaWidth
+	if nameWidth < 10 {
+		// 幅が足りない場合はメタデータ列を省略する
+		meta = ""
+		nameWidth = width - runewidth.StringWidth(cursorMark)
+	}
+	name := runewidth.Truncate(item, nameWidth, "…")
+	padding := nameWidth - runewidth.StringWidth(name)
+
+	row := base.Render(cursorMark) + highlightPositions(name, positions, base, match)
+	if padding > 0 {
+		row += base.Render(strings.Repeat(" ", padding))
+	}
+	if meta != "" {
+		row += dim.Render("  " + meta)
+	}
+	return row
 }
 
-// calculateVisibleRange は表示する項目の範囲を計算します
-func (m UIModel) calculateVisibleRange(items []string, cursor int, maxVisibleItems int) (int, int) {
-	startIdx := 0
-	if len(items) > maxVisibleItems {
-		// カーソルが画面外に出ないように調整
-		if cursor >= maxVisibleItems {
-			startIdx = cursor - maxVisibleItems + 1
-			if startIdx+maxVisibleItems > len(items) {
-				startIdx = len(items) - maxVisibleItems
-			}
-		}
+// listHeight は一覧に表示できる行数を返します
+func (m UIModel) listHeight() int {
+	height := m.height - 12 // ヘッダー・フッター・枠線のスペースを考慮
+	// 再試行や検索の状態を表示している行の分を差し引く
+	if m.retry != nil {
+		height--
+	}
+	if len(m.tabs) > 1 {
+		// タブバーの行
+		height--
+	}
+	if m.state == ObjectsView && m.search.progress != nil {
+		height--
+	}
+	if m.state == BucketsView && (m.bucketOp.status != "" || m.bucketOp.err != nil) {
+		height--
+	}
+	if m.state == ObjectsView && (m.objectOp.status != "" || m.objectOp.err != nil) {
+		height--
+	}
+	if m.state == ObjectsView && m.renderListingStatus() != "" {
+		height--
 	}
+	if m.state == ObjectsView {
+		height -= m.transferPanelHeight()
+	}
+	if m.state == UsageView {
+		// ストレージクラスと経過時間の内訳の行
+		height -= 2
+	}
+	if height < 1 {
+		height = 1 // 最低でも1行は表示
+	}
+	return height
+}
 
+// calculateVisibleRange は表示する項目の範囲を計算します
+func calculateVisibleRange(total, cursor, offset, maxVisibleItems int) (int, int) {
+	startIdx := visibleStart(offset, cursor, maxVisibleItems, total)
 	endIdx := startIdx + maxVisibleItems
-	if endIdx > len(items) {
-		endIdx = len(items)
+	if endIdx > total {
+		endIdx = total
 	}
-
 	return startIdx, endIdx
 }
+
+// visibleStart は、先頭を offset からなるべく動かさずにカーソルが表示範囲に収まる先頭の位置を返します
+func visibleStart(offset, cursor, height, total int) int {
+	// カーソルが画面外に出ないように調整
+	if cursor < offset {
+		offset = cursor
+	}
+	if cursor >= offset+height {
+		offset = cursor - height + 1
+	}
+	// 末尾より後ろに空白ができないようにする
+	offset = min(offset, max(total-height, 0))
+	return max(offset, 0)
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/tsuna-can/s3-cli/internal/aws"
+	"github.com/tsuna-can/s3-cli/internal/i18n"
+	"github.com/tsuna-can/s3-cli/internal/model"
+)
+
+// update を指定すると golden ファイルを現在の出力で更新します（go test ./internal/ui -update）
+var update = flag.Bool("update", false, "golden ファイルを更新します")
+
+// newGoldenModel は描画結果が環境に依存しないよう、色数を固定したテーマでUIModelを作成します
+func newGoldenModel(t *testing.T, themeName string, width int) UIModel {
+	t.Helper()
+
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.TrueColor)
+	theme, err := newTheme(themeName, r)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	km, err := newKeyMap(nil)
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	filterInput := textinput.New()
+	filterInput.Prompt = filterPrompt(MatchSubstring)
+	filterInput.Placeholder = "Filter objects..."
+	theme.applyToInput(&filterInput)
+	helpModel := help.New()
+	theme.applyToHelp(&helpModel)
+
+	modified := time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+	objects := []aws.ObjectInfo{
+		{Key: "logs/2026-10-01/app.log", Size: 1536, LastModified: modified, StorageClass: "STANDARD"},
+		{Key: "data/warehouse/events/part-0000-very-long-file-name.parquet", Size: 250 << 20, LastModified: modified, StorageClass: "GLACIER"},
+		{Key: "README.md", Size: 42, LastModified: modified, StorageClass: "STANDARD_IA"},
+	}
+	objectModel := model.ObjectListModel{BucketName: "example-bucket", Details: map[string]aws.ObjectInfo{}}
+	for _, obj := range objects {
+		objectModel.Objects = append(objectModel.Objects, obj.Key)
+		objectModel.Details[obj.Key] = obj
+	}
+	objectModel.FilteredObjects = objectModel.Objects
+	objectModel.MatchPositions = [][]int{{0, 1, 2, 3}, nil, nil}
+	objectModel.Cursor = 1
+
+	uploads := model.UploadListModel{
+		BucketName: "example-bucket",
+		Uploads: []aws.MultipartUpload{
+			{Key: "backups/db-2026-09-01.tar.gz", UploadID: "2~abcdefghijklmnopqrstuvwxyz", Initiated: modified.AddDate(0, -1, 0), Parts: 12, Size: 96 << 20},
+			{Key: "videos/intro.mp4", UploadID: "2~zyxwvutsrqponmlkjihgfedcba", Initiated: modified, Parts: 0},
+		},
+		Selected: map[string]bool{"2~abcdefghijklmnopqrstuvwxyz": true},
+	}
+
+	buckets := []string{"example-bucket", "logs-archive", "static-assets"}
+	return UIModel{
+		session: session{
+			state:       ObjectsView,
+			filterInput: filterInput,
+			profile:     "default",
+			endpointURL: "http://localhost:4566",
+			bucketModel: model.BucketListModel{Buckets: buckets, FilteredBuckets: buckets, MatchPositions: [][]int{nil, {0, 1, 2, 3}, nil}},
+			objectModel: objectModel,
+			uploadModel: uploads,
+		},
+		theme:  theme,
+		keys:   km,
+		help:   helpModel,
+		width:  width,
+		height: 24,
+	}
+}
+
+// TestViewGolden は固定幅での描画結果を golden ファイルと比較します
+func TestViewGolden(t *testing.T) {
+	// 更新日時の表示をタイムゾーンに依存させない
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	testCases := []struct {
+		name  string
+		theme string
+		width int
+		state ViewState
+		help  bool
+		lang  i18n.Lang // 空の場合は日本語
+	}{
+		{name: "buckets_dark_80", theme: "dark", width: 80, state: BucketsView},
+		{name: "objects_dark_100", theme: "dark", width: 100, state: ObjectsView},
+		{name: "objects_light_60", theme: "light", width: 60, state: ObjectsView},
+		{name: "objects_high-contrast_80", theme: "high-contrast", width: 80, state: ObjectsView},
+		{name: "objects_none_40", theme: "none", width: 40, state: ObjectsView},
+		{name: "help_none_80", theme: "none", width: 80, state: ObjectsView, help: true},
+		{name: "uploads_dark_100", theme: "dark", width: 100, state: UploadsView},
+		{name: "uploads_none_60_en", theme: "none", width: 60, state: UploadsView, lang: i18n.English},
+		{name: "objects_dark_100_en", theme: "dark", width: 100, state: ObjectsView, lang: i18n.English},
+		{name: "help_none_80_en", theme: "none", width: 80, state: ObjectsView, help: true, lang: i18n.English},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.lang != "" {
+				i18n.Set(tc.lang)
+				defer i18n.Set(i18n.Japanese)
+			}
+			m := newGoldenModel(t, tc.theme, tc.width)
+			m.state = tc.state
+			m.showHelp = tc.help
+			actual := m.View()
+
+			path := filepath.Join("testdata", tc.name+".golden")
+			if *update {
+				if err := os.WriteFile(path, []byte(actual), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("golden ファイルを読み込めません（-update で作成してください）: %v", err)
+			}
+			if actual != string(expected) {
+				t.Errorf("描画結果が golden ファイルと異なります: %s\n--- 期待結果\n%s\n--- 実際の結果\n%s", path, expected, actual)
+			}
+		})
+	}
+}
+
+// TestResolveThemeName はテーマ名の優先順位をテストします
+func TestResolveThemeName(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	if got := ResolveThemeName("", ""); got != "dark" {
+		t.Errorf("既定のテーマ: 期待結果 %q, 実際の結果 %q", "dark", got)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if got := ResolveThemeName("", ""); got != "none" {
+		t.Errorf("NO_COLOR設定時: 期待結果 %q, 実際の結果 %q", "none", got)
+	}
+	if got := ResolveThemeName("", "light"); got != "light" {
+		t.Errorf("設定ファイル指定時: 期待結果 %q, 実際の結果 %q", "light", got)
+	}
+	if got := ResolveThemeName("high-contrast", "light"); got != "high-contrast" {
+		t.Errorf("フラグ指定時: 期待結果 %q, 実際の結果 %q", "high-contrast", got)
+	}
+
+	if err := ValidateTheme("solarized"); err == nil {
+		t.Error("不明なテーマでエラーが返されませんでした")
+	}
+}
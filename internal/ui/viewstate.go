@@ -8,6 +8,10 @@ const (
 	BucketsView ViewState = iota
 	// ObjectsView はオブジェクト一覧表示状態
 	ObjectsView
+	// UploadsView は完了していないマルチパートアップロード一覧の表示状態
+	UploadsView
+	// UsageView はバケットやプレフィックスの使用量の表示状態
+	UsageView
 )
 
 // String はViewStateを文字列で返します
@@ -17,7 +21,16 @@ func (v ViewState) String() string {
 		return "buckets"
 	case ObjectsView:
 		return "objects"
+	case UploadsView:
+		return "uploads"
+	case UsageView:
+		return "usage"
 	default:
 		return "unknown"
 	}
 }
+
+// browsing はバケットやオブジェクトの一覧を表示している状態かを返します
+func (v ViewState) browsing() bool {
+	return v == BucketsView || v == ObjectsView
+}
@@ -12,6 +12,14 @@ func TestViewStateValues(t *testing.T) {
 	if ObjectsView != 1 {
 		t.Errorf("ObjectsViewの値が期待と異なります: 期待値=%d, 実際値=%d", 1, ObjectsView)
 	}
+
+	if UploadsView != 2 {
+		t.Errorf("UploadsViewの値が期待と異なります: 期待値=%d, 実際値=%d", 2, UploadsView)
+	}
+
+	if UsageView != 3 {
+		t.Errorf("UsageViewの値が期待と異なります: 期待値=%d, 実際値=%d", 3, UsageView)
+	}
 }
 
 // TestViewStateString はViewStateのString()メソッドをテストします
@@ -31,6 +39,16 @@ func TestViewStateString(t *testing.T) {
 			state:    ObjectsView,
 			expected: "objects",
 		},
+		{
+			name:     "UploadsViewの文字列表現",
+			state:    UploadsView,
+			expected: "uploads",
+		},
+		{
+			name:     "UsageViewの文字列表現",
+			state:    UsageView,
+			expected: "usage",
+		},
 		{
 			name:     "未定義の状態の文字列表現",
 			state:    ViewState(99), // 未定義の値
@@ -0,0 +1,153 @@
+package usage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format は集計の書き出し形式です
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+)
+
+// FormatForPath はファイルの拡張子から書き出し形式を決めます。".csv" 以外は JSON です
+func FormatForPath(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return FormatCSV
+	}
+	return FormatJSON
+}
+
+// report は JSON で書き出す集計です
+type report struct {
+	Bucket    string    `json:"bucket"`
+	ScannedAt time.Time `json:"scanned_at"`
+	Complete  bool      `json:"complete"`
+	Usage     nodeJSON  `json:"usage"`
+}
+
+// nodeJSON は JSON で書き出すプレフィックス1つ分の集計です
+type nodeJSON struct {
+	Prefix         string          `json:"prefix"`
+	Objects        int64           `json:"objects"`
+	Size           int64           `json:"size"`
+	Direct         Stat            `json:"direct"`
+	StorageClasses map[string]Stat `json:"storage_classes"`
+	Ages           []ageJSON       `json:"ages"`
+	Prefixes       []nodeJSON      `json:"prefixes,omitempty"`
+}
+
+type ageJSON struct {
+	Age string `json:"age"`
+	Stat
+}
+
+func newNodeJSON(n *Node) nodeJSON {
+	j := nodeJSON{
+		Prefix:         n.Prefix,
+		Objects:        n.Total.Objects,
+		Size:           n.Total.Size,
+		Direct:         n.Direct,
+		StorageClasses: make(map[string]Stat, len(n.Classes)),
+		Ages:           make([]ageJSON, len(AgeBuckets)),
+	}
+	for name, s := range n.Classes {
+		j.StorageClasses[name] = *s
+	}
+	for i, b := range AgeBuckets {
+		j.Ages[i] = ageJSON{Age: b.Label, Stat: n.Ages[i]}
+	}
+	for _, c := range n.Sorted() {
+		j.Prefixes = append(j.Prefixes, newNodeJSON(c))
+	}
+	return j
+}
+
+// Write は n 配下の集計を format の形式で w に書き出します
+func (t *Tree) Write(w io.Writer, n *Node, format Format) error {
+	if format == FormatCSV {
+		return t.WriteCSV(w, n)
+	}
+	return t.WriteJSON(w, n)
+}
+
+// WriteJSON は n 配下の集計を、サブプレフィックスを入れ子にした JSON で書き出します
+func (t *Tree) WriteJSON(w io.Writer, n *Node) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report{Bucket: t.Bucket, ScannedAt: t.ScannedAt, Complete: t.Complete, Usage: newNodeJSON(n)})
+}
+
+// WriteCSV は n 配下のすべてのプレフィックスを1行ずつ CSV で書き出します。
+// ストレージクラスと経過時間の列には、それぞれの合計サイズ（バイト）が入ります。
+func (t *Tree) WriteCSV(w io.Writer, n *Node) error {
+	classes := map[string]bool{}
+	walk(n, func(n *Node) {
+		for name := range n.Classes {
+			classes[name] = true
+		}
+	})
+	classNames := make([]string, 0, len(classes))
+	for name := range classes {
+		classNames = append(classNames, name)
+	}
+	sort.Strings(classNames)
+
+	cw := csv.NewWriter(w)
+	header := []string{"bucket", "prefix", "objects", "size", "direct_objects", "direct_size"}
+	for _, name := range classNames {
+		header = append(header, "class:"+name)
+	}
+	for _, b := range AgeBuckets {
+		header = append(header, "age:"+b.Label)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	var err error
+	walk(n, func(n *Node) {
+		if err != nil {
+			return
+		}
+		row := []string{t.Bucket, n.Prefix,
+			itoa(n.Total.Objects), itoa(n.Total.Size), itoa(n.Direct.Objects), itoa(n.Direct.Size)}
+		for _, name := range classNames {
+			var size int64
+			if s, ok := n.Classes[name]; ok {
+				size = s.Size
+			}
+			row = append(row, itoa(size))
+		}
+		for _, s := range n.Ages {
+			row = append(row, itoa(s.Size))
+		}
+		err = cw.Write(row)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// walk は n とその配下のプレフィックスを、大きい順に深さ優先でたどります
+func walk(n *Node, fn func(n *Node)) {
+	fn(n)
+	for _, c := range n.Sorted() {
+		walk(c, fn)
+	}
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,185 @@
+// Package usage はバケットやプレフィックス配下のオブジェクトのサイズを、
+// サブプレフィックス・ストレージクラス・経過時間ごとに集計します。
+package usage
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+)
+
+// defaultStorageClass は一覧にストレージクラスが含まれない場合のストレージクラスです
+const defaultStorageClass = "STANDARD"
+
+const day = 24 * time.Hour
+
+// AgeBucket は最終更新日時からの経過時間の区分です
+type AgeBucket struct {
+	Label string        // 表示と書き出しに使う名前
+	Max   time.Duration // この区分に入る経過時間の上限。0 は上限なし
+}
+
+// AgeBuckets は経過時間の区分です。短い順に並びます
+var AgeBuckets = []AgeBucket{
+	{Label: "<1d", Max: day},
+	{Label: "1-7d", Max: 7 * day},
+	{Label: "7-30d", Max: 30 * day},
+	{Label: "30-90d", Max: 90 * day},
+	{Label: "90d-1y", Max: 365 * day},
+	{Label: ">1y"},
+}
+
+// ageIndex は経過時間が入る AgeBuckets の位置を返します
+func ageIndex(age time.Duration) int {
+	for i, b := range AgeBuckets {
+		if b.Max == 0 || age < b.Max {
+			return i
+		}
+	}
+	return len(AgeBuckets) - 1
+}
+
+// Stat はオブジェクト数と合計サイズです
+type Stat struct {
+	Objects int64 `json:"objects"`
+	Size    int64 `json:"size"`
+}
+
+func (s *Stat) add(size int64) {
+	s.Objects++
+	s.Size += size
+}
+
+// Percent は total に対する n の割合（%）を返します
+func Percent(n, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
+
+// Node はプレフィックス1つ分の集計です。サブプレフィックス配下のオブジェクトも含みます
+type Node struct {
+	Name     string           // 親からの相対的な名前（"2026/"）。根は集計したプレフィックスそのもの
+	Prefix   string           // バケット内のプレフィックス
+	Total    Stat             // 配下のすべてのオブジェクト
+	Direct   Stat             // サブプレフィックスに含まれない、直下のオブジェクト
+	Classes  map[string]*Stat // ストレージクラスごとの集計
+	Ages     []Stat           // AgeBuckets と同じ順序の、経過時間ごとの集計
+	Children map[string]*Node // 名前ごとのサブプレフィックス
+}
+
+func newNode(name, prefix string) *Node {
+	return &Node{
+		Name:     name,
+		Prefix:   prefix,
+		Classes:  map[string]*Stat{},
+		Ages:     make([]Stat, len(AgeBuckets)),
+		Children: map[string]*Node{},
+	}
+}
+
+func (n *Node) add(obj aws.ObjectInfo, class string, age int) {
+	n.Total.add(obj.Size)
+	s, ok := n.Classes[class]
+	if !ok {
+		s = &Stat{}
+		n.Classes[class] = s
+	}
+	s.add(obj.Size)
+	n.Ages[age].add(obj.Size)
+}
+
+// Sorted はサブプレフィックスを大きい順に返します。同じ大きさの場合は名前の順です
+func (n *Node) Sorted() []*Node {
+	children := make([]*Node, 0, len(n.Children))
+	for _, c := range n.Children {
+		children = append(children, c)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		if children[i].Total.Size != children[j].Total.Size {
+			return children[i].Total.Size > children[j].Total.Size
+		}
+		return children[i].Name < children[j].Name
+	})
+	return children
+}
+
+// ClassNames はストレージクラスを大きい順に返します
+func (n *Node) ClassNames() []string {
+	names := make([]string, 0, len(n.Classes))
+	for name := range n.Classes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := n.Classes[names[i]], n.Classes[names[j]]
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Tree はバケットまたはプレフィックス配下の使用量の集計です
+type Tree struct {
+	Bucket    string
+	Root      *Node
+	ScannedAt time.Time // 経過時間の基準にする、集計を始めた日時
+	Complete  bool      // 最後まで走査できたか。キャンセルや失敗で途中までの場合は false
+}
+
+// New は bucket の prefix 配下を集計する空の Tree を作成します
+func New(bucket, prefix string, now time.Time) *Tree {
+	return &Tree{Bucket: bucket, Root: newNode(prefix, prefix), ScannedAt: now}
+}
+
+// Add はオブジェクトを、根からオブジェクトのあるプレフィックスまでの各階層に加えます
+func (t *Tree) Add(obj aws.ObjectInfo) {
+	class := obj.StorageClass
+	if class == "" {
+		class = defaultStorageClass
+	}
+	age := ageIndex(t.ScannedAt.Sub(obj.LastModified))
+
+	n := t.Root
+	n.add(obj, class, age)
+	rest := strings.TrimPrefix(obj.Key, t.Root.Prefix)
+	for {
+		i := strings.Index(rest, "/")
+		if i < 0 {
+			break
+		}
+		name := rest[:i+1]
+		child, ok := n.Children[name]
+		if !ok {
+			child = newNode(name, n.Prefix+name)
+			n.Children[name] = child
+		}
+		child.add(obj, class, age)
+		n, rest = child, rest[i+1:]
+	}
+	n.Direct.add(obj.Size)
+}
+
+// Find は prefix の階層を返します。集計していない場合は nil を返します
+func (t *Tree) Find(prefix string) *Node {
+	rest, ok := strings.CutPrefix(prefix, t.Root.Prefix)
+	if !ok {
+		return nil
+	}
+	n := t.Root
+	for rest != "" {
+		i := strings.Index(rest, "/")
+		if i < 0 {
+			return nil
+		}
+		if n = n.Children[rest[:i+1]]; n == nil {
+			return nil
+		}
+		rest = rest[i+1:]
+	}
+	return n
+}
@@ -0,0 +1,119 @@
+package usage
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tsuna-can/s3-cli/internal/aws"
+)
+
+var scannedAt = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestTree は logs/ 配下のオブジェクトを集計した Tree を返します
+func newTestTree() *Tree {
+	t := New("example", "logs/", scannedAt)
+	for _, obj := range []aws.ObjectInfo{
+		{Key: "logs/2026/01/a.log", Size: 100, StorageClass: "STANDARD", LastModified: scannedAt.Add(-2 * time.Hour)},
+		{Key: "logs/2026/02/b.log", Size: 300, StorageClass: "GLACIER", LastModified: scannedAt.Add(-400 * day)},
+		{Key: "logs/2025/c.log", Size: 50, LastModified: scannedAt.Add(-10 * day)},
+		{Key: "logs/index.html", Size: 5, StorageClass: "STANDARD", LastModified: scannedAt.Add(-3 * day)},
+	} {
+		t.Add(obj)
+	}
+	return t
+}
+
+func TestTreeAdd(t *testing.T) {
+	tree := newTestTree()
+	root := tree.Root
+
+	testCases := []struct {
+		name     string
+		prefix   string
+		expected Stat
+	}{
+		{name: "根は配下のすべて", prefix: "logs/", expected: Stat{Objects: 4, Size: 455}},
+		{name: "サブプレフィックス", prefix: "logs/2026/", expected: Stat{Objects: 2, Size: 400}},
+		{name: "2階層下のサブプレフィックス", prefix: "logs/2026/02/", expected: Stat{Objects: 1, Size: 300}},
+		{name: "ストレージクラスのないオブジェクト", prefix: "logs/2025/", expected: Stat{Objects: 1, Size: 50}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := tree.Find(tc.prefix)
+			if n == nil {
+				t.Fatalf("プレフィックスが見つかりません: %s", tc.prefix)
+			}
+			if n.Total != tc.expected {
+				t.Errorf("期待結果 %v, 実際の結果 %v", tc.expected, n.Total)
+			}
+		})
+	}
+
+	if root.Direct != (Stat{Objects: 1, Size: 5}) {
+		t.Errorf("直下のオブジェクト: 期待結果 %v, 実際の結果 %v", Stat{Objects: 1, Size: 5}, root.Direct)
+	}
+	if names := root.Sorted(); len(names) != 2 || names[0].Name != "2026/" || names[1].Name != "2025/" {
+		t.Errorf("大きい順: 期待結果 %v, 実際の結果 %v", []string{"2026/", "2025/"}, names)
+	}
+	if classes := root.ClassNames(); strings.Join(classes, ",") != "GLACIER,STANDARD" {
+		t.Errorf("ストレージクラス: 期待結果 %v, 実際の結果 %v", "GLACIER,STANDARD", classes)
+	}
+	if root.Classes["STANDARD"].Size != 155 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", 155, root.Classes["STANDARD"].Size)
+	}
+	// <1d, 1-7d, 7-30d, ..., >1y
+	expectedAges := []int64{100, 5, 50, 0, 0, 300}
+	for i, s := range root.Ages {
+		if s.Size != expectedAges[i] {
+			t.Errorf("%s: 期待結果 %v, 実際の結果 %v", AgeBuckets[i].Label, expectedAges[i], s.Size)
+		}
+	}
+	if tree.Find("tmp/") != nil || tree.Find("logs/2024/") != nil {
+		t.Error("集計していないプレフィックスが見つかりました")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	tree := newTestTree()
+	tree.Complete = true
+	var buf bytes.Buffer
+	if err := tree.Write(&buf, tree.Find("logs/2026/"), FormatForPath("usage.json")); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	var got report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if got.Bucket != "example" || !got.Complete || got.Usage.Prefix != "logs/2026/" || got.Usage.Size != 400 {
+		t.Errorf("期待結果 %v, 実際の結果 %+v", "example logs/2026/ 400", got)
+	}
+	if len(got.Usage.Prefixes) != 2 || got.Usage.Prefixes[0].Prefix != "logs/2026/02/" {
+		t.Errorf("期待結果 %v, 実際の結果 %+v", "logs/2026/02/", got.Usage.Prefixes)
+	}
+	if got.Usage.StorageClasses["GLACIER"].Size != 300 {
+		t.Errorf("期待結果 %v, 実際の結果 %v", 300, got.Usage.StorageClasses)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	tree := newTestTree()
+	var buf bytes.Buffer
+	if err := tree.Write(&buf, tree.Root, FormatForPath("usage.CSV")); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	expected := `bucket,prefix,objects,size,direct_objects,direct_size,class:GLACIER,class:STANDARD,age:<1d,age:1-7d,age:7-30d,age:30-90d,age:90d-1y,age:>1y
+example,logs/,4,455,1,5,300,155,100,5,50,0,0,300
+example,logs/2026/,2,400,0,0,300,100,100,0,0,0,0,300
+example,logs/2026/02/,1,300,1,300,300,0,0,0,0,0,0,300
+example,logs/2026/01/,1,100,1,100,0,100,100,0,0,0,0,0
+example,logs/2025/,1,50,1,50,0,50,0,0,50,0,0,0
+`
+	if buf.String() != expected {
+		t.Errorf("期待結果 %v, 実際の結果 %v", expected, buf.String())
+	}
+}